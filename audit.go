@@ -0,0 +1,206 @@
+package spelunk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+var ErrFailedToAudit = fmt.Errorf("failed to audit secret access")
+
+// AuditOutcome is the outcome of an audited secret access.
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditOperation is the operation of an audited secret access, named after the Spelunker method.
+type AuditOperation string
+
+const (
+	AuditOperationDigUp  AuditOperation = "dig_up"
+	AuditOperationExists AuditOperation = "exists"
+	AuditOperationList   AuditOperation = "list"
+	AuditOperationPut    AuditOperation = "put"
+	AuditOperationDelete AuditOperation = "delete"
+)
+
+// AuditEvent records a secret access made via Spelunker.DigUp, Exists, List, Put or Delete.
+// It never contains the secret value, nor modifier arguments.
+type AuditEvent struct {
+	// Time is when the access started.
+	Time time.Time `json:"time"`
+	// Operation is the operation of the access (e.g. a dig-up, or a write).
+	Operation AuditOperation `json:"operation"`
+	// Identity is the identity of the caller, as set via WithAuditIdentity (if any).
+	Identity string `json:"identity,omitempty"`
+	// Type is the type of the coordinates (i.e. the source type), or of the prefix listed.
+	Type string `json:"type"`
+	// Location is the location of the coordinates, redacted if it is the secret itself (see types.RedactLocation).
+	Location string `json:"location"`
+	// Modifiers are the types of the modifiers applied, in order.
+	Modifiers []string `json:"modifiers,omitempty"`
+	// Outcome is whether the access succeeded or failed.
+	Outcome AuditOutcome `json:"outcome"`
+	// ErrorClass is the class of the error, in case of failure (see types.ClassifyError).
+	ErrorClass types.ErrorClass `json:"error_class,omitempty"`
+	// Duration is how long the access took.
+	Duration time.Duration `json:"duration"`
+}
+
+// AuditSink receives an AuditEvent for each secret access.
+//
+// Implementations must be safe for concurrent use.
+type AuditSink interface {
+	// Audit records the given AuditEvent.
+	// If it fails, the access it refers to fails too, with ErrFailedToAudit.
+	Audit(ctx context.Context, event AuditEvent) error
+}
+
+// WithAuditSink adds the given AuditSink, receiving an AuditEvent for each call to Spelunker.DigUp
+// (including the ones made by Spelunker.DigUpAll, Spelunker.Resolve and others), Spelunker.Exists,
+// Spelunker.List, Spelunker.Put and Spelunker.Delete.
+//
+// Auditing is fail-closed: if a sink fails to record an event, the access fails with ErrFailedToAudit,
+// and the secret is not returned. Events are recorded once the access is done: a write that
+// fails to be audited has happened regardless.
+func WithAuditSink(sinks ...AuditSink) SpelunkerOption {
+	return func(o *options) {
+		o.auditSinks = append(o.auditSinks, sinks...)
+	}
+}
+
+// auditIdentityKey is the context key of the identity set via WithAuditIdentity.
+type auditIdentityKey struct{}
+
+// WithAuditIdentity returns a copy of ctx carrying the identity of the caller,
+// recorded in every AuditEvent of the accesses made with it.
+func WithAuditIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, auditIdentityKey{}, identity)
+}
+
+// AuditIdentity returns the identity of the caller set via WithAuditIdentity, if any.
+func AuditIdentity(ctx context.Context) string {
+	identity, _ := ctx.Value(auditIdentityKey{}).(string)
+	return identity
+}
+
+// auditMiddleware is the DigUpMiddleware sending an AuditEvent to all sinks, once the access is done.
+func (s *Spelunker) auditMiddleware(next DigUpHandler) DigUpHandler {
+	return func(ctx context.Context, coord *types.SecretCoord) (string, error) {
+		start := time.Now()
+		val, err := next(ctx, coord)
+		if err := s.audited(ctx, AuditOperationDigUp, coord, start, err); err != nil {
+			return "", err
+		}
+		return val, nil
+	}
+}
+
+// audited sends the AuditEvent of the operation on coord, started at start and failed with err (if not nil),
+// to all sinks. It returns err, or the failure of a sink wrapped in ErrFailedToAudit.
+func (s *Spelunker) audited(
+	ctx context.Context,
+	operation AuditOperation,
+	coord *types.SecretCoord,
+	start time.Time,
+	err error,
+) error {
+	if len(s.opts.auditSinks) == 0 || coord == nil {
+		return err
+	}
+
+	event := AuditEvent{
+		Time:      start,
+		Operation: operation,
+		Identity:  AuditIdentity(ctx),
+		Type:      coord.Type,
+		Location:  types.RedactedLocation,
+		Outcome:   AuditOutcomeSuccess,
+		Duration:  time.Since(start),
+	}
+	// Locations of unsupported sources are redacted too, as it's unknown what they contain
+	if source, found := s.opts.sources[coord.Type]; found {
+		event.Location = types.RedactLocation(source, *coord)
+	}
+	for _, mod := range coord.Modifiers {
+		event.Modifiers = append(event.Modifiers, mod[0])
+	}
+	if err != nil {
+		event.Outcome = AuditOutcomeFailure
+		event.ErrorClass = types.ClassifyError(err)
+	}
+
+	for _, sink := range s.opts.auditSinks {
+		if auditErr := sink.Audit(ctx, event); auditErr != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToAudit, auditErr)
+		}
+	}
+	return err
+}
+
+// jsonLinesAuditSink writes each AuditEvent as a JSON object, on its own line.
+type jsonLinesAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONLinesAuditSink creates an AuditSink writing each AuditEvent to w,
+// as a JSON object on its own line (see https://jsonlines.org/).
+func NewJSONLinesAuditSink(w io.Writer) AuditSink {
+	return &jsonLinesAuditSink{
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (j *jsonLinesAuditSink) Audit(_ context.Context, event AuditEvent) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.enc.Encode(event)
+}
+
+// slogAuditSink logs each AuditEvent via a *slog.Logger.
+type slogAuditSink struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// NewSlogAuditSink creates an AuditSink logging each AuditEvent via logger, at the given level.
+func NewSlogAuditSink(logger *slog.Logger, level slog.Level) AuditSink {
+	return &slogAuditSink{
+		logger: logger,
+		level:  level,
+	}
+}
+
+func (l *slogAuditSink) Audit(ctx context.Context, event AuditEvent) error {
+	attrs := []slog.Attr{
+		slog.Time("time", event.Time),
+		slog.String("operation", string(event.Operation)),
+		slog.String("type", event.Type),
+		slog.String("location", event.Location),
+		slog.String("outcome", string(event.Outcome)),
+		slog.Duration("duration", event.Duration),
+	}
+	if event.Identity != "" {
+		attrs = append(attrs, slog.String("identity", event.Identity))
+	}
+	if len(event.Modifiers) > 0 {
+		attrs = append(attrs, slog.Any("modifiers", event.Modifiers))
+	}
+	if event.ErrorClass != "" {
+		attrs = append(attrs, slog.String("error_class", string(event.ErrorClass)))
+	}
+
+	l.logger.LogAttrs(ctx, l.level, "Secret accessed", attrs...)
+	return nil
+}
@@ -0,0 +1,267 @@
+package spelunk_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditSink implements spelunk.AuditSink for testing.
+type recordingAuditSink struct {
+	events []spelunk.AuditEvent
+	err    error
+}
+
+func (r *recordingAuditSink) Audit(_ context.Context, event spelunk.AuditEvent) error {
+	r.events = append(r.events, event)
+	return r.err
+}
+
+func TestWithAuditSink(t *testing.T) {
+	ctx := spelunk.WithAuditIdentity(context.Background(), "alice")
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	failing := util.NewMockSource("fail")
+	failing.Err = types.ErrSecretNotFound
+	sink := &recordingAuditSink{}
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithSource(failing),
+		spelunk.WithModifier(&mockModifier{typ: "mod"}),
+		spelunk.WithAuditSink(sink),
+	)
+
+	for _, coordStr := range []string{
+		"test://path/to/secret?mod=arg",
+		"fail://missing",
+		"plain://inline-secret",
+		"unknown://maybe-secret",
+	} {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		_, _ = spelunker.DigUp(ctx, coord)
+	}
+
+	require.Len(t, sink.events, 4)
+	for _, event := range sink.events {
+		require.Equal(t, "alice", event.Identity)
+		require.False(t, event.Time.IsZero())
+	}
+
+	require.Equal(t, spelunk.AuditOperationDigUp, sink.events[0].Operation)
+	require.Equal(t, "test", sink.events[0].Type)
+	require.Equal(t, "path/to/secret", sink.events[0].Location)
+	require.Equal(t, []string{"mod"}, sink.events[0].Modifiers)
+	require.Equal(t, spelunk.AuditOutcomeSuccess, sink.events[0].Outcome)
+	require.Empty(t, sink.events[0].ErrorClass)
+
+	require.Equal(t, spelunk.AuditOutcomeFailure, sink.events[1].Outcome)
+	require.Equal(t, types.ErrorClassNotFound, sink.events[1].ErrorClass)
+
+	require.Equal(t, types.RedactedLocation, sink.events[2].Location)
+	require.Equal(t, spelunk.AuditOutcomeSuccess, sink.events[2].Outcome)
+
+	require.Equal(t, types.RedactedLocation, sink.events[3].Location)
+	require.Equal(t, types.ErrorClassUnknown, sink.events[3].ErrorClass)
+}
+
+func TestWithAuditSink_Operations(t *testing.T) {
+	ctx := spelunk.WithAuditIdentity(context.Background(), "alice")
+
+	checker := &existenceChecker{MockSource: util.NewMockSource("exists"), existing: map[string]bool{"here": true}}
+	lister := &secretLister{MockSource: util.NewMockSource("list"), err: types.ErrPermissionDenied}
+	writer := &secretWriter{MockSource: util.NewMockSource("write")}
+	digger := util.NewMockSource("dig")
+	digger.Val = "secret-value"
+	sink := &recordingAuditSink{}
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(checker),
+		spelunk.WithSource(lister),
+		spelunk.WithSource(writer),
+		spelunk.WithSource(digger),
+		spelunk.WithAuditSink(sink),
+	)
+	mustCoord := func(coordStr string) *types.SecretCoord {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return coord
+	}
+
+	tests := []struct {
+		name       string
+		access     func() error
+		wantOp     spelunk.AuditOperation
+		wantType   string
+		wantClass  types.ErrorClass
+		wantFailed bool
+	}{
+		{
+			name: "exists",
+			access: func() error {
+				_, err := spelunker.Exists(ctx, mustCoord("exists://missing"))
+				return err
+			},
+			wantOp:   spelunk.AuditOperationExists,
+			wantType: "exists",
+		},
+		{
+			name: "exists, digging up",
+			access: func() error {
+				_, err := spelunker.Exists(ctx, mustCoord("dig://loc"))
+				return err
+			},
+			wantOp:   spelunk.AuditOperationExists,
+			wantType: "dig",
+		},
+		{
+			name: "list",
+			access: func() error {
+				_, err := spelunker.List(ctx, mustCoord("list://prefix/"))
+				return err
+			},
+			wantOp:     spelunk.AuditOperationList,
+			wantType:   "list",
+			wantClass:  types.ErrorClassPermissionDenied,
+			wantFailed: true,
+		},
+		{
+			name: "put",
+			access: func() error {
+				return spelunker.Put(ctx, mustCoord("write://loc"), "secret-value", types.PutOptions{})
+			},
+			wantOp:   spelunk.AuditOperationPut,
+			wantType: "write",
+		},
+		{
+			name: "delete",
+			access: func() error {
+				return spelunker.Delete(ctx, mustCoord("write://loc"))
+			},
+			wantOp:   spelunk.AuditOperationDelete,
+			wantType: "write",
+		},
+		{
+			name: "put rejected",
+			access: func() error {
+				return spelunker.Put(ctx, mustCoord("exists://loc"), "secret-value", types.PutOptions{})
+			},
+			wantOp:     spelunk.AuditOperationPut,
+			wantType:   "exists",
+			wantClass:  types.ErrorClassUnknown,
+			wantFailed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink.events = nil
+			err := tt.access()
+			require.Equal(t, tt.wantFailed, err != nil)
+
+			require.Len(t, sink.events, 1)
+			event := sink.events[0]
+			require.Equal(t, tt.wantOp, event.Operation)
+			require.Equal(t, tt.wantType, event.Type)
+			require.Equal(t, "alice", event.Identity)
+			require.Equal(t, tt.wantClass, event.ErrorClass)
+			if tt.wantFailed {
+				require.Equal(t, spelunk.AuditOutcomeFailure, event.Outcome)
+			} else {
+				require.Equal(t, spelunk.AuditOutcomeSuccess, event.Outcome)
+			}
+		})
+	}
+
+	// Auditing is fail-closed for every operation
+	sink.err = errors.New("disk full")
+	_, err := spelunker.Exists(ctx, mustCoord("exists://here"))
+	require.ErrorIs(t, err, spelunk.ErrFailedToAudit)
+	err = spelunker.Put(ctx, mustCoord("write://loc"), "secret-value", types.PutOptions{})
+	require.ErrorIs(t, err, spelunk.ErrFailedToAudit)
+}
+
+func TestWithAuditSink_FailClosed(t *testing.T) {
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	errSink := errors.New("disk full")
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithAuditSink(&recordingAuditSink{err: errSink}),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	got, err := spelunker.DigUp(context.Background(), coord)
+	require.ErrorIs(t, err, spelunk.ErrFailedToAudit)
+	require.ErrorIs(t, err, errSink)
+	require.Empty(t, got)
+}
+
+func TestNewJSONLinesAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithAuditSink(spelunk.NewJSONLinesAuditSink(&buf)),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	for range 2 {
+		_, err = spelunker.DigUp(context.Background(), coord)
+		require.NoError(t, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		require.NotContains(t, line, "secret-value")
+
+		var event map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		require.Equal(t, "test", event["type"])
+		require.Equal(t, "loc", event["location"])
+		require.Equal(t, "success", event["outcome"])
+		require.Equal(t, "dig_up", event["operation"])
+		require.NotContains(t, event, "identity")
+	}
+}
+
+func TestNewSlogAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	src := util.NewMockSource("test")
+	src.Err = types.ErrSecretNotFound
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithAuditSink(spelunk.NewSlogAuditSink(logger, slog.LevelInfo)),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(spelunk.WithAuditIdentity(context.Background(), "bob"), coord)
+	require.Error(t, err)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "Secret accessed", record["msg"])
+	require.Equal(t, "bob", record["identity"])
+	require.Equal(t, "failure", record["outcome"])
+	require.Equal(t, "not_found", record["error_class"])
+}
@@ -0,0 +1,83 @@
+package spelunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// defaultConcurrency is the maximum amount of secrets dug-up concurrently
+// by Spelunker.DigUpAll, unless configured otherwise.
+const defaultConcurrency = 8
+
+var ErrNilSecretCoord = fmt.Errorf("nil secret coordinates")
+
+// DigUpAll digs up concurrently all the secrets at the given *SecretCoord, indexed by key.
+//
+// Concurrency is bounded (see WithConcurrency), and identical coordinates are dug-up only once,
+// even if they appear under multiple keys.
+//
+// It returns a map of the secrets dug-up successfully, indexed by the same keys as the input.
+// If any secret fails to be dug-up, the returned error joins the errors of every failing key,
+// each naming the key it refers to: the returned map still contains all the successful results.
+func (s *Spelunker) DigUpAll(
+	ctx context.Context,
+	coords map[string]*types.SecretCoord,
+) (map[string]string, error) {
+	// Group keys by identical coordinates, so each is dug-up only once
+	keysByCoord := make(map[string][]string, len(coords))
+	uniqueCoords := make(map[string]*types.SecretCoord, len(coords))
+	var errs []error
+	for key, coord := range coords {
+		if coord == nil {
+			errs = append(errs, fmt.Errorf("%q: %w", key, ErrNilSecretCoord))
+			continue
+		}
+		id := coord.URI()
+		keysByCoord[id] = append(keysByCoord[id], key)
+		uniqueCoords[id] = coord
+	}
+
+	jobs := make(chan string)
+	go func() {
+		defer close(jobs)
+		for id := range uniqueCoords {
+			jobs <- id
+		}
+	}()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string, len(coords))
+	)
+	for range min(s.opts.concurrency, len(uniqueCoords)) {
+		wg.Go(func() {
+			for id := range jobs {
+				val, err := s.DigUp(ctx, uniqueCoords[id])
+
+				mu.Lock()
+				for _, key := range keysByCoord[id] {
+					if err != nil {
+						errs = append(errs, fmt.Errorf("%q: %w", key, err))
+					} else {
+						results[key] = val
+					}
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	// Sort errors, so that the joined error is deterministic
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return results, errors.Join(errs...)
+}
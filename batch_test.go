@@ -0,0 +1,118 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// slowSource implements types.SecretSource for testing,
+// tracking the maximum amount of concurrent calls to DigUp.
+type slowSource struct {
+	current atomic.Int64
+	peak    atomic.Int64
+}
+
+func (s *slowSource) Type() string {
+	return "slow"
+}
+
+func (s *slowSource) DigUp(_ context.Context, coord types.SecretCoord) (string, error) {
+	cur := s.current.Add(1)
+	defer s.current.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if cur <= peak || s.peak.CompareAndSwap(peak, cur) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return coord.Location, nil
+}
+
+func mustCoords(t *testing.T, coordStrs map[string]string) map[string]*types.SecretCoord {
+	t.Helper()
+	coords := make(map[string]*types.SecretCoord, len(coordStrs))
+	for key, coordStr := range coordStrs {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		coords[key] = coord
+	}
+	return coords
+}
+
+func TestSpelunker_DigUpAll(t *testing.T) {
+	ctx := context.Background()
+
+	okSrc := util.NewMockSource("ok")
+	okSrc.Val = "ok-value"
+	failSrc := util.NewMockSource("fail")
+	failSrc.Err = errors.New("boom")
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(okSrc),
+		spelunk.WithSource(failSrc),
+		spelunk.WithModifier(&mockModifier{typ: "mod"}),
+	)
+
+	coords := mustCoords(t, map[string]string{
+		"first":    "ok://loc",
+		"second":   "ok://loc",
+		"modified": "ok://loc?mod=a",
+		"failing":  "fail://loc",
+		"missing":  "unknown://loc",
+	})
+	coords["nil"] = nil
+
+	got, err := spelunker.DigUpAll(ctx, coords)
+	require.Equal(t, map[string]string{
+		"first":    "ok-value",
+		"second":   "ok-value",
+		"modified": "ok-value_a",
+	}, got)
+
+	// Every failing key is named in the error
+	require.ErrorIs(t, err, spelunk.ErrFailedToDigUpSecret)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	require.ErrorIs(t, err, spelunk.ErrNilSecretCoord)
+	require.ErrorContains(t, err, `"failing"`)
+	require.ErrorContains(t, err, `"missing"`)
+	require.ErrorContains(t, err, `"nil"`)
+
+	// Identical coordinates are dug-up only once
+	require.Equal(t, 2, okSrc.Calls())
+}
+
+func TestSpelunker_DigUpAll_Empty(t *testing.T) {
+	got, err := spelunk.NewSpelunker().DigUpAll(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+func TestSpelunker_DigUpAll_Concurrency(t *testing.T) {
+	src := &slowSource{}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithConcurrency(3),
+	)
+
+	coords := mustCoords(t, map[string]string{
+		"a": "slow://a", "b": "slow://b", "c": "slow://c", "d": "slow://d",
+		"e": "slow://e", "f": "slow://f", "g": "slow://g", "h": "slow://h",
+	})
+
+	got, err := spelunker.DigUpAll(context.Background(), coords)
+	require.NoError(t, err)
+	require.Len(t, got, len(coords))
+	for key, val := range got {
+		require.Equal(t, key, val)
+	}
+	require.LessOrEqual(t, src.peak.Load(), int64(3))
+	require.Greater(t, src.peak.Load(), int64(1))
+}
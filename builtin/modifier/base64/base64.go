@@ -16,7 +16,7 @@ import (
 // NOTE: This is "aliased" as `?b64e` by base64_encoder.SecretModifierBase64Encoder.
 type SecretModifierBase64 struct{}
 
-var _ types.SecretModifier = (*SecretModifierBase64)(nil)
+var _ types.BytesModifier = (*SecretModifierBase64)(nil)
 
 func (s *SecretModifierBase64) Type() string {
 	return "b64"
@@ -29,3 +29,11 @@ func (s *SecretModifierBase64) Modify(
 ) (string, error) {
 	return b64.StdEncoding.EncodeToString([]byte(secretValue)), nil
 }
+
+func (s *SecretModifierBase64) ModifyBytes(
+	_ context.Context,
+	secretValue []byte,
+	_ string,
+) ([]byte, error) {
+	return b64.StdEncoding.AppendEncode(nil, secretValue), nil
+}
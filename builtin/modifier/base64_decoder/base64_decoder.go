@@ -17,7 +17,7 @@ var ErrSecretModifierBase64DecoderFailedDecoding = fmt.Errorf("failed to decode
 //	plain://bXktc2VjcmV0?b64d
 type SecretModifierBase64Decoder struct{}
 
-var _ types.SecretModifier = (*SecretModifierBase64Decoder)(nil)
+var _ types.BytesModifier = (*SecretModifierBase64Decoder)(nil)
 
 func (s *SecretModifierBase64Decoder) Type() string {
 	return "b64d"
@@ -34,3 +34,15 @@ func (s *SecretModifierBase64Decoder) Modify(
 	}
 	return string(decoded), nil
 }
+
+func (s *SecretModifierBase64Decoder) ModifyBytes(
+	_ context.Context,
+	secretValue []byte,
+	_ string,
+) ([]byte, error) {
+	decoded, err := b64.StdEncoding.AppendDecode(nil, secretValue)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSecretModifierBase64DecoderFailedDecoding, err)
+	}
+	return decoded, nil
+}
@@ -86,3 +86,16 @@ func TestSecretModifierBase64Decoder_Modify(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretModifierBase64Decoder_ModifyBytes(t *testing.T) {
+	ctx := context.Background()
+	s := &base64_decoder.SecretModifierBase64Decoder{}
+
+	binary := []byte{0xff, 0x00, 0xfe}
+	got, err := s.ModifyBytes(ctx, []byte(b64.StdEncoding.EncodeToString(binary)), "")
+	require.NoError(t, err)
+	require.Equal(t, binary, got)
+
+	_, err = s.ModifyBytes(ctx, []byte("invalid base64 string"), "")
+	require.ErrorIs(t, err, base64_decoder.ErrSecretModifierBase64DecoderFailedDecoding)
+}
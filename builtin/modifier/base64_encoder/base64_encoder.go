@@ -17,7 +17,7 @@ type SecretModifierBase64Encoder struct {
 	base64.SecretModifierBase64
 }
 
-var _ types.SecretModifier = (*SecretModifierBase64Encoder)(nil)
+var _ types.BytesModifier = (*SecretModifierBase64Encoder)(nil)
 
 func (s *SecretModifierBase64Encoder) Type() string {
 	return "b64e"
@@ -18,21 +18,42 @@ var ErrSecretSourceBase64FailedDecoding = fmt.Errorf("failed to decode base64 se
 // This types.SecretSource is built-in to spelunker.Spelunker.
 type SecretSourceBase64 struct{}
 
-var _ types.SecretSource = (*SecretSourceBase64)(nil)
+var (
+	_ types.InlineSecretSource = (*SecretSourceBase64)(nil)
+	_ types.LocationValidator  = (*SecretSourceBase64)(nil)
+)
 
 func (s *SecretSourceBase64) Type() string {
 	return "base64"
 }
 
+func (s *SecretSourceBase64) Inline() {}
+
 func (s *SecretSourceBase64) DigUp(_ context.Context, coord types.SecretCoord) (string, error) {
+	decoded, err := s.decode(coord)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// ValidateLocation checks that the location is valid base64.
+func (s *SecretSourceBase64) ValidateLocation(coord types.SecretCoord) error {
+	if _, err := s.decode(coord); err != nil {
+		return fmt.Errorf("%w: %w", types.ErrInvalidLocation, err)
+	}
+	return nil
+}
+
+func (s *SecretSourceBase64) decode(coord types.SecretCoord) ([]byte, error) {
 	decoded, err := base64.StdEncoding.DecodeString(coord.Location)
 	if err != nil {
-		return "", fmt.Errorf(
+		return nil, fmt.Errorf(
 			"%w (%q): %w",
 			ErrSecretSourceBase64FailedDecoding,
-			coord.Location,
+			types.RedactLocation(s, coord),
 			err,
 		)
 	}
-	return string(decoded), nil
+	return decoded, nil
 }
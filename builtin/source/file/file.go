@@ -2,9 +2,11 @@ package file
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/detro/spelunk/v2/types"
 )
@@ -14,6 +16,10 @@ var (
 	ErrSecretSourceFileFailedRead = fmt.Errorf("failed to read secret file")
 )
 
+// filePerm are the permissions of the files written by SecretSourceFile.Put:
+// readable and writable by the owner only.
+const filePerm = 0o600
+
 // SecretSourceFile digs up secrets from local files.
 // The URI scheme for this source is "file". Examples:
 //
@@ -24,7 +30,7 @@ var (
 // This types.SecretSource is built-in to spelunker.Spelunker.
 type SecretSourceFile struct{}
 
-var _ types.SecretSource = (*SecretSourceFile)(nil)
+var _ types.SecretWriter = (*SecretSourceFile)(nil)
 
 func (s *SecretSourceFile) Type() string {
 	return "file"
@@ -36,6 +42,9 @@ func (s *SecretSourceFile) DigUp(_ context.Context, coord types.SecretCoord) (st
 	}
 
 	f, err := os.Open(coord.Location)
+	if errors.Is(err, os.ErrPermission) {
+		return "", fmt.Errorf("%w (%q): %w: %w", ErrSecretSourceFileFailedOpen, coord.Location, types.ErrPermissionDenied, err)
+	}
 	if err != nil {
 		return "", fmt.Errorf("%w (%q): %w", ErrSecretSourceFileFailedOpen, coord.Location, err)
 	}
@@ -48,3 +57,57 @@ func (s *SecretSourceFile) DigUp(_ context.Context, coord types.SecretCoord) (st
 
 	return string(content), nil
 }
+
+// Put writes the secret to the file, creating it (and its parent directories) if it doesn't exist.
+// The file is replaced atomically, and is readable and writable by the owner only.
+// Files have no version, so compare-and-set is not supported.
+func (s *SecretSourceFile) Put(_ context.Context, coord types.SecretCoord, value string, opts types.PutOptions) error {
+	if len(opts.IfVersion) > 0 {
+		return fmt.Errorf("%w: files have no version", types.ErrCompareAndSetNotSupported)
+	}
+
+	dir := filepath.Dir(coord.Location)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return wrapWriteError(coord, err)
+	}
+
+	// Write to a temporary file first, so that the secret file is never left half-written
+	f, err := os.CreateTemp(dir, "."+filepath.Base(coord.Location)+".*")
+	if err != nil {
+		return wrapWriteError(coord, err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err := f.WriteString(value); err != nil {
+		_ = f.Close()
+		return wrapWriteError(coord, err)
+	}
+	if err := f.Chmod(filePerm); err != nil {
+		_ = f.Close()
+		return wrapWriteError(coord, err)
+	}
+	if err := f.Close(); err != nil {
+		return wrapWriteError(coord, err)
+	}
+	if err := os.Rename(f.Name(), coord.Location); err != nil {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// Delete removes the file.
+func (s *SecretSourceFile) Delete(_ context.Context, coord types.SecretCoord) error {
+	if err := os.Remove(coord.Location); err != nil && !os.IsNotExist(err) {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// wrapWriteError wraps a failure to write or remove the file of coord,
+// classifying it as permission denied if it is.
+func wrapWriteError(coord types.SecretCoord, err error) error {
+	if errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("%w (%q): %w: %w", types.ErrCouldNotWriteSecret, coord.Location, types.ErrPermissionDenied, err)
+	}
+	return fmt.Errorf("%w (%q): %w", types.ErrCouldNotWriteSecret, coord.Location, err)
+}
@@ -114,3 +114,35 @@ func TestSecretSourceFile_DigUp(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretSourceFile_PutDelete(t *testing.T) {
+	ctx := context.Background()
+	spelunker := spelunk.NewSpelunker()
+
+	path := filepath.Join(t.TempDir(), "nested", "secret.txt")
+	coord, err := types.NewSecretCoord("file://" + filepath.ToSlash(path))
+	require.NoError(t, err)
+
+	// Created, with its parent directories, then replaced
+	require.NoError(t, spelunker.Put(ctx, coord, "first", types.PutOptions{}))
+	require.NoError(t, spelunker.Put(ctx, coord, "second", types.PutOptions{}))
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "second", got)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary file left behind")
+
+	err = spelunker.Put(ctx, coord, "third", types.PutOptions{IfVersion: "1"})
+	require.ErrorIs(t, err, types.ErrCompareAndSetNotSupported)
+
+	// Deleted, also when it doesn't exist anymore
+	require.NoError(t, spelunker.Delete(ctx, coord))
+	require.NoError(t, spelunker.Delete(ctx, coord))
+	_, err = spelunker.DigUp(ctx, coord)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+}
@@ -16,12 +16,14 @@ import (
 // the whole combination of URI _authority_ and _path_ is returned.
 type SecretSourcePlain struct{}
 
-var _ types.SecretSource = (*SecretSourcePlain)(nil)
+var _ types.InlineSecretSource = (*SecretSourcePlain)(nil)
 
 func (s *SecretSourcePlain) Type() string {
 	return "plain"
 }
 
+func (s *SecretSourcePlain) Inline() {}
+
 func (s *SecretSourcePlain) DigUp(_ context.Context, coord types.SecretCoord) (string, error) {
 	return coord.Location, nil
 }
@@ -0,0 +1,65 @@
+package spelunk
+
+import (
+	"context"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// bytesModeKey is the context key marking a dig-up made via Spelunker.DigUpBytes.
+type bytesModeKey struct{}
+
+func withBytesMode(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bytesModeKey{}, true)
+}
+
+// IsBytesMode returns true if ctx belongs to a dig-up made via Spelunker.DigUpBytes:
+// the values handled by middlewares are then raw bytes (held in a string), rather than text.
+func IsBytesMode(ctx context.Context) bool {
+	bytesMode, _ := ctx.Value(bytesModeKey{}).(bool)
+	return bytesMode
+}
+
+// DigUpBytes digs up a secret using the given *SecretCoord, like DigUp, but binary-safe:
+// it returns the raw bytes of the secret, without encoding tricks.
+//
+// Sources implementing types.BytesSource are asked for raw bytes (e.g. GCP Secret Manager payloads
+// are returned as they are, rather than base64-encoded), and modifiers implementing
+// types.BytesModifier are applied to raw bytes. Others are used via their string API.
+//
+// The value is never trimmed, regardless of WithTrimValue.
+func (s *Spelunker) DigUpBytes(ctx context.Context, coord *types.SecretCoord) ([]byte, error) {
+	val, err := s.digUp(withBytesMode(ctx), coord)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(val), nil
+}
+
+// digUpFromSource digs up the secret at coord from source, as raw bytes or with metadata,
+// if requested and supported.
+func digUpFromSource(ctx context.Context, source types.SecretSource, coord types.SecretCoord) (string, error) {
+	if metadataSource, ok := source.(types.MetadataSource); ok {
+		if metadata := requestedMetadata(ctx); metadata != nil {
+			val, sourceMetadata, err := metadataSource.DigUpWithMetadata(ctx, coord)
+			if err == nil && sourceMetadata != nil {
+				*metadata = *sourceMetadata
+			}
+			return val, err
+		}
+	}
+	if bytesSource, ok := source.(types.BytesSource); ok && IsBytesMode(ctx) {
+		val, err := bytesSource.DigUpBytes(ctx, coord)
+		return string(val), err
+	}
+	return source.DigUp(ctx, coord)
+}
+
+// modifyBytes applies modifier to value, as raw bytes if supported.
+func modifyBytes(ctx context.Context, modifier types.SecretModifier, value string, arg string) (string, error) {
+	if bytesModifier, ok := modifier.(types.BytesModifier); ok && IsBytesMode(ctx) {
+		val, err := bytesModifier.ModifyBytes(ctx, []byte(value), arg)
+		return string(val), err
+	}
+	return modifier.Modify(ctx, value, arg)
+}
@@ -0,0 +1,145 @@
+package spelunk_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+// binarySource implements types.BytesSource for testing.
+// Like sources of binary secrets, its string API returns the value base64-encoded.
+type binarySource struct {
+	val []byte
+}
+
+func (b *binarySource) Type() string {
+	return "bin"
+}
+
+func (b *binarySource) DigUp(_ context.Context, _ types.SecretCoord) (string, error) {
+	return base64.StdEncoding.EncodeToString(b.val), nil
+}
+
+func (b *binarySource) DigUpBytes(_ context.Context, _ types.SecretCoord) ([]byte, error) {
+	return b.val, nil
+}
+
+// xorModifier implements types.BytesModifier for testing.
+// Its string API fails, to prove it's never used in bytes mode.
+type xorModifier struct{}
+
+func (x *xorModifier) Type() string {
+	return "xor"
+}
+
+func (x *xorModifier) Modify(_ context.Context, _ string, _ string) (string, error) {
+	panic("string API must not be used")
+}
+
+func (x *xorModifier) ModifyBytes(_ context.Context, value []byte, _ string) ([]byte, error) {
+	res := make([]byte, len(value))
+	for i, b := range value {
+		res[i] = b ^ 0xff
+	}
+	return res, nil
+}
+
+func TestSpelunker_DigUpBytes(t *testing.T) {
+	ctx := context.Background()
+
+	// Not valid UTF-8, with leading and trailing white spaces
+	binary := []byte{' ', 0xff, 0x00, 0xfe, '\n'}
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     []byte
+		errMatch error
+	}{
+		{
+			name:     "bytes source",
+			coordStr: "bin://loc",
+			want:     binary,
+		},
+		{
+			name:     "bytes source and bytes modifier",
+			coordStr: "bin://loc?xor",
+			want:     []byte{0xdf, 0x00, 0xff, 0x01, 0xf5},
+		},
+		{
+			name:     "bytes source and built-in modifiers",
+			coordStr: "bin://loc?b64e&b64d",
+			want:     binary,
+		},
+		{
+			name:     "string source, decoded as bytes",
+			coordStr: "base64://IP8A/go=",
+			want:     binary,
+		},
+		{
+			name:     "string source and string modifier",
+			coordStr: "plain://val?mod=a",
+			want:     []byte("val_a"),
+		},
+		{
+			name:     "unsupported source type",
+			coordStr: "unknown://loc",
+			errMatch: spelunk.ErrUnsupportedSecretSourceType,
+		},
+	}
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(&binarySource{val: binary}),
+		spelunk.WithModifier(&xorModifier{}),
+		spelunk.WithModifier(&mockModifier{typ: "mod"}),
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.DigUpBytes(ctx, coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	// The string API is left as is
+	coord, err := types.NewSecretCoord("bin://loc")
+	require.NoError(t, err)
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, base64.StdEncoding.EncodeToString(binary), got)
+}
+
+func TestSpelunker_DigUpBytes_WithCache(t *testing.T) {
+	ctx := context.Background()
+
+	binary := []byte{0xff, 0x00}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(&binarySource{val: binary}),
+		spelunk.WithCache(),
+	)
+
+	coord, err := types.NewSecretCoord("bin://loc")
+	require.NoError(t, err)
+
+	// Values dug-up as string and as bytes are cached apart
+	for range 2 {
+		got, err := spelunker.DigUp(ctx, coord)
+		require.NoError(t, err)
+		require.Equal(t, base64.StdEncoding.EncodeToString(binary), got)
+
+		gotBytes, err := spelunker.DigUpBytes(ctx, coord)
+		require.NoError(t, err)
+		require.Equal(t, binary, gotBytes)
+	}
+}
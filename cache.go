@@ -0,0 +1,294 @@
+package spelunk
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+const (
+	// defaultCacheTTL is the time-to-live of cached values, unless configured otherwise.
+	defaultCacheTTL = 5 * time.Minute
+
+	// defaultCacheMaxEntries is the maximum amount of values held by the default CacheBackend.
+	defaultCacheMaxEntries = 1024
+)
+
+// CacheBackend stores the raw values dug-up by the sources of a Spelunker,
+// when caching is enabled via WithCache.
+//
+// Implementations must be safe for concurrent use.
+type CacheBackend interface {
+	// Get returns the value stored at key, and true if it was found and not expired.
+	Get(key string) (string, bool)
+
+	// Set stores value at key, for the given time-to-live.
+	Set(key string, value string, ttl time.Duration)
+
+	// Delete removes the value stored at key, if any.
+	Delete(key string)
+
+	// Purge removes all stored values.
+	Purge()
+}
+
+// CacheOption options that can be provided to WithCache.
+type CacheOption func(*cacheOptions)
+
+type cacheOptions struct {
+	ttl        time.Duration
+	sourceTTLs map[string]time.Duration
+	maxEntries int
+	backend    CacheBackend
+}
+
+// WithCacheTTL sets the time-to-live of cached values, for all sources
+// that don't have a specific one set via WithCacheSourceTTL.
+// A ttl <= 0 disables caching for those sources.
+//
+// Default is 5 minutes.
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithCacheSourceTTL sets the time-to-live of cached values dug-up by
+// the types.SecretSource of the given type.
+// A ttl <= 0 disables caching for that source.
+func WithCacheSourceTTL(sourceType string, ttl time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.sourceTTLs[sourceType] = ttl
+	}
+}
+
+// WithCacheMaxEntries sets the maximum amount of values held in the cache:
+// when full, the least recently used value is evicted.
+// It only applies to the default in-memory CacheBackend.
+//
+// Default is 1024.
+func WithCacheMaxEntries(maxEntries int) CacheOption {
+	return func(o *cacheOptions) {
+		o.maxEntries = maxEntries
+	}
+}
+
+// WithCacheBackend sets the CacheBackend used to store cached values,
+// replacing the default in-memory one.
+func WithCacheBackend(backend CacheBackend) CacheOption {
+	return func(o *cacheOptions) {
+		o.backend = backend
+	}
+}
+
+// CacheObserver is notified of every lookup into the cache of a Spelunker,
+// and whether it was a hit (e.g. to collect metrics).
+type CacheObserver func(ctx context.Context, coord types.SecretCoord, hit bool)
+
+// WithCacheObserver adds the given CacheObserver, notified of every lookup into the cache.
+// It has no effect if caching is not enabled via WithCache.
+func WithCacheObserver(observer CacheObserver) SpelunkerOption {
+	return func(o *options) {
+		o.cacheObservers = append(o.cacheObservers, observer)
+	}
+}
+
+// secretCache memoizes the raw values dug-up by sources, before modifiers are applied.
+type secretCache struct {
+	opts      cacheOptions
+	observers []CacheObserver
+}
+
+func newSecretCache(opts ...CacheOption) *secretCache {
+	c := &secretCache{
+		opts: cacheOptions{
+			ttl:        defaultCacheTTL,
+			sourceTTLs: make(map[string]time.Duration),
+			maxEntries: defaultCacheMaxEntries,
+		},
+	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	if c.opts.backend == nil {
+		c.opts.backend = NewMemoryCacheBackend(c.opts.maxEntries)
+	}
+	return c
+}
+
+// key returns the cache key for the given coordinates: modifiers are
+// not part of it, as the cache holds raw values dug-up by sources.
+// Parameters are (e.g. a version), as sources dig up different values with them.
+// Values dug-up as bytes (see Spelunker.DigUpBytes) are kept apart,
+// as a source might return them differently.
+func (c *secretCache) key(coord *types.SecretCoord, bytesMode bool) string {
+	key := coord.Type + "://" + coord.Location
+	if len(coord.Params) > 0 {
+		key = types.SecretCoord{Type: coord.Type, Location: coord.Location, Params: coord.Params}.URI()
+	}
+	if bytesMode {
+		return "bytes:" + key
+	}
+	return key
+}
+
+func (c *secretCache) ttl(sourceType string) time.Duration {
+	if ttl, found := c.opts.sourceTTLs[sourceType]; found {
+		return ttl
+	}
+	return c.opts.ttl
+}
+
+// get returns the value cached for coord, notifying the observers of the lookup.
+// Coordinates of sources with caching disabled are not looked up, nor are dig-ups
+// requesting metadata (see Spelunker.DigUpWithMetadata), as the cache doesn't hold it.
+func (c *secretCache) get(ctx context.Context, coord *types.SecretCoord) (string, bool) {
+	if c.ttl(coord.Type) <= 0 || requestedMetadata(ctx) != nil {
+		return "", false
+	}
+	val, found := c.opts.backend.Get(c.key(coord, IsBytesMode(ctx)))
+	for _, observe := range c.observers {
+		observe(ctx, *coord, found)
+	}
+	return val, found
+}
+
+func (c *secretCache) set(ctx context.Context, coord *types.SecretCoord, val string) {
+	if ttl := c.ttl(coord.Type); ttl > 0 {
+		c.opts.backend.Set(c.key(coord, IsBytesMode(ctx)), val, ttl)
+	}
+}
+
+// middleware is the SourceMiddleware serving values from the cache,
+// and storing into it the values dug-up successfully.
+// cacheBypassKey is the context key marking a dig-up that must read around the cache (e.g. by a watcher).
+type cacheBypassKey struct{}
+
+func withCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+func isCacheBypass(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+func (c *secretCache) middleware(next SourceHandler) SourceHandler {
+	return func(
+		ctx context.Context,
+		source types.SecretSource,
+		coord types.SecretCoord,
+	) (string, error) {
+		if isCacheBypass(ctx) {
+			return next(ctx, source, coord)
+		}
+		if val, found := c.get(ctx, &coord); found {
+			return val, nil
+		}
+		val, err := next(ctx, source, coord)
+		if err != nil {
+			return "", err
+		}
+		c.set(ctx, &coord, val)
+		return val, nil
+	}
+}
+
+func (c *secretCache) invalidate(coord *types.SecretCoord) {
+	c.opts.backend.Delete(c.key(coord, false))
+	c.opts.backend.Delete(c.key(coord, true))
+}
+
+func (c *secretCache) purge() {
+	c.opts.backend.Purge()
+}
+
+// memoryCacheBackend is an in-memory, size-bounded, LRU CacheBackend.
+type memoryCacheBackend struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemoryCacheBackend creates an in-memory CacheBackend, holding up to maxEntries values.
+// When full, the least recently used value is evicted.
+// A maxEntries <= 0 means no limit.
+func NewMemoryCacheBackend(maxEntries int) CacheBackend {
+	return &memoryCacheBackend{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (m *memoryCacheBackend) Get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, found := m.entries[key]
+	if !found {
+		return "", false
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		m.remove(elem)
+		return "", false
+	}
+	m.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (m *memoryCacheBackend) Set(key string, value string, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if elem, found := m.entries[key]; found {
+		entry := elem.Value.(*memoryCacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.lru.MoveToFront(elem)
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryCacheEntry{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+	if m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+}
+
+func (m *memoryCacheBackend) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, found := m.entries[key]; found {
+		m.remove(elem)
+	}
+}
+
+func (m *memoryCacheBackend) Purge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = make(map[string]*list.Element)
+	m.lru.Init()
+}
+
+func (m *memoryCacheBackend) remove(elem *list.Element) {
+	m.lru.Remove(elem)
+	delete(m.entries, elem.Value.(*memoryCacheEntry).key)
+}
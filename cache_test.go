@@ -0,0 +1,248 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpelunker_DigUp_WithCache(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithModifier(&mockModifier{typ: "mod"}),
+		spelunk.WithCache(),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	coordWithMod, err := types.NewSecretCoord("test://loc?mod=a")
+	require.NoError(t, err)
+
+	// First dig-up reaches the source, the following ones are served by the cache
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "secret-value", got)
+	got, err = spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "secret-value", got)
+	require.Equal(t, 1, src.Calls())
+
+	// Modifiers are applied to the cached raw value
+	got, err = spelunker.DigUp(ctx, coordWithMod)
+	require.NoError(t, err)
+	require.Equal(t, "secret-value_a", got)
+	require.Equal(t, 1, src.Calls())
+
+	// Invalidation forces the next dig-up to reach the source
+	spelunker.Invalidate(coord)
+	_, err = spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, 2, src.Calls())
+
+	// Same for purging
+	spelunker.Purge()
+	_, err = spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, 3, src.Calls())
+}
+
+func TestSpelunker_DigUp_WithCache_TTL(t *testing.T) {
+	ctx := context.Background()
+
+	shortSrc := util.NewMockSource("short")
+	shortSrc.Val = "short-value"
+	uncachedSrc := util.NewMockSource("uncached")
+	uncachedSrc.Val = "uncached-value"
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(shortSrc),
+		spelunk.WithSource(uncachedSrc),
+		spelunk.WithCache(
+			spelunk.WithCacheSourceTTL("short", 10*time.Millisecond),
+			spelunk.WithCacheSourceTTL("uncached", 0),
+		),
+	)
+
+	shortCoord, err := types.NewSecretCoord("short://loc")
+	require.NoError(t, err)
+	uncachedCoord, err := types.NewSecretCoord("uncached://loc")
+	require.NoError(t, err)
+
+	for range 3 {
+		_, err = spelunker.DigUp(ctx, shortCoord)
+		require.NoError(t, err)
+		_, err = spelunker.DigUp(ctx, uncachedCoord)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, shortSrc.Calls())
+	require.Equal(t, 3, uncachedSrc.Calls())
+
+	// Once expired, the value is dug-up again
+	time.Sleep(20 * time.Millisecond)
+	_, err = spelunker.DigUp(ctx, shortCoord)
+	require.NoError(t, err)
+	require.Equal(t, 2, shortSrc.Calls())
+}
+
+func TestSpelunker_DigUp_WithCache_ErrorsNotCached(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("fail")
+	src.Err = errors.New("boom")
+	spelunker := spelunk.NewSpelunker(spelunk.WithSource(src), spelunk.WithCache())
+
+	coord, err := types.NewSecretCoord("fail://loc")
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = spelunker.DigUp(ctx, coord)
+		require.ErrorIs(t, err, spelunk.ErrFailedToDigUpSecret)
+	}
+	require.Equal(t, 2, src.Calls())
+}
+
+func TestSpelunker_DigUp_WithCache_Twice(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	first := spelunk.NewMemoryCacheBackend(0)
+	last := spelunk.NewMemoryCacheBackend(0)
+	lookups := 0
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithCache(spelunk.WithCacheBackend(first)),
+		spelunk.WithCache(spelunk.WithCacheBackend(last)),
+		spelunk.WithCacheObserver(func(context.Context, types.SecretCoord, bool) {
+			lookups++
+		}),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	for range 2 {
+		_, err = spelunker.DigUp(ctx, coord)
+		require.NoError(t, err)
+	}
+
+	// The last configuration replaces the first: values are cached once
+	require.Equal(t, 1, src.Calls())
+	require.Equal(t, 2, lookups)
+	_, found := first.Get("test://loc")
+	require.False(t, found)
+	_, found = last.Get("test://loc")
+	require.True(t, found)
+}
+
+func TestSpelunker_DigUp_WithCache_Backend(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	backend := spelunk.NewMemoryCacheBackend(0)
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithCache(spelunk.WithCacheBackend(backend)),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+
+	got, found := backend.Get("test://loc")
+	require.True(t, found)
+	require.Equal(t, "secret-value", got)
+}
+
+func TestSpelunker_DigUp_WithCacheObserver(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	uncachedSrc := util.NewMockSource("uncached")
+	uncachedSrc.Val = "uncached-value"
+
+	var lookups []bool
+	spelunker := spelunk.NewSpelunker(
+		// Observers apply regardless of the position relative to WithCache
+		spelunk.WithCacheObserver(func(_ context.Context, coord types.SecretCoord, hit bool) {
+			require.Equal(t, "test", coord.Type)
+			lookups = append(lookups, hit)
+		}),
+		spelunk.WithSource(src),
+		spelunk.WithSource(uncachedSrc),
+		spelunk.WithCache(spelunk.WithCacheSourceTTL("uncached", 0)),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	uncachedCoord, err := types.NewSecretCoord("uncached://loc")
+	require.NoError(t, err)
+	for range 3 {
+		_, err = spelunker.DigUp(ctx, coord)
+		require.NoError(t, err)
+		// Sources with caching disabled are not looked up
+		_, err = spelunker.DigUp(ctx, uncachedCoord)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []bool{false, true, true}, lookups)
+}
+
+func TestMemoryCacheBackend(t *testing.T) {
+	t.Run("evicts least recently used", func(t *testing.T) {
+		backend := spelunk.NewMemoryCacheBackend(2)
+		backend.Set("a", "1", time.Minute)
+		backend.Set("b", "2", time.Minute)
+
+		// Access "a", so that "b" becomes the least recently used
+		_, found := backend.Get("a")
+		require.True(t, found)
+
+		backend.Set("c", "3", time.Minute)
+		_, found = backend.Get("b")
+		require.False(t, found)
+		_, found = backend.Get("a")
+		require.True(t, found)
+		_, found = backend.Get("c")
+		require.True(t, found)
+	})
+
+	t.Run("expires values", func(t *testing.T) {
+		backend := spelunk.NewMemoryCacheBackend(0)
+		backend.Set("a", "1", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		_, found := backend.Get("a")
+		require.False(t, found)
+	})
+
+	t.Run("overwrites, deletes and purges", func(t *testing.T) {
+		backend := spelunk.NewMemoryCacheBackend(0)
+		backend.Set("a", "1", time.Minute)
+		backend.Set("a", "2", time.Minute)
+		got, found := backend.Get("a")
+		require.True(t, found)
+		require.Equal(t, "2", got)
+
+		backend.Delete("a")
+		_, found = backend.Get("a")
+		require.False(t, found)
+
+		backend.Set("a", "1", time.Minute)
+		backend.Set("b", "2", time.Minute)
+		backend.Purge()
+		_, found = backend.Get("a")
+		require.False(t, found)
+		_, found = backend.Get("b")
+		require.False(t, found)
+	})
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+
+	"github.com/detro/spelunk/v2"
+)
+
+type auditArgs struct {
+	Log      string `name:"log"      type:"path" help:"Append an audit event, as JSON line, for every secret access to this file"`
+	Identity string `name:"identity"             help:"Identity recorded in audit events (default: current OS user)"`
+
+	logFile *os.File
+}
+
+// SpelunkerOption returns the spelunk.SpelunkerOption enabling auditing into the audit log file,
+// or nil if no audit log was given.
+func (a *auditArgs) SpelunkerOption() (spelunk.SpelunkerOption, error) {
+	if a.Log == "" {
+		return nil, nil
+	}
+
+	if a.logFile == nil {
+		f, err := os.OpenFile(a.Log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log %q: %w", a.Log, err)
+		}
+		a.logFile = f
+	}
+	slog.Debug("Auditing secret accesses", "audit_log", a.Log)
+
+	return spelunk.WithAuditSink(spelunk.NewJSONLinesAuditSink(a.logFile)), nil
+}
+
+// WithIdentity returns a copy of ctx carrying the identity recorded in audit events.
+func (a *auditArgs) WithIdentity(ctx context.Context) context.Context {
+	identity := a.Identity
+	if identity == "" {
+		if u, err := user.Current(); err == nil {
+			identity = u.Username
+		}
+	}
+	return spelunk.WithAuditIdentity(ctx, identity)
+}
+
+// Close closes the audit log file, if it was opened.
+func (a *auditArgs) Close() error {
+	if a.logFile == nil {
+		return nil
+	}
+	return a.logFile.Close()
+}
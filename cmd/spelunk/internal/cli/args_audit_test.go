@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditArgs_SpelunkerOption(t *testing.T) {
+	var disabled auditArgs
+	opt, err := disabled.SpelunkerOption()
+	require.NoError(t, err)
+	require.Nil(t, opt)
+
+	args := auditArgs{
+		Log:      filepath.Join(t.TempDir(), "audit.jsonl"),
+		Identity: "ci-bot",
+	}
+	opt, err = args.SpelunkerOption()
+	require.NoError(t, err)
+	require.NotNil(t, opt)
+
+	coord, err := types.NewSecretCoord("plain://the-secret")
+	require.NoError(t, err)
+	_, err = spelunk.NewSpelunker(opt).DigUp(args.WithIdentity(t.Context()), coord)
+	require.NoError(t, err)
+	require.NoError(t, args.Close())
+
+	content, err := os.ReadFile(args.Log)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "the-secret")
+
+	var event spelunk.AuditEvent
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(content))), &event))
+	require.Equal(t, "ci-bot", event.Identity)
+	require.Equal(t, "plain", event.Type)
+	require.Equal(t, spelunk.AuditOutcomeSuccess, event.Outcome)
+}
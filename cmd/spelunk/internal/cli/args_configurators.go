@@ -53,6 +53,16 @@ func (c *Configurators) SpelunkerOptions(ctx context.Context) ([]spelunk.Spelunk
 	return opts, nil
 }
 
+// OfflineSpelunkerOptions returns the offline SpelunkerOption of all internal.SecretSourceConfigurator,
+// whether their credentials are detected or not.
+func (c *Configurators) OfflineSpelunkerOptions() []spelunk.SpelunkerOption {
+	opts := make([]spelunk.SpelunkerOption, 0, len(c.All()))
+	for _, p := range c.All() {
+		opts = append(opts, p.OfflineSpelunkerOption())
+	}
+	return opts
+}
+
 // VerifyAll checks all registered internal.SecretSourceConfigurator, logging progress and errors,
 // and returns a joined error if any detected credential failed verification.
 func (c *Configurators) VerifyAll(ctx context.Context) error {
@@ -38,9 +38,14 @@ var (
 
 type CLI struct {
 	// Commands
-	Dig    DigCmd    `cmd:"" default:"withargs" help:"Dig up a secret (default)."`
-	Exists ExistsCmd `cmd:""                    help:"Check if a secret Exists."`
-	Creds  CredsCmd  `cmd:""                    help:"Check all configured credentials."`
+	Dig      DigCmd      `cmd:"" default:"withargs" help:"Dig up a secret (default)."`
+	Exists   ExistsCmd   `cmd:""                    help:"Check if a secret Exists."`
+	Ls       LsCmd       `cmd:""                    help:"List the secret coordinates available under a coordinates prefix."`
+	Cp       CpCmd       `cmd:""                    help:"Copy a secret to other coordinates, possibly of another source."`
+	Diff     DiffCmd     `cmd:""                    help:"Compare two secrets, without revealing their values."`
+	Put      PutCmd      `cmd:""                    help:"Write a secret, read from standard input."`
+	Validate ValidateCmd `cmd:""                    help:"Validate secret coordinates offline (no network call, no credentials needed)."`
+	Creds    CredsCmd    `cmd:""                    help:"Check all configured credentials."`
 
 	Completion kongcompletion.Completion `cmd:"" help:"Generate shell completion scripts."`
 
@@ -48,6 +53,7 @@ type CLI struct {
 	Config Configurators `embed:""`
 
 	loggingArgs `embed:"" group:"Logging:" prefix:"log."`
+	auditArgs   `embed:"" group:"Audit:"   prefix:"audit-"`
 }
 
 func (c *CLI) AfterApply() error {
@@ -58,6 +64,10 @@ func (c *CLI) AfterApply() error {
 	return nil
 }
 
+func (c *CLI) AfterRun() error {
+	return c.auditArgs.Close()
+}
+
 func (c *CLI) NewSpelunker(ctx context.Context) (*spelunk.Spelunker, error) {
 	// Enable configured sources
 	opts, err := c.Config.SpelunkerOptions(ctx)
@@ -66,38 +76,135 @@ func (c *CLI) NewSpelunker(ctx context.Context) (*spelunk.Spelunker, error) {
 	}
 
 	// Enable all modifiers
-	opts = append(opts,
+	opts = append(opts, modifierOptions()...)
+
+	// Enable auditing, if requested
+	auditOpt, err := c.auditArgs.SpelunkerOption()
+	if err != nil {
+		return nil, err
+	}
+	if auditOpt != nil {
+		opts = append(opts, auditOpt)
+	}
+
+	return spelunk.NewSpelunker(opts...), nil
+}
+
+// NewOfflineSpelunker creates a Spelunker with all sources enabled, but without any SDK client:
+// it can't dig up secrets, only validate coordinates (see spelunk.Spelunker.Validate).
+func (c *CLI) NewOfflineSpelunker() *spelunk.Spelunker {
+	opts := append(c.Config.OfflineSpelunkerOptions(), modifierOptions()...)
+	return spelunk.NewSpelunker(opts...)
+}
+
+// modifierOptions returns the SpelunkerOption enabling all modifiers.
+func modifierOptions() []spelunk.SpelunkerOption {
+	return []spelunk.SpelunkerOption{
 		jsonpath.WithJSONPath(),
 		tomlpath.WithTOMLPath(),
 		xpath.WithXPath(),
 		yamlpath.WithYAMLPath(),
-	)
-
-	return spelunk.NewSpelunker(opts...), nil
+	}
 }
 
 func (c *CLI) DigUpSecret(ctx context.Context, coordStr string) (string, error) {
+	coord, sp, err := c.prepareDigUp(ctx, coordStr)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := sp.DigUp(c.auditArgs.WithIdentity(ctx), coord)
+	if err != nil {
+		slog.Error("Failed to dig up secret", "err", err, "coord", coordStr)
+		return "", err
+	}
+	return secret, nil
+}
+
+func (c *CLI) DigUpSecretWithMetadata(
+	ctx context.Context,
+	coordStr string,
+) (string, *types.SecretMetadata, error) {
+	coord, sp, err := c.prepareDigUp(ctx, coordStr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	secret, metadata, err := sp.DigUpWithMetadata(c.auditArgs.WithIdentity(ctx), coord)
+	if err != nil {
+		slog.Error("Failed to dig up secret", "err", err, "coord", coordStr)
+		return "", nil, err
+	}
+	return secret, metadata, nil
+}
+
+// SecretExists checks if the secret at the given coordinates exists, without digging it up
+// if the source supports it (see spelunk.Spelunker.Exists).
+func (c *CLI) SecretExists(ctx context.Context, coordStr string) (bool, error) {
+	coord, sp, err := c.prepareDigUp(ctx, coordStr)
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := sp.Exists(c.auditArgs.WithIdentity(ctx), coord)
+	if err != nil {
+		slog.Error("Failed to check secret existence", "err", err, "coord", coordStr)
+		return false, err
+	}
+	return exists, nil
+}
+
+// ListSecrets lists the coordinates available under the given coordinates prefix
+// (see spelunk.Spelunker.List).
+func (c *CLI) ListSecrets(ctx context.Context, prefixStr string) ([]types.SecretCoord, error) {
+	prefix, sp, err := c.prepareDigUp(ctx, prefixStr)
+	if err != nil {
+		return nil, err
+	}
+
+	coords, err := sp.List(c.auditArgs.WithIdentity(ctx), prefix)
+	if err != nil {
+		slog.Error("Failed to list secrets", "err", err, "prefix", prefixStr)
+		return nil, err
+	}
+	return coords, nil
+}
+
+// PutSecret writes value as the secret at the given coordinates (see spelunk.Spelunker.Put).
+func (c *CLI) PutSecret(ctx context.Context, coordStr, value string, opts types.PutOptions) error {
+	coord, sp, err := c.prepareDigUp(ctx, coordStr)
+	if err != nil {
+		return err
+	}
+
+	if err := sp.Put(c.auditArgs.WithIdentity(ctx), coord, value, opts); err != nil {
+		slog.Error("Failed to put secret", "err", err, "coord", coordStr)
+		return err
+	}
+	return nil
+}
+
+// prepareDigUp parses the given coordinates, and creates the Spelunker to dig them up.
+func (c *CLI) prepareDigUp(
+	ctx context.Context,
+	coordStr string,
+) (*types.SecretCoord, *spelunk.Spelunker, error) {
 	slog.Debug("Resolving secret coordinate", "coord", coordStr)
 	coord, err := types.NewSecretCoord(coordStr)
 	if err != nil {
 		slog.Error("Failed to parse secret coordinate", "err", err, "coord", coordStr)
-		return "", err
+		return nil, nil, err
 	}
 	slog.Log(ctx, logger.LevelTrace, "Coordinate parsed", "coord", coordStr, "parsed", coord)
 
 	sp, err := c.NewSpelunker(ctx)
 	if err != nil {
 		slog.Error("Failed to create spelunker", "err", err)
-		return "", err
+		return nil, nil, err
 	}
 	slog.Log(ctx, logger.LevelTrace, "Spelunker initialized")
 
-	secret, err := sp.DigUp(ctx, coord)
-	if err != nil {
-		slog.Error("Failed to dig up secret", "err", err, "coord", coordStr)
-		return "", err
-	}
-	return secret, nil
+	return coord, sp, nil
 }
 
 func Parse() *kong.Context {
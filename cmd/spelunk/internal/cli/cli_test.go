@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_DigUpSecret_Fallback(t *testing.T) {
+	t.Setenv("SPELUNK_TEST_FALLBACK", " val ")
+	cli := &CLI{}
+
+	// Found secrets are trimmed, fallbacks are returned as is
+	got, err := cli.DigUpSecret(t.Context(), "env://SPELUNK_TEST_FALLBACK?default=fallback")
+	require.NoError(t, err)
+	require.Equal(t, "val", got)
+
+	got, err = cli.DigUpSecret(t.Context(), "env://SPELUNK_TEST_DOES_NOT_EXIST?jp=$.port&default=%208080")
+	require.NoError(t, err)
+	require.Equal(t, " 8080", got)
+
+	got, err = cli.DigUpSecret(t.Context(), "env://SPELUNK_TEST_DOES_NOT_EXIST?optional")
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = cli.DigUpSecret(t.Context(), "env://SPELUNK_TEST_DOES_NOT_EXIST")
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+}
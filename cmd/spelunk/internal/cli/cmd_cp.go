@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+)
+
+// CpCmd copies the secret at the Source coordinates to the Destination coordinates,
+// possibly across sources (e.g. to migrate secrets from Kubernetes to Vault).
+type CpCmd struct {
+	Source      string `arg:"" name:"src-coordinate" help:"Coordinates of the secret to copy (modifiers are applied)."`
+	Destination string `arg:"" name:"dst-coordinate" help:"Coordinates to copy the secret to."`
+
+	Recursive   bool `help:"Copy each key of the source secret (a JSON object, e.g. 'k8s://ns/name/') to the destination, key by key."`
+	DryRun      bool `help:"Print what would be copied, without writing anything."`
+	NoOverwrite bool `help:"Skip destinations that already exist (atomically where the destination supports compare-and-set)."`
+}
+
+func (c *CpCmd) Run(cli *CLI) error {
+	return c.copy(context.Background(), cli, os.Stdout)
+}
+
+// copy copies the secret, reporting each destination to w.
+func (c *CpCmd) copy(ctx context.Context, cli *CLI, w io.Writer) error {
+	src, sp, err := cli.prepareDigUp(ctx, c.Source)
+	if err != nil {
+		return err
+	}
+	dst, err := types.NewSecretCoord(c.Destination)
+	if err == nil {
+		// Fail early, before anything is dug-up
+		err = sp.Validate(dst)
+	}
+	if err != nil {
+		slog.Error("Invalid destination coordinate", "err", err, "coord", c.Destination)
+		return err
+	}
+	ctx = cli.auditArgs.WithIdentity(ctx)
+
+	// Dug-up as bytes, so that it's copied as is (e.g. never trimmed, nor base64-encoded)
+	value, err := sp.DigUpBytes(ctx, src)
+	if err != nil {
+		slog.Error("Failed to dig up secret", "err", err, "coord", c.Source)
+		return err
+	}
+	if !c.Recursive {
+		return c.put(ctx, sp, w, dst, string(value))
+	}
+
+	var data map[string]any
+	// The unmarshalling error is not wrapped, as it could contain part of the secret
+	if err := json.Unmarshal(value, &data); err != nil || data == nil {
+		return fmt.Errorf("%w: to copy recursively, the source secret must be a JSON object", types.ErrInvalidSecretValue)
+	}
+
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(data)) {
+		keyDst := *dst
+		keyDst.Location = strings.TrimSuffix(dst.Location, "/") + "/" + key
+
+		keyValue, isString := data[key].(string)
+		if !isString {
+			// Values that aren't strings (e.g. numbers, objects) are copied as JSON
+			keyValueBytes, _ := json.Marshal(data[key])
+			keyValue = string(keyValueBytes)
+		}
+		if err := c.put(ctx, sp, w, &keyDst, keyValue); err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// put writes value at dst, unless skipped (see DryRun and NoOverwrite), reporting it to w.
+//
+// With NoOverwrite, the write is create-only (see types.VersionNone) where the destination supports
+// compare-and-set (e.g. Vault KV v2, Kubernetes). Elsewhere, it relies on the existence check alone:
+// a destination created between the check and the write is overwritten.
+func (c *CpCmd) put(
+	ctx context.Context,
+	sp *spelunk.Spelunker,
+	w io.Writer,
+	dst *types.SecretCoord,
+	value string,
+) error {
+	if c.NoOverwrite {
+		exists, err := sp.Exists(ctx, dst)
+		if err != nil {
+			slog.Error("Failed to check destination secret", "err", err, "coord", dst.URI())
+			return err
+		}
+		if exists {
+			_, err = fmt.Fprintf(w, "skipped %s (already exists)\n", dst.URI())
+			return err
+		}
+	}
+
+	if c.DryRun {
+		_, err := fmt.Fprintf(w, "would copy to %s\n", dst.URI())
+		return err
+	}
+	opts := types.PutOptions{}
+	if c.NoOverwrite {
+		opts.IfVersion = types.VersionNone
+	}
+	err := sp.Put(ctx, dst, value, opts)
+	if c.NoOverwrite && errors.Is(err, types.ErrCompareAndSetNotSupported) {
+		// Best-effort: the existence check above is all there is
+		err = sp.Put(ctx, dst, value, types.PutOptions{})
+	}
+	if c.NoOverwrite && errors.Is(err, types.ErrVersionConflict) {
+		// Created since the existence check
+		_, err = fmt.Fprintf(w, "skipped %s (already exists)\n", dst.URI())
+		return err
+	}
+	if err != nil {
+		slog.Error("Failed to put secret", "err", err, "coord", dst.URI())
+		return err
+	}
+	_, err = fmt.Fprintf(w, "copied to %s\n", dst.URI())
+	return err
+}
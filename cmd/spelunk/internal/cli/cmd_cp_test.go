@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCpCmd_copy(t *testing.T) {
+	cli := &CLI{}
+	dir := t.TempDir()
+	fileCoord := func(name string) string {
+		return "file://" + filepath.ToSlash(filepath.Join(dir, name))
+	}
+	readFile := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return string(content)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src.json"), []byte(`{"user": "admin", "port": 5432}`+"\n"), 0o600))
+
+	// Copied as is (not trimmed)
+	var out bytes.Buffer
+	cmd := &CpCmd{Source: fileCoord("src.json"), Destination: fileCoord("copy.json")}
+	require.NoError(t, cmd.copy(t.Context(), cli, &out))
+	require.Equal(t, `{"user": "admin", "port": 5432}`+"\n", readFile("copy.json"))
+	require.Equal(t, "copied to "+fileCoord("copy.json")+"\n", out.String())
+
+	// Modifiers are applied
+	out.Reset()
+	cmd = &CpCmd{Source: fileCoord("src.json?jp=$.user"), Destination: fileCoord("user.txt")}
+	require.NoError(t, cmd.copy(t.Context(), cli, &out))
+	require.Equal(t, "admin", readFile("user.txt"))
+
+	// Recursively, key by key, skipping what exists
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "keys"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keys", "user"), []byte("root"), 0o600))
+	out.Reset()
+	cmd = &CpCmd{Source: fileCoord("src.json"), Destination: fileCoord("keys/"), Recursive: true, NoOverwrite: true}
+	require.NoError(t, cmd.copy(t.Context(), cli, &out))
+	require.Equal(t, "5432", readFile("keys/port"))
+	require.Equal(t, "root", readFile("keys/user"))
+	require.Equal(t, "copied to "+fileCoord("keys/port")+"\n"+
+		"skipped "+fileCoord("keys/user")+" (already exists)\n", out.String())
+
+	// Dry-run writes nothing
+	out.Reset()
+	cmd = &CpCmd{Source: fileCoord("src.json"), Destination: fileCoord("dry/"), Recursive: true, DryRun: true}
+	require.NoError(t, cmd.copy(t.Context(), cli, &out))
+	require.NoDirExists(t, filepath.Join(dir, "dry"))
+	require.Equal(t, "would copy to "+fileCoord("dry/port")+"\n"+
+		"would copy to "+fileCoord("dry/user")+"\n", out.String())
+
+	// Recursive copies need a JSON object
+	cmd = &CpCmd{Source: fileCoord("user.txt"), Destination: fileCoord("keys/"), Recursive: true}
+	require.ErrorIs(t, cmd.copy(t.Context(), cli, &out), types.ErrInvalidSecretValue)
+
+	// Nothing is dug-up, if the destination is invalid
+	cmd = &CpCmd{Source: fileCoord("src.json"), Destination: "nope://x"}
+	require.Error(t, cmd.copy(t.Context(), cli, &out))
+}
+
+func TestCpCmd_copy_NoOverwrite_CompareAndSet(t *testing.T) {
+	// A Vault that never has the secret when read, but always has it when written:
+	// it's created between the existence check and the write
+	var cas []any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": []}`))
+			return
+		}
+		var body map[string]map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		cas = append(cas, body["options"]["cas"])
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors": ["check-and-set parameter did not match the current version"]}`))
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	cli := &CLI{}
+	cli.Config.Vault.Instances = map[string]string{"vault-test": srv.URL}
+	srcPath := filepath.Join(t.TempDir(), "src.txt")
+	require.NoError(t, os.WriteFile(srcPath, []byte("s3cret"), 0o600))
+
+	var out bytes.Buffer
+	cmd := &CpCmd{Source: "file://" + filepath.ToSlash(srcPath), Destination: "vault-test://kv/data/app/token", NoOverwrite: true}
+	require.NoError(t, cmd.copy(t.Context(), cli, &out))
+	require.Equal(t, "skipped vault-test://kv/data/app/token (already exists)\n", out.String())
+	require.Equal(t, []any{float64(0)}, cas)
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// ErrSecretsDiffer is returned by DiffCmd when the compared secrets are not equal.
+var ErrSecretsDiffer = fmt.Errorf("secrets differ")
+
+// Exit codes of DiffCmd, like diff(1): 0 if the secrets are equal.
+const (
+	diffExitCodeDifferent = 1
+	diffExitCodeFailure   = 2
+)
+
+// diffHashLen is the number of hex characters of the hashes printed by DiffCmd.
+const diffHashLen = 16
+
+// DiffCmd compares the secrets at coordinates A and B (e.g. across environments, or sources),
+// without revealing their values: only keyed hashes (HMAC-SHA256) of the values are printed.
+//
+// If both secrets are JSON objects (e.g. 'k8s://ns/name/'), the keys added, removed or changed are reported.
+type DiffCmd struct {
+	CoordinateA string `arg:"" name:"coordinate-a" help:"Coordinates of the first secret (modifiers are applied)."`
+	CoordinateB string `arg:"" name:"coordinate-b" help:"Coordinates of the second secret (modifiers are applied)."`
+
+	HashKey string `name:"hash-key" env:"SPELUNK_DIFF_HASH_KEY" help:"Key of the hashes printed in place of values (random if unset, so hashes can't be compared across runs)."`
+}
+
+func (c *DiffCmd) Run(cli *CLI) error {
+	differ, err := c.diff(context.Background(), cli, os.Stdout)
+	switch {
+	case err != nil:
+		return &exitCodeError{err: err, code: diffExitCodeFailure}
+	case differ:
+		return &exitCodeError{err: ErrSecretsDiffer, code: diffExitCodeDifferent}
+	default:
+		return nil
+	}
+}
+
+// diff compares the secrets, reporting the differences to w, and returns true if they differ.
+func (c *DiffCmd) diff(ctx context.Context, cli *CLI, w io.Writer) (bool, error) {
+	coordA, sp, err := cli.prepareDigUp(ctx, c.CoordinateA)
+	if err != nil {
+		return false, err
+	}
+	coordB, err := types.NewSecretCoord(c.CoordinateB)
+	if err == nil {
+		// Fail early, before anything is dug-up
+		err = sp.Validate(coordB)
+	}
+	if err != nil {
+		slog.Error("Invalid secret coordinate", "err", err, "coord", c.CoordinateB)
+		return false, err
+	}
+	ctx = cli.auditArgs.WithIdentity(ctx)
+
+	// Dug-up as bytes, so that values are compared as stored (e.g. never trimmed)
+	valueA, err := sp.DigUpBytes(ctx, coordA)
+	if err != nil {
+		slog.Error("Failed to dig up secret", "err", err, "coord", c.CoordinateA)
+		return false, err
+	}
+	valueB, err := sp.DigUpBytes(ctx, coordB)
+	if err != nil {
+		slog.Error("Failed to dig up secret", "err", err, "coord", c.CoordinateB)
+		return false, err
+	}
+
+	hash, err := c.hasher()
+	if err != nil {
+		return false, err
+	}
+
+	var dataA, dataB map[string]any
+	if json.Unmarshal(valueA, &dataA) != nil || json.Unmarshal(valueB, &dataB) != nil || dataA == nil || dataB == nil {
+		// Not both JSON objects: compared as a whole
+		if bytes.Equal(valueA, valueB) {
+			_, err = fmt.Fprintln(w, "equal")
+			return false, err
+		}
+		_, err = fmt.Fprintf(w, "~ %s -> %s\n", hash(valueA), hash(valueB))
+		return true, err
+	}
+
+	differ := false
+	keys := slices.Collect(maps.Keys(dataA))
+	for key := range dataB {
+		if _, inA := dataA[key]; !inA {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		keyValueA, inA := dataA[key]
+		keyValueB, inB := dataB[key]
+
+		var line string
+		switch {
+		case !inB:
+			line = fmt.Sprintf("- %s %s", key, hash(diffBytes(keyValueA)))
+		case !inA:
+			line = fmt.Sprintf("+ %s %s", key, hash(diffBytes(keyValueB)))
+		case !bytes.Equal(diffBytes(keyValueA), diffBytes(keyValueB)):
+			line = fmt.Sprintf("~ %s %s -> %s", key, hash(diffBytes(keyValueA)), hash(diffBytes(keyValueB)))
+		default:
+			continue
+		}
+		differ = true
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return differ, err
+		}
+	}
+	if !differ {
+		_, err = fmt.Fprintln(w, "equal")
+	}
+	return differ, err
+}
+
+// hasher returns the function hashing the values, keyed with HashKey (or a random key, if unset).
+func (c *DiffCmd) hasher() (func([]byte) string, error) {
+	key := []byte(c.HashKey)
+	if len(key) == 0 {
+		key = make([]byte, sha256.Size)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(value []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(value)
+		return hex.EncodeToString(mac.Sum(nil))[:diffHashLen]
+	}, nil
+}
+
+// diffBytes returns the bytes of the value of a JSON object key, as compared by DiffCmd:
+// strings as they are, anything else (e.g. numbers, objects) as JSON.
+func diffBytes(value any) []byte {
+	if str, isString := value.(string); isString {
+		return []byte(str)
+	}
+	valueBytes, _ := json.Marshal(value)
+	return valueBytes
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffCmd_diff(t *testing.T) {
+	cli := &CLI{}
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		return "file://" + filepath.ToSlash(filepath.Join(dir, name))
+	}
+	prod := writeFile("prod.json", `{"user": "admin", "pass": "s3cr3t", "port": 5432}`)
+	staging := writeFile("staging.json", `{"user": "admin", "pass": "hunter2", "host": "db"}`)
+	token := writeFile("token", "s3cr3t")
+	tokenWithNewline := writeFile("token-nl", "s3cr3t\n")
+
+	hash := func(value string) string {
+		h, err := (&DiffCmd{HashKey: "key"}).hasher()
+		require.NoError(t, err)
+		return h([]byte(value))
+	}
+
+	tests := []struct {
+		name       string
+		a, b       string
+		wantDiffer bool
+		wantOut    string
+	}{
+		{
+			name:    "equal objects",
+			a:       prod,
+			b:       prod,
+			wantOut: "equal\n",
+		},
+		{
+			name:       "different objects",
+			a:          prod,
+			b:          staging,
+			wantDiffer: true,
+			wantOut: "+ host " + hash("db") + "\n" +
+				"~ pass " + hash("s3cr3t") + " -> " + hash("hunter2") + "\n" +
+				"- port " + hash("5432") + "\n",
+		},
+		{
+			name:    "equal values",
+			a:       prod + "?jp=$.pass",
+			b:       token,
+			wantOut: "equal\n",
+		},
+		{
+			name:       "different values",
+			a:          token,
+			b:          tokenWithNewline,
+			wantDiffer: true,
+			wantOut:    "~ " + hash("s3cr3t") + " -> " + hash("s3cr3t\n") + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &DiffCmd{CoordinateA: tt.a, CoordinateB: tt.b, HashKey: "key"}
+			differ, err := cmd.diff(t.Context(), cli, &out)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantDiffer, differ)
+			require.Equal(t, tt.wantOut, out.String())
+			require.NotContains(t, out.String(), "s3cr3t")
+		})
+	}
+
+	// Hashes are random across runs, unless keyed
+	var out1, out2 bytes.Buffer
+	_, err := (&DiffCmd{CoordinateA: token, CoordinateB: tokenWithNewline}).diff(t.Context(), cli, &out1)
+	require.NoError(t, err)
+	_, err = (&DiffCmd{CoordinateA: token, CoordinateB: tokenWithNewline}).diff(t.Context(), cli, &out2)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(out1.String(), "~ "))
+	require.NotEqual(t, out1.String(), out2.String())
+
+	// Failures
+	_, err = (&DiffCmd{CoordinateA: token, CoordinateB: writeFile("other", "x") + "-missing"}).diff(t.Context(), cli, &out1)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+	_, err = (&DiffCmd{CoordinateA: token, CoordinateB: "nope://x"}).diff(t.Context(), cli, &out1)
+	require.Error(t, err)
+}
+
+func TestDiffCmd_Run(t *testing.T) {
+	dir := t.TempDir()
+	coord := "file://" + filepath.ToSlash(filepath.Join(dir, "secret"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("val"), 0o600))
+
+	require.NoError(t, (&DiffCmd{CoordinateA: coord, CoordinateB: coord}).Run(&CLI{}))
+
+	// Exit codes are like diff(1), and kept by WithExitCode
+	err := WithExitCode((&DiffCmd{CoordinateA: coord, CoordinateB: "env://SPELUNK_TEST_DIFF"}).Run(&CLI{}))
+	exitErr, errMatched := err.(*exitCodeError)
+	require.True(t, errMatched)
+	require.Equal(t, diffExitCodeFailure, exitErr.ExitCode())
+
+	t.Setenv("SPELUNK_TEST_DIFF", "other")
+	err = WithExitCode((&DiffCmd{CoordinateA: coord, CoordinateB: "env://SPELUNK_TEST_DIFF"}).Run(&CLI{}))
+	require.ErrorIs(t, err, ErrSecretsDiffer)
+	exitErr, errMatched = err.(*exitCodeError)
+	require.True(t, errMatched)
+	require.Equal(t, diffExitCodeDifferent, exitErr.ExitCode())
+}
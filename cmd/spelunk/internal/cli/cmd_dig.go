@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
@@ -10,16 +11,45 @@ import (
 // DigCmd digs up a secret at the given Coordinate.
 type DigCmd struct {
 	coordsArgs `embed:""`
+
+	Meta bool `help:"Print the metadata of the secret (e.g. version, creation time) as JSON, to standard error."`
 }
 
 func (c *DigCmd) Run(cli *CLI) error {
 	ctx := context.Background()
+	if c.Meta {
+		return c.runWithMetadata(ctx, cli)
+	}
+
 	secret, err := cli.DigUpSecret(ctx, c.Coordinate)
 	if err != nil {
 		return err
 	}
+	return c.write(secret)
+}
+
+func (c *DigCmd) runWithMetadata(ctx context.Context, cli *CLI) error {
+	secret, metadata, err := cli.DigUpSecretWithMetadata(ctx, c.Coordinate)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(os.Stderr).Encode(metadata); err != nil {
+		slog.Error(
+			"Failed to write metadata to standard error",
+			"err",
+			err,
+			"coord",
+			c.Coordinate,
+		)
+		return err
+	}
+	return c.write(secret)
+}
 
-	_, err = fmt.Fprint(os.Stdout, secret)
+// write writes the secret to standard output.
+func (c *DigCmd) write(secret string) error {
+	_, err := fmt.Fprint(os.Stdout, secret)
 	if err != nil {
 		slog.Error(
 			"Failed to write secret to standard output",
@@ -2,18 +2,34 @@ package cli
 
 import (
 	"context"
+
+	"github.com/detro/spelunk/v2/types"
 )
 
 // ExistsCmd confirms existence of a secret at the given Coordinate.
+// When the source supports it, the secret is not dug-up: only its metadata is looked up.
 type ExistsCmd struct {
 	coordsArgs `embed:""`
 }
 
 func (c *ExistsCmd) Run(cli *CLI) error {
 	ctx := context.Background()
-	_, err := cli.DigUpSecret(ctx, c.Coordinate)
+	exists, err := cli.SecretExists(ctx, c.Coordinate)
 	if err != nil {
 		return err
 	}
+	if !exists {
+		// Reported as a not found dig-up failure, so that the CLI exits with the matching code
+		coord, err := types.NewSecretCoord(c.Coordinate)
+		if err != nil {
+			return err
+		}
+		return types.NewDigUpError(
+			types.ErrorStageSource,
+			coord.Type,
+			types.RedactedLocation,
+			types.ErrSecretNotFound,
+		)
+	}
 	return nil
 }
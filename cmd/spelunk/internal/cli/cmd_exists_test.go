@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExistsCmd_Run(t *testing.T) {
+	t.Setenv("SPELUNK_TEST_EXISTS", `{"key":"val"}`)
+	cli := &CLI{}
+
+	cmd := &ExistsCmd{coordsArgs{Coordinate: "env://SPELUNK_TEST_EXISTS"}}
+	require.NoError(t, cmd.Run(cli))
+
+	cmd = &ExistsCmd{coordsArgs{Coordinate: "env://SPELUNK_TEST_EXISTS?jp=$.key"}}
+	require.NoError(t, cmd.Run(cli))
+
+	// Modifiers are applied: a key that is not there is not found
+	cmd = &ExistsCmd{coordsArgs{Coordinate: "env://SPELUNK_TEST_EXISTS?jp=$.missing"}}
+	require.ErrorIs(t, cmd.Run(cli), types.ErrSecretNotFound)
+
+	cmd = &ExistsCmd{coordsArgs{Coordinate: "env://SPELUNK_TEST_DOES_NOT_EXIST"}}
+	err := cmd.Run(cli)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+
+	var exitCoder kong.ExitCoder
+	require.ErrorAs(t, WithExitCode(err), &exitCoder)
+	require.Equal(t, 3, exitCoder.ExitCode())
+
+	cmd = &ExistsCmd{coordsArgs{Coordinate: "nope://x"}}
+	require.Error(t, cmd.Run(cli))
+}
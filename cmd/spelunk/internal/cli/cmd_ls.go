@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// LsCmd lists the coordinates available under the given coordinates Prefix.
+type LsCmd struct {
+	Prefix string `arg:"" name:"coord-prefix" help:"Coordinates prefix to list (e.g. 'vault://kv/data/team-a/')."`
+
+	JSON bool `help:"Print the coordinates as a JSON array, rather than one per line."`
+}
+
+func (c *LsCmd) Run(cli *CLI) error {
+	ctx := context.Background()
+	coords, err := cli.ListSecrets(ctx, c.Prefix)
+	if err != nil {
+		return err
+	}
+
+	if err := c.write(os.Stdout, coords); err != nil {
+		slog.Error("Failed to write coordinates to standard output", "err", err, "prefix", c.Prefix)
+		return err
+	}
+	return nil
+}
+
+// write writes the coordinates to w, as URIs.
+func (c *LsCmd) write(w io.Writer, coords []types.SecretCoord) error {
+	if c.JSON {
+		if coords == nil {
+			coords = []types.SecretCoord{}
+		}
+		return json.NewEncoder(w).Encode(coords)
+	}
+
+	for _, coord := range coords {
+		if _, err := fmt.Fprintln(w, coord.URI()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLsCmd_write(t *testing.T) {
+	coords := []types.SecretCoord{
+		{Type: "vault", Location: "kv/data/team-a/db/"},
+		{Type: "vault", Location: "kv/data/team-a/api key/"},
+	}
+
+	tests := []struct {
+		name   string
+		json   bool
+		coords []types.SecretCoord
+		want   string
+	}{
+		{
+			name:   "plain",
+			coords: coords,
+			want:   "vault://kv/data/team-a/db/\nvault://kv/data/team-a/api%20key/\n",
+		},
+		{
+			name:   "json",
+			json:   true,
+			coords: coords,
+			want:   `["vault://kv/data/team-a/db/","vault://kv/data/team-a/api%20key/"]` + "\n",
+		},
+		{
+			name: "plain, empty",
+			want: "",
+		},
+		{
+			name: "json, empty",
+			json: true,
+			want: "[]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cmd := &LsCmd{JSON: tt.json}
+			require.NoError(t, cmd.write(&buf, tt.coords))
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestLsCmd_Run(t *testing.T) {
+	cli := &CLI{}
+
+	// Built-in sources can't list
+	cmd := &LsCmd{Prefix: "env://SPELUNK_"}
+	require.ErrorIs(t, cmd.Run(cli), spelunk.ErrListingNotSupported)
+
+	cmd = &LsCmd{Prefix: "://nope"}
+	require.ErrorIs(t, cmd.Run(cli), types.ErrSecretCoordFailedParsing)
+}
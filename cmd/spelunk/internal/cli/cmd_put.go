@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// PutCmd writes the secret read from standard input at the given Coordinate,
+// creating it if it doesn't exist.
+type PutCmd struct {
+	coordsArgs `embed:""`
+
+	IfVersion   string `help:"Write only if the secret is at this version (compare-and-set, e.g. Vault KV v2 version, Kubernetes resourceVersion)."`
+	TrimNewline bool   `help:"Remove the trailing newline from the value read (e.g. as written by 'echo')."`
+}
+
+func (c *PutCmd) Run(cli *CLI) error {
+	return c.put(context.Background(), cli, os.Stdin)
+}
+
+// put writes the secret read from r.
+func (c *PutCmd) put(ctx context.Context, cli *CLI, r io.Reader) error {
+	value, err := io.ReadAll(r)
+	if err != nil {
+		slog.Error("Failed to read secret from standard input", "err", err, "coord", c.Coordinate)
+		return err
+	}
+
+	secret := string(value)
+	if c.TrimNewline {
+		secret = strings.TrimSuffix(strings.TrimSuffix(secret, "\n"), "\r")
+	}
+	return cli.PutSecret(ctx, c.Coordinate, secret, types.PutOptions{IfVersion: c.IfVersion})
+}
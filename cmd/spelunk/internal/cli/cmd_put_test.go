@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutCmd_put(t *testing.T) {
+	cli := &CLI{}
+	path := filepath.Join(t.TempDir(), "secret.txt")
+
+	// Written as read, unless asked to trim the trailing newline
+	cmd := &PutCmd{coordsArgs: coordsArgs{Coordinate: "file://" + filepath.ToSlash(path)}}
+	require.NoError(t, cmd.put(t.Context(), cli, strings.NewReader("s3cret\n")))
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "s3cret\n", string(got))
+
+	cmd.TrimNewline = true
+	require.NoError(t, cmd.put(t.Context(), cli, strings.NewReader("s3cret\r\n")))
+	got, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", string(got))
+
+	cmd.IfVersion = "1"
+	err = cmd.put(t.Context(), cli, strings.NewReader("s3cret"))
+	require.ErrorIs(t, err, types.ErrCompareAndSetNotSupported)
+
+	// Sources that can't be written
+	cmd = &PutCmd{coordsArgs: coordsArgs{Coordinate: "env://SPELUNK_TEST_PUT"}}
+	err = cmd.put(t.Context(), cli, strings.NewReader("s3cret"))
+	require.ErrorIs(t, err, spelunk.ErrWritingNotSupported)
+
+	var exitCoder kong.ExitCoder
+	require.ErrorAs(t, WithExitCode(err), &exitCoder)
+	require.Equal(t, 1, exitCoder.ExitCode())
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// ValidateCmd validates the given Coordinates offline: scheme, location syntax, modifier names and arguments.
+type ValidateCmd struct {
+	Coordinates []string `arg:"" name:"coordinate" help:"Coordinates to the Secrets."`
+}
+
+func (c *ValidateCmd) Run(cli *CLI) error {
+	sp := cli.NewOfflineSpelunker()
+
+	var errs []error
+	for idx, coordStr := range c.Coordinates {
+		coord, err := types.NewSecretCoord(coordStr)
+		if err == nil {
+			err = sp.Validate(coord)
+		}
+		if err != nil {
+			// Coordinates are referred to by position, as they might be inline secrets
+			slog.Error("Invalid secret coordinate", "err", err, "position", idx+1)
+			errs = append(errs, fmt.Errorf("coordinate #%d: %w", idx+1, err))
+			continue
+		}
+		slog.Debug("Valid secret coordinate", "type", coord.Type, "position", idx+1)
+	}
+	return errors.Join(errs...)
+}
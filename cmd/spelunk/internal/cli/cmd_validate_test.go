@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCmd_Run(t *testing.T) {
+	cli := &CLI{}
+	cli.Config.Vault.Instances = map[string]string{"vault-eu": "http://127.0.0.1:1"}
+
+	cmd := &ValidateCmd{Coordinates: []string{
+		"vault://secret/data/app/key?jp=$.password",
+		"vault-eu://secret/data/app/key",
+		"k8s://ns/name/key",
+		"aws://prod/db",
+		"gcp://projects/my-project/secrets/db-password",
+		"env://HOME",
+	}}
+	require.NoError(t, cmd.Run(cli))
+
+	cmd = &ValidateCmd{Coordinates: []string{
+		"vault://secret/data/app/key",
+		"vault://key",
+		"k8s://ns/name/key?jp=$.[",
+		"nope://x",
+	}}
+	err := cmd.Run(cli)
+	require.ErrorIs(t, err, types.ErrInvalidLocation)
+	require.ErrorIs(t, err, spelunk.ErrInvalidModifier)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	require.ErrorContains(t, err, "coordinate #2")
+	require.NotContains(t, err.Error(), "coordinate #1")
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/alecthomas/kong"
+	"github.com/detro/spelunk/v2/types"
+)
+
+// exitCodes maps the class of a dig-up failure to the exit code of the CLI.
+var exitCodes = map[types.ErrorClass]int{
+	types.ErrorClassUnknown:          1,
+	types.ErrorClassInvalidLocation:  2,
+	types.ErrorClassNotFound:         3,
+	types.ErrorClassPermissionDenied: 4,
+	types.ErrorClassUnauthenticated:  5,
+	types.ErrorClassTransient:        6,
+	types.ErrorClassConflict:         7,
+}
+
+// exitCodeError is an error carrying the exit code of the CLI.
+// It implements kong.ExitCoder.
+type exitCodeError struct {
+	err  error
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+func (e *exitCodeError) ExitCode() int {
+	return e.code
+}
+
+// WithExitCode wraps err, if it is a types.DigUpError, so that the CLI exits with the code
+// matching its types.ErrorClass. Any other error is returned as is, and exits with code 1,
+// unless it already carries an exit code (i.e. it is a kong.ExitCoder, like the failures of DiffCmd).
+func WithExitCode(err error) error {
+	var exitCoder kong.ExitCoder
+	if errors.As(err, &exitCoder) {
+		return err
+	}
+	digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+	if !errMatched {
+		return err
+	}
+	code, found := exitCodes[digUpErr.Class]
+	if !found {
+		code = exitCodes[types.ErrorClassUnknown]
+	}
+	return &exitCodeError{err: err, code: code}
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		coordStr string
+		wantCode int
+	}{
+		{
+			name:     "not found",
+			coordStr: "env://SPELUNK_TEST_DOES_NOT_EXIST",
+			wantCode: 3,
+		},
+		{
+			name:     "invalid location",
+			coordStr: "vault://",
+			wantCode: 2,
+		},
+		{
+			name:     "unsupported source",
+			coordStr: "unknown://loc",
+			wantCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			if err == nil {
+				_, err = spelunk.NewSpelunker().DigUp(t.Context(), coord)
+			}
+			require.Error(t, err)
+
+			var exitCoder kong.ExitCoder
+			require.ErrorAs(t, WithExitCode(err), &exitCoder)
+			require.Equal(t, tt.wantCode, exitCoder.ExitCode())
+			require.ErrorIs(t, WithExitCode(err), err)
+		})
+	}
+
+	require.NoError(t, WithExitCode(nil))
+	other := errors.New("boom")
+	require.Equal(t, other, WithExitCode(other))
+
+	// Exit codes already set are kept
+	withCode := &exitCodeError{err: types.NewDigUpError(types.ErrorStageSource, "env", "", types.ErrSecretNotFound), code: 2}
+	require.Equal(t, withCode, WithExitCode(withCode))
+}
@@ -23,6 +23,11 @@ type SecretSourceConfigurator interface {
 	// In all other cases, a descriptive error of what went wrong.
 	SpelunkerOption(ctx context.Context) (spelunk.SpelunkerOption, error)
 
+	// OfflineSpelunkerOption returns a configuration option for Spelunker, enabling the types.SecretSource
+	// (and its named instances, if any) without an SDK client, regardless of the credentials detected.
+	// It's only meant for operations that never reach the secret provider, like Spelunker.Validate.
+	OfflineSpelunkerOption() spelunk.SpelunkerOption
+
 	// CredentialsValid validates credentials, via a lightweight non-mutating operation against the underlying secret provider.
 	// Returns an error if something goes wrong, including if the credentials were not even detected in the first
 	// place (i.e. CredentialsDetected already returned false).
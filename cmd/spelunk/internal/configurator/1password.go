@@ -67,6 +67,10 @@ func (c *OnePasswordConfigurator) SpelunkerOption(
 	return spelunkop.With1Password(client), nil
 }
 
+func (c *OnePasswordConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	return spelunkop.With1Password(nil)
+}
+
 func (c *OnePasswordConfigurator) CredentialsValid(ctx context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
@@ -86,6 +86,10 @@ func (c *AWSConfigurator) SpelunkerOption(ctx context.Context) (spelunk.Spelunke
 	return spelunkaws.WithAWS(client), nil
 }
 
+func (c *AWSConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	return spelunkaws.WithAWS(nil)
+}
+
 func (c *AWSConfigurator) CredentialsValid(ctx context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
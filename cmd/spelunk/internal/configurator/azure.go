@@ -105,6 +105,10 @@ func (c *AzureConfigurator) SpelunkerOption(ctx context.Context) (spelunk.Spelun
 	return spelunkaz.WithAzure(client), nil
 }
 
+func (c *AzureConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	return spelunkaz.WithAzure(nil)
+}
+
 func (c *AzureConfigurator) CredentialsValid(ctx context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
@@ -69,6 +69,10 @@ func (c *BitwardenConfigurator) SpelunkerOption(
 	return spelunkbw.WithBitwarden(client), nil
 }
 
+func (c *BitwardenConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	return spelunkbw.WithBitwarden(nil)
+}
+
 func (c *BitwardenConfigurator) CredentialsValid(_ context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
@@ -77,6 +77,10 @@ func (c *GCPConfigurator) SpelunkerOption(ctx context.Context) (spelunk.Spelunke
 	return spelunkgcp.WithGCP(client), nil
 }
 
+func (c *GCPConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	return spelunkgcp.WithGCP(nil)
+}
+
 func (c *GCPConfigurator) CredentialsValid(ctx context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
@@ -0,0 +1,21 @@
+package configurator
+
+import (
+	"strings"
+)
+
+// instanceEnvPrefix returns the prefix of the environment variables configuring the named instance
+// with the given scheme: upper-cased, with any non-alphanumeric character replaced by `_`
+// (e.g. `vault-eu` becomes `VAULT_EU`).
+func instanceEnvPrefix(scheme string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, scheme)
+}
@@ -0,0 +1,118 @@
+package configurator_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/detro/spelunk/cmd/spelunk/internal/configurator"
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newJSONServer returns a server that responds to every request with the JSON body returned by respond.
+func newJSONServer(t *testing.T, respond func(r *http.Request) string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, respond(r))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func digUpAll(t *testing.T, opt spelunk.SpelunkerOption, want map[string]string) {
+	t.Helper()
+	spelunker := spelunk.NewSpelunker(opt)
+	for coordStr, wantVal := range want {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		got, err := spelunker.DigUp(t.Context(), coord)
+		require.NoError(t, err, coordStr)
+		require.Equal(t, wantVal, got, coordStr)
+	}
+}
+
+func TestVaultConfigurator_Instances(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+	t.Setenv("VAULT_EU_TOKEN", "eu-token")
+
+	newVaultServer := func(name string) *httptest.Server {
+		return newJSONServer(t, func(r *http.Request) string {
+			return fmt.Sprintf(`{"data":{"key":"%s-%s"}}`, name, r.Header.Get("X-Vault-Token"))
+		})
+	}
+	eu := newVaultServer("eu")
+	us := newVaultServer("us")
+
+	c := &configurator.VaultConfigurator{
+		Instances: map[string]string{
+			"vault-eu": eu.URL,
+			"vault-us": us.URL,
+		},
+		Token: "shared-token",
+	}
+	require.True(t, c.CredentialsDetected())
+
+	opt, err := c.SpelunkerOption(t.Context())
+	require.NoError(t, err)
+	digUpAll(t, opt, map[string]string{
+		"vault-eu://kv/app/key": "eu-eu-token",
+		"vault-us://kv/app/key": "us-shared-token",
+	})
+}
+
+func TestKubernetesConfigurator_Contexts(t *testing.T) {
+	t.Setenv("KUBECONFIG", "")
+
+	newKubeServer := func(name string) *httptest.Server {
+		return newJSONServer(t, func(_ *http.Request) string {
+			return fmt.Sprintf(
+				`{"kind":"Secret","apiVersion":"v1","metadata":{"name":"my-secret"},"data":{"password":%q}}`,
+				base64.StdEncoding.EncodeToString([]byte(name)),
+			)
+		})
+	}
+	prod := newKubeServer("prod")
+	staging := newKubeServer("staging")
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster: {server: %q}
+- name: staging
+  cluster: {server: %q}
+contexts:
+- name: prod
+  context: {cluster: prod, user: user}
+- name: staging
+  context: {cluster: staging, user: user}
+users:
+- name: user
+  user: {token: token}
+current-context: prod
+`, prod.URL, staging.URL)), 0o600))
+
+	c := &configurator.KubernetesConfigurator{
+		Kubeconfig: kubeconfig,
+		Contexts: map[string]string{
+			"k8s-staging": "staging",
+		},
+	}
+	require.True(t, c.CredentialsDetected())
+
+	opt, err := c.SpelunkerOption(t.Context())
+	require.NoError(t, err)
+	digUpAll(t, opt, map[string]string{
+		"k8s://my-secret/password":         "prod",
+		"k8s-staging://my-secret/password": "staging",
+	})
+}
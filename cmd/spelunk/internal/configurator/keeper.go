@@ -57,6 +57,10 @@ func (c *KeeperConfigurator) SpelunkerOption(ctx context.Context) (spelunk.Spelu
 	return spelunkkeeper.WithKeeper(client), nil
 }
 
+func (c *KeeperConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	return spelunkkeeper.WithKeeper(nil)
+}
+
 func (c *KeeperConfigurator) CredentialsValid(_ context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
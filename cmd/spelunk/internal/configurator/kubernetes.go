@@ -2,9 +2,12 @@ package configurator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 
 	"github.com/detro/spelunk/cmd/spelunk/internal"
 	"github.com/detro/spelunk/cmd/spelunk/internal/logger"
@@ -16,7 +19,8 @@ import (
 )
 
 type KubernetesConfigurator struct {
-	Kubeconfig string `name:"kubeconfig" env:"KUBECONFIG" help:"Path to Kubeconfig file."`
+	Kubeconfig string            `name:"kubeconfig"   env:"KUBECONFIG" help:"Path to Kubeconfig file."`
+	Contexts   map[string]string `name:"kube-context"                  help:"Named Kubernetes instance, as SCHEME=CONTEXT (repeatable): coordinates with that scheme (e.g. k8s-prod://...) dig-up from that context of the Kubeconfig." placeholder:"SCHEME=CONTEXT"`
 }
 
 var _ internal.SecretSourceConfigurator = (*KubernetesConfigurator)(nil)
@@ -47,7 +51,24 @@ func (c *KubernetesConfigurator) loadConfig() (*rest.Config, error) {
 	return nil, fmt.Errorf("no kubernetes configuration found")
 }
 
+// loadContextConfig loads the configuration of the given context of the Kubeconfig.
+func (c *KubernetesConfigurator) loadContextConfig(kubeContext string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if c.Kubeconfig != "" {
+		rules.ExplicitPath = c.Kubeconfig
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
+}
+
 func (c *KubernetesConfigurator) CredentialsDetected() bool {
+	return c.defaultDetected() || len(c.Contexts) > 0
+}
+
+// defaultDetected returns true if the configuration of the default instance (i.e. `k8s://`) is detected.
+func (c *KubernetesConfigurator) defaultDetected() bool {
 	if c.Kubeconfig != "" || os.Getenv("KUBECONFIG") != "" {
 		return true
 	}
@@ -57,8 +78,18 @@ func (c *KubernetesConfigurator) CredentialsDetected() bool {
 	return false
 }
 
-func (c *KubernetesConfigurator) newClient() (*kubernetes.Clientset, error) {
-	restConfig, err := c.loadConfig()
+// newClient creates a client for the given context of the Kubeconfig,
+// or for the default instance if kubeContext is empty.
+func (c *KubernetesConfigurator) newClient(kubeContext string) (*kubernetes.Clientset, error) {
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+	if kubeContext == "" {
+		restConfig, err = c.loadConfig()
+	} else {
+		restConfig, err = c.loadContextConfig(kubeContext)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -74,20 +105,54 @@ func (c *KubernetesConfigurator) SpelunkerOption(
 	}
 	slog.Log(ctx, logger.LevelTrace, "detected credentials", "plugin", c.Type())
 
-	clientset, err := c.newClient()
-	if err != nil {
-		return nil, err
+	var opts []spelunk.SpelunkerOption
+	if c.defaultDetected() {
+		clientset, err := c.newClient("")
+		if err != nil {
+			return nil, err
+		}
+		slog.Log(ctx, logger.LevelTrace, "configured client", "plugin", c.Type())
+		opts = append(opts, spelunkk8s.WithKubernetes(clientset.CoreV1()))
+	}
+	for _, scheme := range slices.Sorted(maps.Keys(c.Contexts)) {
+		clientset, err := c.newClient(c.Contexts[scheme])
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %w", scheme, err)
+		}
+		slog.Log(ctx, logger.LevelTrace, "configured client", "plugin", c.Type(), "scheme", scheme)
+		opts = append(opts, spelunk.WithSourceAs(scheme, spelunkk8s.New(clientset.CoreV1())))
 	}
-	slog.Log(ctx, logger.LevelTrace, "configured client", "plugin", c.Type())
 
-	return spelunkk8s.WithKubernetes(clientset.CoreV1()), nil
+	return spelunk.WithOptions(opts...), nil
+}
+
+func (c *KubernetesConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	opts := []spelunk.SpelunkerOption{spelunk.WithSource(spelunkk8s.New(nil))}
+	for scheme := range c.Contexts {
+		opts = append(opts, spelunk.WithSourceAs(scheme, spelunkk8s.New(nil)))
+	}
+	return spelunk.WithOptions(opts...)
 }
 
 func (c *KubernetesConfigurator) CredentialsValid(_ context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
 	}
-	clientset, err := c.newClient()
+
+	var errs []error
+	if c.defaultDetected() {
+		errs = append(errs, c.clientValid(""))
+	}
+	for _, scheme := range slices.Sorted(maps.Keys(c.Contexts)) {
+		if err := c.clientValid(c.Contexts[scheme]); err != nil {
+			errs = append(errs, fmt.Errorf("instance %q: %w", scheme, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *KubernetesConfigurator) clientValid(kubeContext string) error {
+	clientset, err := c.newClient(kubeContext)
 	if err != nil {
 		return err
 	}
@@ -2,9 +2,12 @@ package configurator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 
 	"github.com/detro/spelunk/cmd/spelunk/internal"
 	"github.com/detro/spelunk/cmd/spelunk/internal/logger"
@@ -14,9 +17,10 @@ import (
 )
 
 type VaultConfigurator struct {
-	Addr      string `name:"vault-addr"      env:"VAULT_ADDR"      help:"Vault Server Address (e.g. https://vault.example.com:8200)."`
-	Token     string `name:"vault-token"     env:"VAULT_TOKEN"     help:"Vault Authentication Token."`
-	Namespace string `name:"vault-namespace" env:"VAULT_NAMESPACE" help:"Vault Namespace."`
+	Addr      string            `name:"vault-addr"      env:"VAULT_ADDR"      help:"Vault Server Address (e.g. https://vault.example.com:8200)."`
+	Token     string            `name:"vault-token"     env:"VAULT_TOKEN"     help:"Vault Authentication Token."`
+	Namespace string            `name:"vault-namespace" env:"VAULT_NAMESPACE" help:"Vault Namespace."`
+	Instances map[string]string `name:"vault-instance"                        help:"Named Vault instance, as SCHEME=ADDR (repeatable): coordinates with that scheme (e.g. vault-eu://...) dig-up from it. Its token is read from <SCHEME>_TOKEN (e.g. VAULT_EU_TOKEN), defaulting to --vault-token." placeholder:"SCHEME=ADDR"`
 }
 
 var _ internal.SecretSourceConfigurator = (*VaultConfigurator)(nil)
@@ -26,21 +30,34 @@ func (c *VaultConfigurator) Type() string {
 }
 
 func (c *VaultConfigurator) CredentialsDetected() bool {
+	return c.defaultDetected() || len(c.Instances) > 0
+}
+
+// defaultDetected returns true if credentials for the default instance (i.e. `vault://`) are detected.
+func (c *VaultConfigurator) defaultDetected() bool {
 	return c.Addr != "" || c.Token != "" || os.Getenv("VAULT_ADDR") != "" ||
 		os.Getenv("VAULT_TOKEN") != ""
 }
 
-func (c *VaultConfigurator) newClient() (*api.Client, error) {
+// instanceToken returns the token of the named instance with the given scheme.
+func (c *VaultConfigurator) instanceToken(scheme string) string {
+	if token := os.Getenv(instanceEnvPrefix(scheme) + "_TOKEN"); token != "" {
+		return token
+	}
+	return c.Token
+}
+
+func (c *VaultConfigurator) newClient(addr, token string) (*api.Client, error) {
 	cfg := api.DefaultConfig()
-	if c.Addr != "" {
-		cfg.Address = c.Addr
+	if addr != "" {
+		cfg.Address = addr
 	}
 	client, err := api.NewClient(cfg)
 	if err != nil {
 		return nil, err
 	}
-	if c.Token != "" {
-		client.SetToken(c.Token)
+	if token != "" {
+		client.SetToken(token)
 	}
 	if c.Namespace != "" {
 		client.SetNamespace(c.Namespace)
@@ -55,20 +72,54 @@ func (c *VaultConfigurator) SpelunkerOption(ctx context.Context) (spelunk.Spelun
 	}
 	slog.Log(ctx, logger.LevelTrace, "detected credentials", "plugin", c.Type())
 
-	client, err := c.newClient()
-	if err != nil {
-		return nil, err
+	var opts []spelunk.SpelunkerOption
+	if c.defaultDetected() {
+		client, err := c.newClient(c.Addr, c.Token)
+		if err != nil {
+			return nil, err
+		}
+		slog.Log(ctx, logger.LevelTrace, "configured client", "plugin", c.Type())
+		opts = append(opts, spelunkvault.WithVault(client))
+	}
+	for _, scheme := range slices.Sorted(maps.Keys(c.Instances)) {
+		client, err := c.newClient(c.Instances[scheme], c.instanceToken(scheme))
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %w", scheme, err)
+		}
+		slog.Log(ctx, logger.LevelTrace, "configured client", "plugin", c.Type(), "scheme", scheme)
+		opts = append(opts, spelunk.WithSourceAs(scheme, spelunkvault.New(client)))
 	}
-	slog.Log(ctx, logger.LevelTrace, "configured client", "plugin", c.Type())
 
-	return spelunkvault.WithVault(client), nil
+	return spelunk.WithOptions(opts...), nil
+}
+
+func (c *VaultConfigurator) OfflineSpelunkerOption() spelunk.SpelunkerOption {
+	opts := []spelunk.SpelunkerOption{spelunk.WithSource(spelunkvault.New(nil))}
+	for scheme := range c.Instances {
+		opts = append(opts, spelunk.WithSourceAs(scheme, spelunkvault.New(nil)))
+	}
+	return spelunk.WithOptions(opts...)
 }
 
 func (c *VaultConfigurator) CredentialsValid(ctx context.Context) error {
 	if !c.CredentialsDetected() {
 		return fmt.Errorf("%w for plugin %s", ErrCredentialsNotDetected, c.Type())
 	}
-	client, err := c.newClient()
+
+	var errs []error
+	if c.defaultDetected() {
+		errs = append(errs, c.clientValid(ctx, c.Addr, c.Token))
+	}
+	for _, scheme := range slices.Sorted(maps.Keys(c.Instances)) {
+		if err := c.clientValid(ctx, c.Instances[scheme], c.instanceToken(scheme)); err != nil {
+			errs = append(errs, fmt.Errorf("instance %q: %w", scheme, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *VaultConfigurator) clientValid(ctx context.Context, addr, token string) error {
+	client, err := c.newClient(addr, token)
 	if err != nil {
 		return err
 	}
@@ -103,6 +103,21 @@ func TestPlugin_Vault_E2E(t *testing.T) {
 		require.Equal(t, "one", res.Stdout)
 	})
 
+	t.Run("dig v2 secret key with metadata", func(t *testing.T) {
+		res := runCLI(
+			ctx,
+			bin,
+			env,
+			"dig",
+			"--meta",
+			fmt.Sprintf("vault://%s/string_value", vaultV2SecPath),
+		)
+		require.Equal(t, 0, res.ExitCode, res.Stderr)
+		require.Equal(t, "one", res.Stdout)
+		require.Contains(t, res.Stderr, `"version":"1"`)
+		require.Contains(t, res.Stderr, `"created_at":`)
+	})
+
 	t.Run("default dig whole v1 secret as json with jsonpath", func(t *testing.T) {
 		res := runCLI(ctx, bin, env, fmt.Sprintf("vault://%s/?jp=$.intValue", vaultV1SecPath))
 		require.Equal(t, 0, res.ExitCode, res.Stderr)
@@ -120,6 +135,11 @@ func TestPlugin_Vault_E2E(t *testing.T) {
 		require.Equal(t, 0, res.ExitCode, res.Stderr)
 	})
 
+	t.Run("exist v2 secret found via metadata", func(t *testing.T) {
+		res := runCLI(ctx, bin, env, "exists", fmt.Sprintf("vault://%s/", vaultV2SecPath))
+		require.Equal(t, 0, res.ExitCode, res.Stderr)
+	})
+
 	t.Run("exist secret missing", func(t *testing.T) {
 		res := runCLI(ctx, bin, env, "exists", "vault://missing/secret/key")
 		require.NotEqual(t, 0, res.ExitCode)
@@ -7,5 +7,5 @@ import (
 func main() {
 	kongContext := cli.Parse()
 
-	kongContext.FatalIfErrorf(kongContext.Run())
+	kongContext.FatalIfErrorf(cli.WithExitCode(kongContext.Run()))
 }
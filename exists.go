@@ -0,0 +1,103 @@
+package spelunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// Exists checks if the secret pointed at by the given *SecretCoord exists, without modifying it.
+//
+// Sources implementing types.ExistenceChecker are asked via metadata-only calls: the value of
+// the secret is never fetched. For all others, the secret is dug-up from the source and discarded.
+//
+// Coordinates with modifiers point inside the secret, where no metadata can look: they are
+// fully dug-up instead, modifiers included, and a modifier that can't be applied (e.g. a JSONPath
+// matching nothing) reports the secret as not existing. ModifierDefault and ModifierOptional
+// are ignored either way.
+//
+// It returns false, without error, if the secret (or the key inside it) is not found:
+// any other failure is returned as a *types.DigUpError.
+func (s *Spelunker) Exists(ctx context.Context, coord *types.SecretCoord) (bool, error) {
+	start := time.Now()
+	exists, err := s.exists(ctx, coord)
+	if err := s.audited(ctx, AuditOperationExists, coord, start, err); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (s *Spelunker) exists(ctx context.Context, coord *types.SecretCoord) (bool, error) {
+	if coord == nil {
+		return false, ErrNilSecretCoord
+	}
+
+	// Identify the source of the secret
+	source, found := s.opts.sources[coord.Type]
+	if !found {
+		return false, types.NewDigUpError(
+			types.ErrorStageSource,
+			coord.Type,
+			types.RedactedLocation,
+			fmt.Errorf("%w: %q", ErrUnsupportedSecretSourceType, coord.Type),
+		)
+	}
+	newDigUpError := func(err error) error {
+		return types.NewDigUpError(types.ErrorStageSource, coord.Type, types.RedactLocation(source, *coord), err)
+	}
+
+	// Enforce the policy, if any
+	if err := s.opts.policy.check(source, coord); err != nil {
+		return false, newDigUpError(err)
+	}
+	if err := checkParams(source, coord); err != nil {
+		return false, newDigUpError(err)
+	}
+
+	// Modifiers can only be checked by applying them
+	if slices.ContainsFunc(coord.Modifiers, func(mod [2]string) bool { return !types.IsReservedModifier(mod[0]) }) {
+		return s.existsModified(ctx, coord)
+	}
+
+	// Ask the source, if it can check without digging up
+	if checker, ok := source.(types.ExistenceChecker); ok {
+		exists, err := checker.Exists(ctx, *coord)
+		if err != nil {
+			return false, newDigUpError(fmt.Errorf("%w: %w", ErrFailedToDigUpSecret, err))
+		}
+		return exists, nil
+	}
+
+	// Otherwise, dig-up the secret from the source (going through the cache, if enabled)
+	if _, err := s.digUpSource(ctx, source, *coord); err != nil {
+		if errors.Is(err, types.ErrSecretNotFound) || errors.Is(err, types.ErrSecretKeyNotFound) {
+			return false, nil
+		}
+		return false, newDigUpError(fmt.Errorf("%w: %w", ErrFailedToDigUpSecret, err))
+	}
+	return true, nil
+}
+
+// existsModified checks if the secret exists by digging it up, modifiers included.
+func (s *Spelunker) existsModified(ctx context.Context, coord *types.SecretCoord) (bool, error) {
+	// Without the reserved modifiers, a missing secret can't fall back to a value
+	modified := *coord
+	modified.Modifiers = slices.DeleteFunc(slices.Clone(coord.Modifiers), func(mod [2]string) bool {
+		return types.IsReservedModifier(mod[0])
+	})
+
+	// Going through the cache, if enabled, but not through the chain of DigUpMiddleware
+	if _, err := s.digUpChained(ctx, &modified); err != nil {
+		if errors.Is(err, types.ErrSecretNotFound) ||
+			errors.Is(err, types.ErrSecretKeyNotFound) ||
+			errors.Is(err, ErrFailedToApplyModifier) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
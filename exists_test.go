@@ -0,0 +1,162 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// existenceChecker implements types.ExistenceChecker for testing,
+// reporting as existing only the locations it knows.
+type existenceChecker struct {
+	*util.MockSource
+	existing map[string]bool
+	err      error
+}
+
+func (c *existenceChecker) Exists(_ context.Context, coord types.SecretCoord) (bool, error) {
+	return c.existing[coord.Location], c.err
+}
+
+func TestSpelunker_Exists(t *testing.T) {
+	ctx := context.Background()
+
+	missingSrc := util.NewMockSource("missing")
+	missingSrc.Err = fmt.Errorf("%w (%q)", types.ErrSecretKeyNotFound, "loc")
+	failingSrc := util.NewMockSource("failing")
+	failingSrc.Err = fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrTransient)
+
+	checker := &existenceChecker{
+		MockSource: util.NewMockSource("checker"),
+		existing:   map[string]bool{"present": true},
+	}
+	failingChecker := &existenceChecker{
+		MockSource: util.NewMockSource("failingchecker"),
+		err:        fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrPermissionDenied),
+	}
+
+	failingMod := util.NewMockModifier("failmod")
+	failingMod.Err = errors.New("path matches nothing")
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithModifier(&mockModifier{typ: "mod"}),
+		spelunk.WithModifier(failingMod),
+		spelunk.WithSource(newValueSource("mock", "val")),
+		spelunk.WithSource(missingSrc),
+		spelunk.WithSource(failingSrc),
+		spelunk.WithSource(checker),
+		spelunk.WithSource(failingChecker),
+		spelunk.WithPolicy(spelunk.WithPolicyDenyLocations("mock://denied/**")),
+	)
+
+	tests := []struct {
+		name      string
+		coordStr  string
+		want      bool
+		errMatch  error
+		wantClass types.ErrorClass
+	}{
+		{
+			name:     "fallback to dig-up, found",
+			coordStr: "mock://loc",
+			want:     true,
+		},
+		{
+			name:     "fallback to dig-up, default ignored",
+			coordStr: "missing://loc?default=val",
+			want:     false,
+		},
+		{
+			name:     "fallback to dig-up, not found",
+			coordStr: "missing://loc",
+			want:     false,
+		},
+		{
+			name:      "fallback to dig-up, failure",
+			coordStr:  "failing://loc",
+			errMatch:  types.ErrTransient,
+			wantClass: types.ErrorClassTransient,
+		},
+		{
+			name:     "existence checker, found",
+			coordStr: "checker://present",
+			want:     true,
+		},
+		{
+			name:     "existence checker, not found",
+			coordStr: "checker://absent",
+			want:     false,
+		},
+		{
+			name:      "existence checker, failure",
+			coordStr:  "failingchecker://loc",
+			errMatch:  types.ErrPermissionDenied,
+			wantClass: types.ErrorClassPermissionDenied,
+		},
+		{
+			name:     "modifiers, found",
+			coordStr: "mock://loc?mod=x",
+			want:     true,
+		},
+		{
+			name:     "modifiers, secret not found",
+			coordStr: "missing://loc?mod=x&default=val",
+			want:     false,
+		},
+		{
+			name:     "modifiers, modifier not applicable",
+			coordStr: "mock://loc?failmod=$.missing",
+			want:     false,
+		},
+		{
+			name:      "modifiers, unsupported modifier",
+			coordStr:  "mock://loc?unknown",
+			errMatch:  spelunk.ErrUnsupportedSecretModifierType,
+			wantClass: types.ErrorClassUnknown,
+		},
+		{
+			name:      "unsupported source type",
+			coordStr:  "unknown://loc",
+			errMatch:  spelunk.ErrUnsupportedSecretSourceType,
+			wantClass: types.ErrorClassUnknown,
+		},
+		{
+			name:      "policy violation",
+			coordStr:  "mock://denied/secret",
+			errMatch:  spelunk.ErrPolicyViolation,
+			wantClass: types.ErrorClassPermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			exists, err := spelunker.Exists(ctx, coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				require.False(t, exists)
+
+				digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+				require.True(t, errMatched)
+				require.Equal(t, tt.wantClass, digUpErr.Class)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, exists)
+		})
+	}
+
+	// Existence checkers are never asked to dig up
+	require.Zero(t, checker.Calls())
+
+	_, err := spelunker.Exists(ctx, nil)
+	require.ErrorIs(t, err, spelunk.ErrNilSecretCoord)
+}
@@ -0,0 +1,223 @@
+package spelunk
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+const (
+	// DefaultExpandOpenDelimiter opens a reference to coordinates embedded in text.
+	DefaultExpandOpenDelimiter = "${spelunk:"
+	// DefaultExpandCloseDelimiter closes a reference to coordinates embedded in text.
+	DefaultExpandCloseDelimiter = "}"
+	// ExpandEscape when placed right before the opening delimiter, makes it literal.
+	ExpandEscape = `\`
+)
+
+var (
+	ErrFailedToExpand              = fmt.Errorf("failed to expand coordinates references")
+	ErrExpandUnterminatedReference = fmt.Errorf("unterminated coordinates reference")
+)
+
+// ExpandError reports the position in the text of a reference that failed to expand.
+// Line and Column are 1-based; Column is measured in runes.
+type ExpandError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ExpandError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ExpandError) Unwrap() error {
+	return e.Err
+}
+
+// ExpandOption options that can be provided to Spelunker.Expand and Spelunker.ExpandReader.
+type ExpandOption func(*expandOptions)
+
+type expandOptions struct {
+	open  string
+	close string
+}
+
+// WithExpandDelimiters sets the delimiters enclosing references to coordinates.
+// If either is empty, the option is ignored: references could not be delimited.
+// Default is `${spelunk:` and `}`.
+func WithExpandDelimiters(open, close string) ExpandOption {
+	return func(o *expandOptions) {
+		if open == "" || close == "" {
+			return
+		}
+		o.open = open
+		o.close = close
+	}
+}
+
+// Expand finds all the references to coordinates embedded in text, and replaces
+// each with the secret dug-up at those coordinates. For example:
+//
+//	postgres://app:${spelunk:vault://kv/data/db/password}@db:5432
+//
+// A reference can be made literal by prefixing it with ExpandEscape (i.e. `\${spelunk:...}`
+// becomes `${spelunk:...}`). References can't span multiple lines.
+//
+// All failures are reported together, each as an *ExpandError carrying line and column.
+func (s *Spelunker) Expand(ctx context.Context, text string, opts ...ExpandOption) (string, error) {
+	e := s.newExpander(opts...)
+
+	var (
+		res  strings.Builder
+		errs []error
+	)
+	for idx, line := range strings.SplitAfter(text, "\n") {
+		expanded, lineErrs := e.expandLine(ctx, line, idx+1)
+		res.WriteString(expanded)
+		errs = append(errs, lineErrs...)
+	}
+	if len(errs) > 0 {
+		return "", fmt.Errorf("%w: %w", ErrFailedToExpand, errors.Join(errs...))
+	}
+	return res.String(), nil
+}
+
+// ExpandReader works like Expand, but on a stream: it returns an io.Reader that reads
+// from r and expands references to coordinates, one line at a time.
+//
+// Reading stops at the first failure, returning an error wrapping an *ExpandError.
+func (s *Spelunker) ExpandReader(
+	ctx context.Context,
+	r io.Reader,
+	opts ...ExpandOption,
+) io.Reader {
+	return &expandReader{
+		ctx:      ctx,
+		expander: s.newExpander(opts...),
+		src:      bufio.NewReader(r),
+	}
+}
+
+// expander replaces references to coordinates with the secrets dug-up by a Spelunker.
+type expander struct {
+	spelunker *Spelunker
+	opts      expandOptions
+}
+
+func (s *Spelunker) newExpander(opts ...ExpandOption) *expander {
+	e := &expander{
+		spelunker: s,
+		opts: expandOptions{
+			open:  DefaultExpandOpenDelimiter,
+			close: DefaultExpandCloseDelimiter,
+		},
+	}
+	for _, opt := range opts {
+		opt(&e.opts)
+	}
+	return e
+}
+
+// expandLine expands all references in line, returning the expanded line and
+// an *ExpandError for each reference that failed to expand.
+func (e *expander) expandLine(ctx context.Context, line string, lineNum int) (string, []error) {
+	var (
+		res  strings.Builder
+		errs []error
+	)
+
+	open, closing := e.opts.open, e.opts.close
+	pos := 0
+	for {
+		idx := strings.Index(line[pos:], open)
+		if idx < 0 {
+			res.WriteString(line[pos:])
+			break
+		}
+		start := pos + idx
+
+		// Escaped opening delimiter: write it literally
+		if strings.HasSuffix(line[pos:start], ExpandEscape) {
+			res.WriteString(line[pos : start-len(ExpandEscape)])
+			res.WriteString(open)
+			pos = start + len(open)
+			continue
+		}
+		res.WriteString(line[pos:start])
+
+		newExpandError := func(err error) error {
+			return &ExpandError{
+				Line:   lineNum,
+				Column: utf8.RuneCountInString(line[:start]) + 1,
+				Err:    err,
+			}
+		}
+
+		refStart := start + len(open)
+		refLen := strings.Index(line[refStart:], closing)
+		if refLen < 0 {
+			errs = append(errs, newExpandError(ErrExpandUnterminatedReference))
+			res.WriteString(line[start:])
+			break
+		}
+		pos = refStart + refLen + len(closing)
+
+		coord, err := types.NewSecretCoord(line[refStart : refStart+refLen])
+		if err != nil {
+			errs = append(errs, newExpandError(err))
+			continue
+		}
+		val, err := e.spelunker.DigUp(ctx, coord)
+		if err != nil {
+			errs = append(errs, newExpandError(err))
+			continue
+		}
+		res.WriteString(val)
+	}
+
+	return res.String(), errs
+}
+
+// expandReader is the io.Reader returned by Spelunker.ExpandReader.
+type expandReader struct {
+	ctx      context.Context
+	expander *expander
+	src      *bufio.Reader
+	lineNum  int
+	buf      []byte
+	err      error
+}
+
+func (r *expandReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		line, err := r.src.ReadString('\n')
+		if len(line) > 0 {
+			r.lineNum++
+			expanded, errs := r.expander.expandLine(r.ctx, line, r.lineNum)
+			if len(errs) > 0 {
+				r.err = fmt.Errorf("%w: %w", ErrFailedToExpand, errs[0])
+				return 0, r.err
+			}
+			r.buf = []byte(expanded)
+		}
+		if err != nil {
+			r.err = err
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
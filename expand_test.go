@@ -0,0 +1,130 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpelunker_Expand(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []spelunk.ExpandOption
+		input    string
+		want     string
+		errMatch error
+		errPos   [2]int
+	}{
+		{
+			name:  "no references",
+			input: "just some text",
+			want:  "just some text",
+		},
+		{
+			name:  "single reference",
+			input: "postgres://app:${spelunk:plain://s3cr3t}@db:5432",
+			want:  "postgres://app:s3cr3t@db:5432",
+		},
+		{
+			name:  "multiple references on multiple lines",
+			input: "user=${spelunk:plain://app}\npass=${spelunk:base64://czNjcjN0}\n",
+			want:  "user=app\npass=s3cr3t\n",
+		},
+		{
+			name:  "reference with modifiers",
+			input: "token=${spelunk:plain://s3cr3t?b64}",
+			want:  "token=czNjcjN0",
+		},
+		{
+			name:  "escaped reference",
+			input: `literal=\${spelunk:plain://s3cr3t} value=${spelunk:plain://s3cr3t}`,
+			want:  "literal=${spelunk:plain://s3cr3t} value=s3cr3t",
+		},
+		{
+			name:  "custom delimiters",
+			opts:  []spelunk.ExpandOption{spelunk.WithExpandDelimiters("{{", "}}")},
+			input: "pass={{plain://s3cr3t}} other=${spelunk:plain://s3cr3t}",
+			want:  "pass=s3cr3t other=${spelunk:plain://s3cr3t}",
+		},
+		{
+			name:  "empty delimiters are ignored",
+			opts:  []spelunk.ExpandOption{spelunk.WithExpandDelimiters("", "}}"), spelunk.WithExpandDelimiters("{{", "")},
+			input: "pass={{plain://s3cr3t}} other=${spelunk:plain://s3cr3t}",
+			want:  "pass={{plain://s3cr3t}} other=s3cr3t",
+		},
+		{
+			name:     "unterminated reference",
+			input:    "line one\nvalue=${spelunk:plain://s3cr3t",
+			errMatch: spelunk.ErrExpandUnterminatedReference,
+			errPos:   [2]int{2, 7},
+		},
+		{
+			name:     "invalid coordinates",
+			input:    "ünïcode=${spelunk:no-scheme}",
+			errMatch: types.ErrSecretCoordHaveNoType,
+			errPos:   [2]int{1, 9},
+		},
+		{
+			name:     "failed dig-up",
+			input:    "\n\n  ${spelunk:unknown://loc}",
+			errMatch: spelunk.ErrUnsupportedSecretSourceType,
+			errPos:   [2]int{3, 3},
+		},
+	}
+
+	spelunker := spelunk.NewSpelunker()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := spelunker.Expand(context.Background(), tt.input, tt.opts...)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, spelunk.ErrFailedToExpand)
+				require.ErrorIs(t, err, tt.errMatch)
+
+				var expandErr *spelunk.ExpandError
+				require.True(t, errors.As(err, &expandErr))
+				require.Equal(t, tt.errPos, [2]int{expandErr.Line, expandErr.Column})
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+
+			// The streaming version produces the same result
+			gotBytes, err := io.ReadAll(
+				spelunker.ExpandReader(context.Background(), strings.NewReader(tt.input), tt.opts...),
+			)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(gotBytes))
+		})
+	}
+}
+
+func TestSpelunker_Expand_ReportsAllErrors(t *testing.T) {
+	_, err := spelunk.NewSpelunker().Expand(
+		context.Background(),
+		"${spelunk:unknown://a}\n${spelunk:env://SPELUNK_EXPAND_TEST_NON_EXISTENT_VAR}",
+	)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+	require.ErrorContains(t, err, "line 1, column 1")
+	require.ErrorContains(t, err, "line 2, column 1")
+}
+
+func TestSpelunker_ExpandReader_StopsAtFirstError(t *testing.T) {
+	r := spelunk.NewSpelunker().ExpandReader(
+		context.Background(),
+		strings.NewReader("ok=${spelunk:plain://a}\nko=${spelunk:unknown://b}\nnever=reached\n"),
+	)
+
+	got, err := io.ReadAll(r)
+	require.ErrorIs(t, err, spelunk.ErrFailedToExpand)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	require.ErrorContains(t, err, "line 2, column 4")
+	require.Equal(t, "ok=a\n", string(got))
+}
@@ -0,0 +1,53 @@
+package spelunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+var ErrNoAlternativeResolved = fmt.Errorf("none of the alternative coordinates resolved")
+
+// FirstOf digs up the secret at the first of the given *SecretCoord that resolves,
+// trying them in order. It returns the secret and the index of the alternative that succeeded.
+//
+// It falls through to the next alternative only if the secret was not found
+// (types.ErrSecretNotFound or types.ErrSecretKeyNotFound), or if the source type is not
+// enabled in this Spelunker (ErrUnsupportedSecretSourceType). Any other error (e.g. authentication)
+// stops the search and is returned as is, alongside the index of the failing alternative.
+//
+// If no alternative resolves, it returns an error wrapping ErrNoAlternativeResolved
+// and the errors of all alternatives, alongside an index of -1.
+func (s *Spelunker) FirstOf(
+	ctx context.Context,
+	coords ...*types.SecretCoord,
+) (string, int, error) {
+	errs := make([]error, 0, len(coords))
+	for idx, coord := range coords {
+		if coord == nil {
+			errs = append(errs, fmt.Errorf("alternative %d: %w", idx, ErrNilSecretCoord))
+			continue
+		}
+
+		val, err := s.DigUp(ctx, coord)
+		if err == nil {
+			return val, idx, nil
+		}
+		if !isFallThroughError(err) {
+			return "", idx, fmt.Errorf("alternative %d: %w", idx, err)
+		}
+		errs = append(errs, fmt.Errorf("alternative %d: %w", idx, err))
+	}
+
+	return "", -1, fmt.Errorf("%w: %w", ErrNoAlternativeResolved, errors.Join(errs...))
+}
+
+// isFallThroughError returns true if err means the secret is not there (or can't be there),
+// so that it's safe to try an alternative.
+func isFallThroughError(err error) bool {
+	return errors.Is(err, types.ErrSecretNotFound) ||
+		errors.Is(err, types.ErrSecretKeyNotFound) ||
+		errors.Is(err, ErrUnsupportedSecretSourceType)
+}
@@ -0,0 +1,91 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpelunker_FirstOf(t *testing.T) {
+	ctx := context.Background()
+
+	notFoundSrc := util.NewMockSource("notfound")
+	notFoundSrc.Err = fmt.Errorf("%w: nope", types.ErrSecretNotFound)
+	keyNotFoundSrc := util.NewMockSource("nokey")
+	keyNotFoundSrc.Err = fmt.Errorf("%w: nope", types.ErrSecretKeyNotFound)
+	authSrc := util.NewMockSource("auth")
+	authSrc.Err = errors.New("permission denied")
+	okSrc := util.NewMockSource("ok")
+	okSrc.Val = "ok-value"
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(notFoundSrc),
+		spelunk.WithSource(keyNotFoundSrc),
+		spelunk.WithSource(authSrc),
+		spelunk.WithSource(okSrc),
+	)
+
+	tests := []struct {
+		name      string
+		coordStrs []string
+		want      string
+		wantIdx   int
+		errMatch  error
+	}{
+		{
+			name:      "first alternative resolves",
+			coordStrs: []string{"ok://loc", "notfound://loc"},
+			want:      "ok-value",
+			wantIdx:   0,
+		},
+		{
+			name:      "falls through not found, key not found and unsupported",
+			coordStrs: []string{"notfound://loc", "nokey://loc", "unknown://loc", "ok://loc"},
+			want:      "ok-value",
+			wantIdx:   3,
+		},
+		{
+			name:      "stops at other errors",
+			coordStrs: []string{"notfound://loc", "auth://loc", "ok://loc"},
+			wantIdx:   1,
+			errMatch:  spelunk.ErrFailedToDigUpSecret,
+		},
+		{
+			name:      "no alternative resolves",
+			coordStrs: []string{"notfound://loc", "nokey://loc"},
+			wantIdx:   -1,
+			errMatch:  spelunk.ErrNoAlternativeResolved,
+		},
+		{
+			name:     "no alternatives",
+			wantIdx:  -1,
+			errMatch: spelunk.ErrNoAlternativeResolved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coords := make([]*types.SecretCoord, 0, len(tt.coordStrs))
+			for _, coordStr := range tt.coordStrs {
+				coord, err := types.NewSecretCoord(coordStr)
+				require.NoError(t, err)
+				coords = append(coords, coord)
+			}
+
+			got, idx, err := spelunker.FirstOf(ctx, coords...)
+			require.Equal(t, tt.wantIdx, idx)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package spelunk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+var (
+	ErrListingNotSupported = fmt.Errorf("secret source does not support listing")
+	ErrFailedToListSecrets = fmt.Errorf("failed to list secrets")
+)
+
+// List returns the coordinates available under the given *SecretCoord prefix, sorted by location
+// (e.g. `vault://kv/data/team-a/` lists the secrets and folders in that Vault folder).
+// The source must implement types.SecretLister: each returned coordinate is either a secret that
+// can be dug-up, or a further prefix that can be listed in turn. No secret value is dug-up.
+//
+// Coordinates rejected by the policy (see WithPolicy) are left out.
+// Failures are returned as a *types.DigUpError.
+func (s *Spelunker) List(ctx context.Context, prefix *types.SecretCoord) ([]types.SecretCoord, error) {
+	start := time.Now()
+	children, err := s.list(ctx, prefix)
+	if err := s.audited(ctx, AuditOperationList, prefix, start, err); err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+func (s *Spelunker) list(ctx context.Context, prefix *types.SecretCoord) ([]types.SecretCoord, error) {
+	if prefix == nil {
+		return nil, ErrNilSecretCoord
+	}
+
+	// Identify the source of the secrets
+	source, found := s.opts.sources[prefix.Type]
+	if !found {
+		return nil, types.NewDigUpError(
+			types.ErrorStageSource,
+			prefix.Type,
+			types.RedactedLocation,
+			fmt.Errorf("%w: %q", ErrUnsupportedSecretSourceType, prefix.Type),
+		)
+	}
+	newDigUpError := func(err error) error {
+		return types.NewDigUpError(types.ErrorStageSource, prefix.Type, types.RedactLocation(source, *prefix), err)
+	}
+
+	lister, ok := source.(types.SecretLister)
+	if !ok {
+		return nil, newDigUpError(fmt.Errorf("%w: %q", ErrListingNotSupported, prefix.Type))
+	}
+	children, err := lister.List(ctx, *prefix)
+	if err != nil {
+		return nil, newDigUpError(fmt.Errorf("%w: %w", ErrFailedToListSecrets, err))
+	}
+
+	// Leave out what the policy rejects, as it couldn't be dug-up anyway
+	children = slices.DeleteFunc(children, func(child types.SecretCoord) bool {
+		return s.opts.policy.check(source, &child) != nil
+	})
+	slices.SortFunc(children, func(a, b types.SecretCoord) int {
+		return strings.Compare(a.Location, b.Location)
+	})
+	return children, nil
+}
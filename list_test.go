@@ -0,0 +1,112 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// secretLister implements types.SecretLister for testing,
+// listing the given children of every prefix.
+type secretLister struct {
+	*util.MockSource
+	children []string
+	err      error
+}
+
+func (l *secretLister) List(_ context.Context, prefix types.SecretCoord) ([]types.SecretCoord, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	coords := make([]types.SecretCoord, 0, len(l.children))
+	for _, child := range l.children {
+		coords = append(coords, types.SecretCoord{Type: prefix.Type, Location: prefix.Location + child})
+	}
+	return coords, nil
+}
+
+func TestSpelunker_List(t *testing.T) {
+	lister := &secretLister{
+		MockSource: util.NewMockSource("lister"),
+		children:   []string{"b", "denied/secret", "a/"},
+	}
+	failingLister := &secretLister{
+		MockSource: util.NewMockSource("failing"),
+		err:        fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrUnauthenticated),
+	}
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(lister),
+		spelunk.WithSource(failingLister),
+		spelunk.WithSource(newValueSource("mock", "val")),
+		spelunk.WithPolicy(spelunk.WithPolicyDenyLocations("lister://app/denied/**")),
+	)
+
+	tests := []struct {
+		name      string
+		coordStr  string
+		want      []string
+		errMatch  error
+		wantClass types.ErrorClass
+	}{
+		{
+			name:     "sorted, without coordinates rejected by the policy",
+			coordStr: "lister://app/",
+			want:     []string{"lister://app/a/", "lister://app/b"},
+		},
+		{
+			name:      "listing failure",
+			coordStr:  "failing://app/",
+			errMatch:  spelunk.ErrFailedToListSecrets,
+			wantClass: types.ErrorClassUnauthenticated,
+		},
+		{
+			name:      "listing not supported",
+			coordStr:  "mock://app/",
+			errMatch:  spelunk.ErrListingNotSupported,
+			wantClass: types.ErrorClassUnknown,
+		},
+		{
+			name:      "unsupported source type",
+			coordStr:  "unknown://app/",
+			errMatch:  spelunk.ErrUnsupportedSecretSourceType,
+			wantClass: types.ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.List(t.Context(), prefix)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+
+				digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+				require.True(t, errMatched)
+				require.Equal(t, tt.wantClass, digUpErr.Class)
+				return
+			}
+			require.NoError(t, err)
+
+			uris := make([]string, 0, len(got))
+			for _, coord := range got {
+				uris = append(uris, coord.URI())
+			}
+			require.Equal(t, tt.want, uris)
+		})
+	}
+
+	// Listing never digs up
+	require.Zero(t, lister.Calls())
+
+	_, err := spelunker.List(t.Context(), nil)
+	require.ErrorIs(t, err, spelunk.ErrNilSecretCoord)
+}
@@ -0,0 +1,38 @@
+package spelunk
+
+import (
+	"context"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// metadataKey is the context key of the *types.SecretMetadata requested via Spelunker.DigUpWithMetadata.
+type metadataKey struct{}
+
+func withMetadata(ctx context.Context, metadata *types.SecretMetadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// requestedMetadata returns the *types.SecretMetadata to fill, if ctx belongs to
+// a dig-up made via Spelunker.DigUpWithMetadata, or nil.
+func requestedMetadata(ctx context.Context) *types.SecretMetadata {
+	metadata, _ := ctx.Value(metadataKey{}).(*types.SecretMetadata)
+	return metadata
+}
+
+// DigUpWithMetadata digs up a secret using the given *SecretCoord, like DigUp,
+// and returns it alongside its *types.SecretMetadata (e.g. version, creation time).
+//
+// The metadata is provided by sources implementing types.MetadataSource: for all others,
+// it's empty. Values are never served from the cache, as it doesn't hold metadata.
+func (s *Spelunker) DigUpWithMetadata(
+	ctx context.Context,
+	coord *types.SecretCoord,
+) (string, *types.SecretMetadata, error) {
+	metadata := &types.SecretMetadata{}
+	val, err := s.digUp(withMetadata(ctx, metadata), coord)
+	if err != nil {
+		return "", nil, err
+	}
+	return val, metadata, nil
+}
@@ -0,0 +1,84 @@
+package spelunk_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+// metadataSource implements types.MetadataSource for testing.
+type metadataSource struct {
+	calls atomic.Int64
+}
+
+func (m *metadataSource) Type() string {
+	return "meta"
+}
+
+func (m *metadataSource) DigUp(_ context.Context, _ types.SecretCoord) (string, error) {
+	m.calls.Add(1)
+	return "value", nil
+}
+
+func (m *metadataSource) DigUpWithMetadata(
+	_ context.Context,
+	_ types.SecretCoord,
+) (string, *types.SecretMetadata, error) {
+	m.calls.Add(1)
+	metadata := &types.SecretMetadata{
+		Version:   "v7",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	metadata.SetExtra("stage", "current")
+	return "value", metadata, nil
+}
+
+func TestSpelunker_DigUpWithMetadata(t *testing.T) {
+	ctx := context.Background()
+
+	src := &metadataSource{}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithModifier(&mockModifier{typ: "mod"}),
+		spelunk.WithCache(),
+	)
+
+	coord, err := types.NewSecretCoord("meta://loc?mod=x")
+	require.NoError(t, err)
+
+	// Values are cached by DigUp...
+	val, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "value_x", val)
+
+	// ... but not served from the cache when metadata is requested
+	val, metadata, err := spelunker.DigUpWithMetadata(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "value_x", val)
+	require.Equal(t, &types.SecretMetadata{
+		Version:   "v7",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Extras:    map[string]string{"stage": "current"},
+	}, metadata)
+	require.Equal(t, int64(2), src.calls.Load())
+
+	// Sources not providing metadata return it empty
+	coord, err = types.NewSecretCoord("plain://value")
+	require.NoError(t, err)
+	val, metadata, err = spelunker.DigUpWithMetadata(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "value", val)
+	require.Equal(t, &types.SecretMetadata{}, metadata)
+
+	// Failures return no metadata
+	coord, err = types.NewSecretCoord("unknown://loc")
+	require.NoError(t, err)
+	_, metadata, err = spelunker.DigUpWithMetadata(ctx, coord)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	require.Nil(t, metadata)
+}
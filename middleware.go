@@ -0,0 +1,118 @@
+package spelunk
+
+import (
+	"context"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// DigUpHandler digs up the secret at coord, applying its modifiers.
+type DigUpHandler func(ctx context.Context, coord *types.SecretCoord) (string, error)
+
+// DigUpMiddleware wraps a DigUpHandler, to intercept every call to Spelunker.DigUp
+// (e.g. for tracing), including the ones made by Spelunker.DigUpAll, Spelunker.Resolve and others.
+//
+// Unlike SourceMiddleware and ModifierMiddleware, it sees the whole dig-up:
+// looking up the source, digging up the value and applying all the modifiers.
+type DigUpMiddleware func(next DigUpHandler) DigUpHandler
+
+// SourceHandler digs up the raw value of the secret at coord, from the given source.
+type SourceHandler func(
+	ctx context.Context,
+	source types.SecretSource,
+	coord types.SecretCoord,
+) (string, error)
+
+// SourceMiddleware wraps a SourceHandler, to intercept the calls a Spelunker
+// makes to types.SecretSource.DigUp (e.g. for logging, metrics, access checks).
+//
+// A middleware can act before and/or after calling next, or not call it at all
+// (e.g. to serve a cached value, or to deny access).
+type SourceMiddleware func(next SourceHandler) SourceHandler
+
+// ModifierHandler applies the given modifier (with argument arg) to value,
+// the secret dug-up at coord.
+type ModifierHandler func(
+	ctx context.Context,
+	modifier types.SecretModifier,
+	coord types.SecretCoord,
+	value string,
+	arg string,
+) (string, error)
+
+// ModifierMiddleware wraps a ModifierHandler, to intercept the calls a Spelunker
+// makes to types.SecretModifier.Modify.
+type ModifierMiddleware func(next ModifierHandler) ModifierHandler
+
+// WithDigUpMiddleware adds the given DigUpMiddleware to the chain wrapping every
+// call to Spelunker.DigUp.
+//
+// Middlewares compose in the order they are added: the first added is the outermost,
+// and sees the call first.
+func WithDigUpMiddleware(middlewares ...DigUpMiddleware) SpelunkerOption {
+	return func(o *options) {
+		o.digUpMiddlewares = append(o.digUpMiddlewares, middlewares...)
+	}
+}
+
+// WithSourceMiddleware adds the given SourceMiddleware to the chain wrapping every
+// call to types.SecretSource.DigUp.
+//
+// Middlewares compose in the order they are added: the first added is the outermost,
+// and sees the call first. Built-in features (e.g. WithCache) are part of the same chain.
+func WithSourceMiddleware(middlewares ...SourceMiddleware) SpelunkerOption {
+	return func(o *options) {
+		o.sourceMiddlewares = append(o.sourceMiddlewares, middlewares...)
+	}
+}
+
+// WithModifierMiddleware adds the given ModifierMiddleware to the chain wrapping every
+// call to types.SecretModifier.Modify.
+//
+// Middlewares compose in the order they are added: the first added is the outermost,
+// and sees the call first.
+func WithModifierMiddleware(middlewares ...ModifierMiddleware) SpelunkerOption {
+	return func(o *options) {
+		o.modifierMiddlewares = append(o.modifierMiddlewares, middlewares...)
+	}
+}
+
+// chainDigUpMiddlewares composes the given middlewares around handler.
+func chainDigUpMiddlewares(handler DigUpHandler, middlewares []DigUpMiddleware) DigUpHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// chainSourceMiddlewares composes the given middlewares around the call to types.SecretSource.DigUp.
+func chainSourceMiddlewares(middlewares []SourceMiddleware) SourceHandler {
+	handler := func(
+		ctx context.Context,
+		source types.SecretSource,
+		coord types.SecretCoord,
+	) (string, error) {
+		return digUpFromSource(ctx, source, coord)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// chainModifierMiddlewares composes the given middlewares around the call to types.SecretModifier.Modify.
+func chainModifierMiddlewares(middlewares []ModifierMiddleware) ModifierHandler {
+	handler := func(
+		ctx context.Context,
+		modifier types.SecretModifier,
+		_ types.SecretCoord,
+		value string,
+		arg string,
+	) (string, error) {
+		return modifyBytes(ctx, modifier, value, arg)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,209 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSourceMiddleware records the calls it sees into calls, tagged with name.
+func recordingSourceMiddleware(name string, calls *[]string) spelunk.SourceMiddleware {
+	return func(next spelunk.SourceHandler) spelunk.SourceHandler {
+		return func(
+			ctx context.Context,
+			source types.SecretSource,
+			coord types.SecretCoord,
+		) (string, error) {
+			*calls = append(*calls, name+":before:"+source.Type()+":"+coord.Location)
+			val, err := next(ctx, source, coord)
+			*calls = append(*calls, name+":after:"+val)
+			return val, err
+		}
+	}
+}
+
+func TestWithSourceMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+
+	var calls []string
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithSourceMiddleware(
+			recordingSourceMiddleware("first", &calls),
+			recordingSourceMiddleware("second", &calls),
+		),
+		spelunk.WithSourceMiddleware(recordingSourceMiddleware("third", &calls)),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "secret-value", got)
+
+	require.Equal(t, []string{
+		"first:before:test:loc",
+		"second:before:test:loc",
+		"third:before:test:loc",
+		"third:after:secret-value",
+		"second:after:secret-value",
+		"first:after:secret-value",
+	}, calls)
+}
+
+func TestWithSourceMiddleware_ShortCircuit(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	errDenied := errors.New("denied")
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithSourceMiddleware(func(next spelunk.SourceHandler) spelunk.SourceHandler {
+			return func(
+				ctx context.Context,
+				source types.SecretSource,
+				coord types.SecretCoord,
+			) (string, error) {
+				if coord.Location == "forbidden" {
+					return "", errDenied
+				}
+				return next(ctx, source, coord)
+			}
+		}),
+	)
+
+	coord, err := types.NewSecretCoord("test://forbidden")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(ctx, coord)
+	require.ErrorIs(t, err, spelunk.ErrFailedToDigUpSecret)
+	require.ErrorIs(t, err, errDenied)
+	require.Equal(t, 0, src.Calls())
+}
+
+func TestWithSourceMiddleware_AroundCache(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+
+	var outer, inner []string
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithSourceMiddleware(recordingSourceMiddleware("outer", &outer)),
+		spelunk.WithCache(),
+		spelunk.WithSourceMiddleware(recordingSourceMiddleware("inner", &inner)),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	for range 2 {
+		_, err = spelunker.DigUp(ctx, coord)
+		require.NoError(t, err)
+	}
+
+	// The outer middleware sees every call, the inner only the cache misses
+	require.Len(t, outer, 4)
+	require.Len(t, inner, 2)
+	require.Equal(t, 1, src.Calls())
+}
+
+func TestWithModifierMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "val"
+
+	var calls []string
+	recording := func(name string) spelunk.ModifierMiddleware {
+		return func(next spelunk.ModifierHandler) spelunk.ModifierHandler {
+			return func(
+				ctx context.Context,
+				modifier types.SecretModifier,
+				coord types.SecretCoord,
+				value string,
+				arg string,
+			) (string, error) {
+				res, err := next(ctx, modifier, coord, value, arg)
+				calls = append(calls, name+":"+coord.Type+":"+modifier.Type()+":"+arg+":"+res)
+				return res, err
+			}
+		}
+	}
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithModifier(&mockModifier{typ: "mod1"}),
+		spelunk.WithModifier(&mockModifier{typ: "mod2"}),
+		spelunk.WithModifierMiddleware(recording("outer"), recording("inner")),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc?mod1=a&mod2=b")
+	require.NoError(t, err)
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "val_a_b", got)
+
+	require.Equal(t, []string{
+		"inner:test:mod1:a:val_a",
+		"outer:test:mod1:a:val_a",
+		"inner:test:mod2:b:val_a_b",
+		"outer:test:mod2:b:val_a_b",
+	}, calls)
+}
+
+func TestWithDigUpMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "val"
+
+	var calls []string
+	recording := func(name string) spelunk.DigUpMiddleware {
+		return func(next spelunk.DigUpHandler) spelunk.DigUpHandler {
+			return func(ctx context.Context, coord *types.SecretCoord) (string, error) {
+				calls = append(calls, name+":before:"+coord.Type)
+				val, err := next(ctx, coord)
+				calls = append(calls, name+":after:"+val)
+				return val, err
+			}
+		}
+	}
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithModifier(&mockModifier{typ: "mod"}),
+		spelunk.WithDigUpMiddleware(recording("outer"), recording("inner")),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc?mod=a")
+	require.NoError(t, err)
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "val_a", got)
+
+	// Middlewares see the whole dig-up, modifiers included
+	require.Equal(t, []string{
+		"outer:before:test",
+		"inner:before:test",
+		"inner:after:val_a",
+		"outer:after:val_a",
+	}, calls)
+
+	// Failures before reaching the source are seen too
+	calls = nil
+	coord, err = types.NewSecretCoord("unknown://loc")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(ctx, coord)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	require.Len(t, calls, 4)
+}
@@ -14,9 +14,18 @@ import (
 // options are the internal configuration used by an instance of Spelunker.
 // They are set by client code using implementations of SpelunkerOption.
 type options struct {
-	trimValue bool
-	sources   map[string]types.SecretSource
-	modifiers map[string]types.SecretModifier
+	trimValue           bool
+	concurrency         int
+	sources             map[string]types.SecretSource
+	modifiers           map[string]types.SecretModifier
+	digUpMiddlewares    []DigUpMiddleware
+	sourceMiddlewares   []SourceMiddleware
+	modifierMiddlewares []ModifierMiddleware
+	cache               *secretCache
+	cacheIdx            int // index of the cache in sourceMiddlewares
+	cacheObservers      []CacheObserver
+	auditSinks          []AuditSink
+	policy              *policy
 }
 
 func (o *options) apply(opts ...SpelunkerOption) *options {
@@ -37,6 +46,7 @@ func (o *options) apply(opts ...SpelunkerOption) *options {
 func defaultOptions() []SpelunkerOption {
 	return []SpelunkerOption{
 		WithTrimValue(),
+		WithConcurrency(defaultConcurrency),
 		WithSource(&plain.SecretSourcePlain{}),
 		WithSource(&file.SecretSourceFile{}),
 		WithSource(&env.SecretSourceEnv{}),
@@ -50,6 +60,16 @@ func defaultOptions() []SpelunkerOption {
 // SpelunkerOption options that can be provided to NewSpelunker.
 type SpelunkerOption func(*options)
 
+// WithOptions groups the given SpelunkerOption into one, applied in order.
+// Useful to packages providing features that need multiple options (e.g. middlewares of different kinds).
+func WithOptions(opts ...SpelunkerOption) SpelunkerOption {
+	return func(o *options) {
+		for _, opt := range opts {
+			opt(o)
+		}
+	}
+}
+
 // WithTrimValue all leading and trailing (Unicode) white spaces
 // of the secret value are removed.
 // Enabled by Default.
@@ -67,6 +87,15 @@ func WithoutTrimValue() SpelunkerOption {
 	}
 }
 
+// WithConcurrency sets the maximum amount of secrets dug-up concurrently
+// by Spelunker.DigUpAll. A value < 1 is treated as 1.
+// Default is 8.
+func WithConcurrency(concurrency int) SpelunkerOption {
+	return func(o *options) {
+		o.concurrency = max(concurrency, 1)
+	}
+}
+
 // WithSource adds the given types.SecretSource to the set of sources
 // a Spelunker can use to dig-up secrets.
 func WithSource(source types.SecretSource) SpelunkerOption {
@@ -75,6 +104,31 @@ func WithSource(source types.SecretSource) SpelunkerOption {
 	}
 }
 
+// WithSourceAs adds the given types.SecretSource to the set of sources a Spelunker can use
+// to dig-up secrets, under a custom scheme rather than its type. This allows multiple instances
+// of the same type of source, each with its own client. For example:
+//
+//	spelunk.WithSourceAs("vault-eu", vault.New(euClient))
+//
+// makes coordinates like `vault-eu://kv/data/x/key` dig-up from that instance.
+// Options keyed by source type (e.g. WithCacheSourceTTL, WithPolicyAllowSources) refer to it by scheme.
+func WithSourceAs(scheme string, source types.SecretSource) SpelunkerOption {
+	return func(o *options) {
+		o.sources[scheme] = source
+	}
+}
+
+// WithoutSource removes the types.SecretSource of the given types from the set of sources
+// a Spelunker can use to dig-up secrets (e.g. the built-in `plain` and `base64`, enabled by default).
+// Coordinates of those types then fail with ErrUnsupportedSecretSourceType.
+func WithoutSource(sourceTypes ...string) SpelunkerOption {
+	return func(o *options) {
+		for _, sourceType := range sourceTypes {
+			delete(o.sources, sourceType)
+		}
+	}
+}
+
 // WithModifier adds the given types.SecretModifier to the set of modifiers
 // a Spelunker can apply to the value of secrets.
 func WithModifier(modifier types.SecretModifier) SpelunkerOption {
@@ -82,3 +136,26 @@ func WithModifier(modifier types.SecretModifier) SpelunkerOption {
 		o.modifiers[modifier.Type()] = modifier
 	}
 }
+
+// WithCache enables caching of the raw values dug-up by the sources of a Spelunker:
+// values are memoized by type and location, before modifiers are applied.
+// It can be configured providing one or more CacheOption.
+//
+// Caching is a SourceMiddleware: see WithSourceMiddleware for how it composes with others.
+//
+// Use Spelunker.Invalidate and Spelunker.Purge to explicitly evict cached values.
+//
+// A Spelunker has a single cache: if given more than once, the last configuration replaces
+// the previous ones, keeping the position of the first in the chain of SourceMiddleware.
+func WithCache(opts ...CacheOption) SpelunkerOption {
+	return func(o *options) {
+		if o.cache != nil {
+			o.cache = newSecretCache(opts...)
+			o.sourceMiddlewares[o.cacheIdx] = o.cache.middleware
+			return
+		}
+		o.cache = newSecretCache(opts...)
+		o.cacheIdx = len(o.sourceMiddlewares)
+		o.sourceMiddlewares = append(o.sourceMiddlewares, o.cache.middleware)
+	}
+}
@@ -0,0 +1,28 @@
+package spelunk
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// checkParams returns an error wrapping types.ErrUnsupportedParam, if coord has any parameter
+// that source doesn't support (see types.ParamSource).
+func checkParams(source types.SecretSource, coord *types.SecretCoord) error {
+	if len(coord.Params) == 0 {
+		return nil
+	}
+
+	var supported []string
+	if paramSource, ok := source.(types.ParamSource); ok {
+		supported = paramSource.Params()
+	}
+	for _, name := range slices.Sorted(maps.Keys(coord.Params)) {
+		if !slices.Contains(supported, name) {
+			return fmt.Errorf("%w: %q (source %q)", types.ErrUnsupportedParam, name, coord.Type)
+		}
+	}
+	return nil
+}
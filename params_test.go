@@ -0,0 +1,95 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedSource implements types.ParamSource for testing, digging up the requested version.
+type versionedSource struct {
+	*util.MockSource
+}
+
+func (s *versionedSource) DigUp(ctx context.Context, coord types.SecretCoord) (string, error) {
+	val, err := s.MockSource.DigUp(ctx, coord)
+	if version := coord.Param(types.ParamVersion); version != "" {
+		val += "@" + version
+	}
+	return val, err
+}
+
+func (s *versionedSource) Params() []string {
+	return []string{types.ParamVersion}
+}
+
+func TestSpelunker_DigUp_Params(t *testing.T) {
+	ctx := context.Background()
+
+	src := &versionedSource{MockSource: newValueSource("versioned", "val")}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithSource(newValueSource("mock", "val")),
+		spelunk.WithCache(),
+	)
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     string
+		errMatch error
+	}{
+		{
+			name:     "no params",
+			coordStr: "versioned://loc",
+			want:     "val",
+		},
+		{
+			name:     "supported param",
+			coordStr: "versioned://loc?@version=3",
+			want:     "val@3",
+		},
+		{
+			name:     "params are cached apart",
+			coordStr: "versioned://loc?@version=4&b64",
+			want:     "dmFsQDQ=",
+		},
+		{
+			name:     "unsupported param",
+			coordStr: "versioned://loc?@region=eu",
+			errMatch: types.ErrUnsupportedParam,
+		},
+		{
+			name:     "source without params",
+			coordStr: "mock://loc?@version=3",
+			errMatch: types.ErrUnsupportedParam,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.DigUp(ctx, coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+
+				digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+				require.True(t, errMatched)
+				require.Equal(t, types.ErrorClassInvalidLocation, digUpErr.Class)
+
+				require.ErrorIs(t, spelunker.Validate(coord), tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.NoError(t, spelunker.Validate(coord))
+		})
+	}
+}
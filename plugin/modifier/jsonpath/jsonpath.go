@@ -36,7 +36,7 @@ var (
 // See: https://github.com/oliveagle/jsonpath (underlying library).
 type SecretModifierJSONPath struct{}
 
-var _ types.SecretModifier = (*SecretModifierJSONPath)(nil)
+var _ types.ModifierValidator = (*SecretModifierJSONPath)(nil)
 
 func (s *SecretModifierJSONPath) Type() string {
 	return "jp"
@@ -70,6 +70,14 @@ func (s *SecretModifierJSONPath) Modify(
 	return strRes, nil
 }
 
+// ValidateModifier checks that the given JSONPath expression compiles.
+func (s *SecretModifierJSONPath) ValidateModifier(mod string) error {
+	if _, err := jp.Compile(mod); err != nil {
+		return fmt.Errorf("%w (%q): %w", ErrJSONPathInvalid, mod, err)
+	}
+	return nil
+}
+
 // WithJSONPath adds the JSONPath modifier to a Spelunker.
 func WithJSONPath() spelunk.SpelunkerOption {
 	return spelunk.WithModifier(&SecretModifierJSONPath{})
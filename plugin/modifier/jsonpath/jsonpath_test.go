@@ -116,3 +116,10 @@ func TestSecretModifier_JSONPath(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretModifierJSONPath_ValidateModifier(t *testing.T) {
+	m := &jsonpath.SecretModifierJSONPath{}
+
+	require.NoError(t, m.ValidateModifier("$.foo"))
+	require.ErrorIs(t, m.ValidateModifier("$.["), jsonpath.ErrJSONPathInvalid)
+}
@@ -22,7 +22,7 @@ var (
 // It parses the TOML into an object and applies standard JSONPath to it.
 type SecretModifierTOMLPath struct{}
 
-var _ types.SecretModifier = (*SecretModifierTOMLPath)(nil)
+var _ types.ModifierValidator = (*SecretModifierTOMLPath)(nil)
 
 func (s *SecretModifierTOMLPath) Type() string {
 	return "tp"
@@ -56,6 +56,14 @@ func (s *SecretModifierTOMLPath) Modify(
 	return strRes, nil
 }
 
+// ValidateModifier checks that the given JSONPath expression compiles.
+func (s *SecretModifierTOMLPath) ValidateModifier(mod string) error {
+	if _, err := jp.Compile(mod); err != nil {
+		return fmt.Errorf("%w (%q): %w", ErrTOMLPathInvalid, mod, err)
+	}
+	return nil
+}
+
 // WithTOMLPath adds the TOML JSONPath modifier to a Spelunker.
 func WithTOMLPath() spelunk.SpelunkerOption {
 	return spelunk.WithModifier(&SecretModifierTOMLPath{})
@@ -102,3 +102,10 @@ price = 12.99
 		})
 	}
 }
+
+func TestSecretModifierTOMLPath_ValidateModifier(t *testing.T) {
+	m := &tomlpath.SecretModifierTOMLPath{}
+
+	require.NoError(t, m.ValidateModifier("$.foo"))
+	require.ErrorIs(t, m.ValidateModifier("$.["), tomlpath.ErrTOMLPathInvalid)
+}
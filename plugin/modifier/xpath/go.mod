@@ -6,12 +6,12 @@ replace github.com/detro/spelunk/v2 => ../../../
 
 require (
 	github.com/antchfx/xmlquery v1.5.1
+	github.com/antchfx/xpath v1.3.8
 	github.com/detro/spelunk/v2 v2.1.0
 	github.com/stretchr/testify v1.12.0
 )
 
 require (
-	github.com/antchfx/xpath v1.3.8 // indirect
 	github.com/golang/groupcache v0.0.0-20241129210726-2c02b8208cf8 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	golang.org/x/net v0.58.0 // indirect
@@ -6,11 +6,13 @@ import (
 	"strings"
 
 	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
 	"github.com/detro/spelunk/v2"
 	"github.com/detro/spelunk/v2/types"
 )
 
 var (
+	ErrXPathInvalid        = fmt.Errorf("invalid XPath expression")
 	ErrXPathFailed         = fmt.Errorf("failed to apply XPath")
 	ErrXPathMatchingFailed = fmt.Errorf("failed to match XPath")
 	ErrSecretNotXML        = fmt.Errorf("secret is not a valid XML")
@@ -21,7 +23,7 @@ var (
 // the inner text found there.
 type SecretModifierXPath struct{}
 
-var _ types.SecretModifier = (*SecretModifierXPath)(nil)
+var _ types.ModifierValidator = (*SecretModifierXPath)(nil)
 
 func (s *SecretModifierXPath) Type() string {
 	return "xp"
@@ -56,6 +58,14 @@ func (s *SecretModifierXPath) Modify(
 	}
 }
 
+// ValidateModifier checks that the given XPath expression compiles.
+func (s *SecretModifierXPath) ValidateModifier(mod string) error {
+	if _, err := xpath.Compile(mod); err != nil {
+		return fmt.Errorf("%w (%q): %w", ErrXPathInvalid, mod, err)
+	}
+	return nil
+}
+
 // WithXPath adds the XPath modifier to a Spelunker.
 func WithXPath() spelunk.SpelunkerOption {
 	return spelunk.WithModifier(&SecretModifierXPath{})
@@ -110,3 +110,10 @@ func TestSecretModifierXPath_Modify(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretModifierXPath_ValidateModifier(t *testing.T) {
+	m := &xpath.SecretModifierXPath{}
+
+	require.NoError(t, m.ValidateModifier("//foo/bar"))
+	require.ErrorIs(t, m.ValidateModifier("//foo["), xpath.ErrXPathInvalid)
+}
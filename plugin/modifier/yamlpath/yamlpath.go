@@ -22,7 +22,7 @@ var (
 // It parses the YAML into an object and applies standard JSONPath to it.
 type SecretModifierYAMLPath struct{}
 
-var _ types.SecretModifier = (*SecretModifierYAMLPath)(nil)
+var _ types.ModifierValidator = (*SecretModifierYAMLPath)(nil)
 
 func (s *SecretModifierYAMLPath) Type() string {
 	return "yp"
@@ -56,6 +56,14 @@ func (s *SecretModifierYAMLPath) Modify(
 	return strRes, nil
 }
 
+// ValidateModifier checks that the given JSONPath expression compiles.
+func (s *SecretModifierYAMLPath) ValidateModifier(mod string) error {
+	if _, err := jp.Compile(mod); err != nil {
+		return fmt.Errorf("%w (%q): %w", ErrYAMLPathInvalid, mod, err)
+	}
+	return nil
+}
+
 // WithYAMLPath adds the YAML JSONPath modifier to a Spelunker.
 func WithYAMLPath() spelunk.SpelunkerOption {
 	return spelunk.WithModifier(&SecretModifierYAMLPath{})
@@ -100,3 +100,10 @@ store:
 		})
 	}
 }
+
+func TestSecretModifierYAMLPath_ValidateModifier(t *testing.T) {
+	m := &yamlpath.SecretModifierYAMLPath{}
+
+	require.NoError(t, m.ValidateModifier("$.foo"))
+	require.ErrorIs(t, m.ValidateModifier("$.["), yamlpath.ErrYAMLPathInvalid)
+}
@@ -2,7 +2,10 @@ package onepassword
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"slices"
 	"strings"
 
 	"github.com/1password/onepassword-sdk-go"
@@ -24,16 +27,25 @@ type SecretSource1Password struct {
 	client *onepassword.Client
 }
 
+// New creates a SecretSource1Password: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(client *onepassword.Client) *SecretSource1Password {
+	return &SecretSource1Password{
+		client: client,
+	}
+}
+
 // With1Password enables the SecretSource1Password.
 func With1Password(client *onepassword.Client) spelunk.SpelunkerOption {
-	return spelunk.WithSource(&SecretSource1Password{
-		client: client,
-	})
+	return spelunk.WithSource(New(client))
 }
 
 const Type = "op"
 
-var _ types.SecretSource = (*SecretSource1Password)(nil)
+var (
+	_ types.LocationValidator = (*SecretSource1Password)(nil)
+	_ types.SecretLister      = (*SecretSource1Password)(nil)
+)
 
 func (s *SecretSource1Password) Type() string {
 	return Type
@@ -43,13 +55,8 @@ func (s *SecretSource1Password) DigUp(
 	ctx context.Context,
 	coord types.SecretCoord,
 ) (string, error) {
-	parts := strings.Split(coord.Location, "/")
-	if len(parts) < 3 || len(parts) > 4 {
-		return "", fmt.Errorf(
-			"%w: expected VAULT/ITEM/FIELD or VAULT/ITEM/SECTION/FIELD, got %q",
-			types.ErrInvalidLocation,
-			coord.Location,
-		)
+	if err := s.ValidateLocation(coord); err != nil {
+		return "", err
 	}
 
 	// 1Password expects the reference in the format op://vault/item/field
@@ -57,8 +64,176 @@ func (s *SecretSource1Password) DigUp(
 
 	secret, err := s.client.Secrets().Resolve(ctx, opRef)
 	if err != nil {
-		return "", fmt.Errorf("%w (%q): %w", types.ErrCouldNotFetchSecret, coord.Location, err)
+		return "", wrapAPIError(types.ErrCouldNotFetchSecret, coord.Location, err)
 	}
 
 	return secret, nil
 }
+
+// List lists, for the prefix `VAULT`, the items in the vault (i.e. `VAULT/ITEM`) and, for the prefix
+// `VAULT/ITEM`, the fields of the item (i.e. `VAULT/ITEM/[SECTION/]FIELD`). Vaults, items and sections
+// can be referred to by title or ID: children are referred to by title, unless it can't be used in a
+// secret reference. Listing fields fetches the whole item, values included, but those are never returned.
+func (s *SecretSource1Password) List(
+	ctx context.Context,
+	prefix types.SecretCoord,
+) ([]types.SecretCoord, error) {
+	parts := strings.Split(strings.Trim(prefix.Location, "/"), "/")
+	if len(parts) > 2 || len(parts[0]) == 0 {
+		return nil, fmt.Errorf(
+			"%w: expected VAULT or VAULT/ITEM, got %q",
+			types.ErrInvalidLocation,
+			prefix.Location,
+		)
+	}
+	newFetchError := func(err error) error {
+		return wrapAPIError(types.ErrCouldNotFetchSecret, prefix.Location, err)
+	}
+
+	// Resolve the vault
+	vaults, err := s.client.Vaults().List(ctx)
+	if err != nil {
+		return nil, newFetchError(err)
+	}
+	vaultIdx := slices.IndexFunc(vaults, func(v onepassword.VaultOverview) bool {
+		return v.ID == parts[0] || v.Title == parts[0]
+	})
+	if vaultIdx < 0 {
+		return nil, nil
+	}
+	vaultID := vaults[vaultIdx].ID
+
+	items, err := s.client.Items().List(ctx, vaultID)
+	if err != nil {
+		return nil, newFetchError(err)
+	}
+
+	// Items of the vault
+	if len(parts) == 1 {
+		children := make([]types.SecretCoord, 0, len(items))
+		for _, item := range items {
+			children = append(children, types.SecretCoord{
+				Type:     prefix.Type,
+				Location: parts[0] + "/" + referenceName(item.Title, item.ID),
+			})
+		}
+		return children, nil
+	}
+
+	// Fields of the item
+	itemIdx := slices.IndexFunc(items, func(i onepassword.ItemOverview) bool {
+		return i.ID == parts[1] || i.Title == parts[1]
+	})
+	if itemIdx < 0 {
+		return nil, nil
+	}
+	item, err := s.client.Items().Get(ctx, vaultID, items[itemIdx].ID)
+	if err != nil {
+		return nil, newFetchError(err)
+	}
+
+	// Fields in untitled sections are referred to without section
+	sections := make(map[string]string, len(item.Sections))
+	for _, section := range item.Sections {
+		if len(section.Title) > 0 {
+			sections[section.ID] = referenceName(section.Title, section.ID)
+		}
+	}
+	children := make([]types.SecretCoord, 0, len(item.Fields))
+	for _, field := range item.Fields {
+		location := parts[0] + "/" + parts[1] + "/"
+		if field.SectionID != nil && len(sections[*field.SectionID]) > 0 {
+			location += sections[*field.SectionID] + "/"
+		}
+		children = append(children, types.SecretCoord{
+			Type:     prefix.Type,
+			Location: location + referenceName(field.Title, field.ID),
+		})
+	}
+	return children, nil
+}
+
+// wrapAPIError wraps an error returned by the 1Password SDK into failure, classifying it.
+func wrapAPIError(failure error, location string, err error) error {
+	if isNotFound(err) {
+		return fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, location, err)
+	}
+
+	var class error
+	switch {
+	case isTransient(err):
+		class = types.ErrTransient
+	case isUnauthenticated(err):
+		class = types.ErrUnauthenticated
+	case isPermissionDenied(err):
+		class = types.ErrPermissionDenied
+	}
+	if class != nil {
+		return fmt.Errorf("%w (%q): %w: %w", failure, location, class, err)
+	}
+	return fmt.Errorf("%w (%q): %w", failure, location, err)
+}
+
+// The SDK only has typed errors for a few failures: the others are told apart by their message.
+
+// isNotFound returns true if err reports that the vault, the item or the field does not exist.
+func isNotFound(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "no vault matched") ||
+		strings.Contains(msg, "no item matched") ||
+		strings.Contains(msg, "cannot be found") ||
+		strings.Contains(msg, "not found")
+}
+
+// isTransient returns true if err is worth retrying: rate limiting, or a dropped connection.
+func isTransient(err error) bool {
+	if _, errMatched := errors.AsType[*onepassword.RateLimitExceededError](err); errMatched {
+		return true
+	}
+	if _, errMatched := errors.AsType[net.Error](err); errMatched {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "connection was unexpectedly dropped") ||
+		strings.Contains(msg, "connection channel is closed")
+}
+
+// isUnauthenticated returns true if err reports missing, invalid or expired credentials.
+func isUnauthenticated(err error) bool {
+	if _, errMatched := errors.AsType[*onepassword.DesktopSessionExpiredError](err); errMatched {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "invalid service account token") ||
+		strings.Contains(msg, "unauthorized") ||
+		strings.Contains(msg, "authentication")
+}
+
+// isPermissionDenied returns true if err reports that the credentials lack access.
+func isPermissionDenied(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "forbidden") ||
+		strings.Contains(msg, "permission") ||
+		strings.Contains(msg, "not authorized")
+}
+
+// referenceName returns title, if it can be used in a secret reference, or id otherwise.
+func referenceName(title, id string) string {
+	if len(title) == 0 || strings.ContainsAny(title, "/?#") {
+		return id
+	}
+	return title
+}
+
+// ValidateLocation checks that the location has a vault, an item, an optional section and a field.
+func (s *SecretSource1Password) ValidateLocation(coord types.SecretCoord) error {
+	parts := strings.Split(coord.Location, "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		return fmt.Errorf(
+			"%w: expected VAULT/ITEM/FIELD or VAULT/ITEM/SECTION/FIELD, got %q",
+			types.ErrInvalidLocation,
+			coord.Location,
+		)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package onepassword_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 
@@ -50,6 +51,10 @@ func TestSecretSource1Password_DigUp_Parsing(t *testing.T) {
 
 			_, err = s.DigUp(t.Context(), *coord)
 			require.ErrorIs(t, err, tt.errMatch)
+
+			err = s.ValidateLocation(*coord)
+			require.ErrorIs(t, err, tt.errMatch)
+			require.ErrorIs(t, err, types.ErrInvalidLocation)
 		})
 	}
 }
@@ -99,12 +104,12 @@ func TestSecretSource1Password_DigUp_Integration(t *testing.T) {
 		{
 			name:     "secret that does not exist",
 			coordStr: "op://non-existent-vault/item/password",
-			errMatch: types.ErrCouldNotFetchSecret,
+			errMatch: types.ErrSecretNotFound,
 		},
 		{
 			name:     "secret that does not exist (with section)",
 			coordStr: "op://non-existent-vault/item/section/password",
-			errMatch: types.ErrCouldNotFetchSecret,
+			errMatch: types.ErrSecretNotFound,
 		},
 		{
 			name:     "valid secret via jp modifier",
@@ -138,3 +143,193 @@ func TestSecretSource1Password_DigUp_Integration(t *testing.T) {
 		})
 	}
 }
+
+// mockSecrets implements onepassword.SecretsAPI for testing, failing every resolution with err.
+type mockSecrets struct {
+	onepassword.SecretsAPI
+	err error
+}
+
+func (m *mockSecrets) Resolve(_ context.Context, _ string) (string, error) {
+	return "", m.err
+}
+
+func TestSecretSource1Password_DigUp_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		errMatch []error
+	}{
+		{
+			name:     "vault not found",
+			err:      errors.New("error resolving secret reference: no vault matched the secret reference query"),
+			errMatch: []error{types.ErrSecretNotFound},
+		},
+		{
+			name:     "field not found",
+			err:      errors.New("the specified field cannot be found within the item"),
+			errMatch: []error{types.ErrSecretNotFound},
+		},
+		{
+			name:     "rate limited",
+			err:      &onepassword.RateLimitExceededError{},
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrTransient},
+		},
+		{
+			name:     "desktop session expired",
+			err:      &onepassword.DesktopSessionExpiredError{},
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrUnauthenticated},
+		},
+		{
+			name:     "invalid token",
+			err:      errors.New("invalid service account token, please make sure you provide a valid token"),
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrUnauthenticated},
+		},
+		{
+			name:     "unclassified",
+			err:      errors.New("boom"),
+			errMatch: []error{types.ErrCouldNotFetchSecret},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &onepassword.Client{SecretsAPI: &mockSecrets{err: tt.err}}
+			spelunker := spelunk.NewSpelunker(spelunkop.With1Password(client))
+
+			coord, err := types.NewSecretCoord("op://Prod/Database/password")
+			require.NoError(t, err)
+
+			_, err = spelunker.DigUp(t.Context(), coord)
+			for _, match := range tt.errMatch {
+				require.ErrorIs(t, err, match)
+			}
+		})
+	}
+}
+
+// mockVaults implements onepassword.VaultsAPI for testing, listing the given vaults or failing with err.
+type mockVaults struct {
+	onepassword.VaultsAPI
+	vaults []onepassword.VaultOverview
+	err    error
+}
+
+func (m *mockVaults) List(_ context.Context, _ ...onepassword.VaultListParams) ([]onepassword.VaultOverview, error) {
+	return m.vaults, m.err
+}
+
+// mockItems implements onepassword.ItemsAPI for testing, holding the given items.
+type mockItems struct {
+	onepassword.ItemsAPI
+	items []onepassword.Item
+}
+
+func (m *mockItems) List(
+	_ context.Context,
+	vaultID string,
+	_ ...onepassword.ItemListFilter,
+) ([]onepassword.ItemOverview, error) {
+	var overviews []onepassword.ItemOverview
+	for _, item := range m.items {
+		if item.VaultID == vaultID {
+			overviews = append(overviews, onepassword.ItemOverview{ID: item.ID, Title: item.Title, VaultID: vaultID})
+		}
+	}
+	return overviews, nil
+}
+
+func (m *mockItems) Get(_ context.Context, vaultID string, itemID string) (onepassword.Item, error) {
+	for _, item := range m.items {
+		if item.VaultID == vaultID && item.ID == itemID {
+			return item, nil
+		}
+	}
+	return onepassword.Item{}, errors.New("item not found in mock")
+}
+
+func TestSecretSource1Password_List(t *testing.T) {
+	sectionID := "s1"
+	client := &onepassword.Client{
+		VaultsAPI: &mockVaults{vaults: []onepassword.VaultOverview{{ID: "v1", Title: "Prod"}}},
+		ItemsAPI: &mockItems{items: []onepassword.Item{
+			{
+				ID:       "i1",
+				Title:    "Database",
+				VaultID:  "v1",
+				Sections: []onepassword.ItemSection{{ID: sectionID, Title: "admin"}},
+				Fields: []onepassword.ItemField{
+					{ID: "password", Title: "password", Value: "s3cret"},
+					{ID: "f2", Title: "token", SectionID: &sectionID, Value: "t0ken"},
+				},
+			},
+			{ID: "i2", Title: "API/Keys", VaultID: "v1"},
+		}},
+	}
+	spelunker := spelunk.NewSpelunker(spelunkop.With1Password(client))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     []string
+		errMatch error
+	}{
+		{
+			name:     "vault by title",
+			coordStr: "op://Prod/",
+			want:     []string{"Prod/Database", "Prod/i2"},
+		},
+		{
+			name:     "item by title",
+			coordStr: "op://Prod/Database",
+			want:     []string{"Prod/Database/admin/token", "Prod/Database/password"},
+		},
+		{
+			name:     "item by ID",
+			coordStr: "op://v1/i1",
+			want:     []string{"v1/i1/admin/token", "v1/i1/password"},
+		},
+		{
+			name:     "vault that does not exist",
+			coordStr: "op://Staging",
+			want:     []string{},
+		},
+		{
+			name:     "invalid location",
+			coordStr: "op://Prod/Database/password",
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	t.Run("rate limited", func(t *testing.T) {
+		client := &onepassword.Client{
+			VaultsAPI: &mockVaults{err: &onepassword.RateLimitExceededError{}},
+		}
+		coord, err := types.NewSecretCoord("op://Prod/")
+		require.NoError(t, err)
+
+		_, err = spelunk.NewSpelunker(spelunkop.With1Password(client)).List(t.Context(), coord)
+		require.ErrorIs(t, err, types.ErrCouldNotFetchSecret)
+		require.ErrorIs(t, err, types.ErrTransient)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.List(t.Context(), coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+
+			locations := []string{}
+			for _, child := range got {
+				locations = append(locations, child.Location)
+			}
+			require.Equal(t, tt.want, locations)
+		})
+	}
+}
@@ -2,12 +2,18 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/smithy-go"
 	"github.com/detro/spelunk/v2"
 	"github.com/detro/spelunk/v2/types"
 )
@@ -27,6 +33,9 @@ var (
 	secretARNRegexp = regexp.MustCompile(
 		`^arn:aws(?:-[a-z]+)*:secretsmanager:[a-z0-9-]+:\d{12}:secret:[a-zA-Z0-9][a-zA-Z0-9/_+=.@-]{0,504}-[a-zA-Z0-9]{6}$`,
 	)
+
+	// versionIDRegexp matches version IDs (i.e. UUIDs), telling them apart from staging labels.
+	versionIDRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 )
 
 // SecretSourceAWS digs up secrets from AWS Secrets Manager.
@@ -37,8 +46,13 @@ var (
 //	aws:///<SECRET_ARN>
 //
 // AWS Secrets Manager supports storing secrets either as a String, or as Binary (i.e. array of bytes).
-// In the case of the latter, the API returns the Base64-encoded version of the bytes. Spelunk respects
-// that and leaves it to you to either consume the secret in base64 form or decode it using the `?b64d` modifier.
+// In the case of the latter, the bytes are returned as they are, without any encoding: use DigUpBytes
+// (via Spelunker.DigUpBytes) to get them without a conversion to string.
+//
+// A specific version is dug-up via the `version` parameter (see types.ParamVersion), set to either
+// a version ID (i.e. a UUID) or a staging label (e.g. `AWSPREVIOUS`):
+//
+//	aws://<SECRET_NAME>?@version=AWSPREVIOUS
 //
 // NOTE: When referring to a secret by ARN, it is important to use the prefix `aws:///` to ensure
 // we don't confuse the internal Spelunk parser, given the "peculiar" format of AWS ARNs containing the `:` character.
@@ -50,22 +64,254 @@ type SecretSourceAWS struct {
 	client *secretsmanager.Client
 }
 
+// New creates a SecretSourceAWS: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(client *secretsmanager.Client) *SecretSourceAWS {
+	return &SecretSourceAWS{
+		client: client,
+	}
+}
+
 // WithAWS enables the SecretSourceAWS.
 func WithAWS(client *secretsmanager.Client) spelunk.SpelunkerOption {
-	return spelunk.WithSource(&SecretSourceAWS{
-		client: client,
-	})
+	return spelunk.WithSource(New(client))
 }
 
 const Type = "aws"
 
-var _ types.SecretSource = (*SecretSourceAWS)(nil)
+var (
+	_ types.BytesSource       = (*SecretSourceAWS)(nil)
+	_ types.MetadataSource    = (*SecretSourceAWS)(nil)
+	_ types.LocationValidator = (*SecretSourceAWS)(nil)
+	_ types.ExistenceChecker  = (*SecretSourceAWS)(nil)
+	_ types.SecretLister      = (*SecretSourceAWS)(nil)
+	_ types.SecretWriter      = (*SecretSourceAWS)(nil)
+	_ types.ParamSource       = (*SecretSourceAWS)(nil)
+)
 
 func (s *SecretSourceAWS) Type() string {
 	return Type
 }
 
+// Params returns the parameters supported: types.ParamVersion, a version ID or staging label.
+func (s *SecretSourceAWS) Params() []string {
+	return []string{types.ParamVersion}
+}
+
 func (s *SecretSourceAWS) DigUp(ctx context.Context, coord types.SecretCoord) (string, error) {
+	val, err := s.DigUpBytes(ctx, coord)
+	return string(val), err
+}
+
+// DigUpBytes returns the secret as raw bytes: either `SecretString` or `SecretBinary`.
+func (s *SecretSourceAWS) DigUpBytes(ctx context.Context, coord types.SecretCoord) ([]byte, error) {
+	val, _, err := s.getSecretValue(ctx, coord)
+	return val, err
+}
+
+// DigUpWithMetadata returns the secret alongside its metadata: `VersionId`, `CreatedDate`,
+// and `VersionStages` and `ARN` (as extras `version_stages`, comma-separated, and `arn`).
+func (s *SecretSourceAWS) DigUpWithMetadata(
+	ctx context.Context,
+	coord types.SecretCoord,
+) (string, *types.SecretMetadata, error) {
+	val, res, err := s.getSecretValue(ctx, coord)
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata := &types.SecretMetadata{
+		Version:   aws.ToString(res.VersionId),
+		CreatedAt: aws.ToTime(res.CreatedDate),
+	}
+	if len(res.VersionStages) > 0 {
+		metadata.SetExtra("version_stages", strings.Join(res.VersionStages, ","))
+	}
+	if res.ARN != nil {
+		metadata.SetExtra("arn", *res.ARN)
+	}
+	return string(val), metadata, nil
+}
+
+// getSecretValue returns the secret pointed at by coord as raw bytes, and the response it was extracted from.
+func (s *SecretSourceAWS) getSecretValue(
+	ctx context.Context,
+	coord types.SecretCoord,
+) ([]byte, *secretsmanager.GetSecretValueOutput, error) {
+	secretID, err := parseLocation(coord)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Retrieve secret (at the version requested, if any)
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	}
+	if version := coord.Param(types.ParamVersion); versionIDRegexp.MatchString(version) {
+		input.VersionId = aws.String(version)
+	} else if len(version) > 0 {
+		input.VersionStage = aws.String(version)
+	}
+	res, err := s.client.GetSecretValue(ctx, input)
+	if err != nil {
+		return nil, nil, wrapFetchError(coord, err)
+	}
+
+	// Extract and return secret, or error if missing
+	if res.SecretString != nil {
+		// Secret is a string
+		return []byte(*res.SecretString), res, nil
+	}
+	if res.SecretBinary != nil {
+		// Secret is a binary: we return it as is, the user will decide how to handle it.
+		return res.SecretBinary, res, nil
+	}
+	return nil, nil, fmt.Errorf(
+		"%w (%q): secret contains no data",
+		types.ErrSecretNotFound,
+		coord.Location,
+	)
+}
+
+// Exists checks if the secret exists via `DescribeSecret`, without retrieving its value.
+// Secrets scheduled for deletion are considered as not existing, as their value can't be retrieved.
+// If a version is requested (see types.ParamVersion), the secret must have a version with that ID or staging label.
+func (s *SecretSourceAWS) Exists(ctx context.Context, coord types.SecretCoord) (bool, error) {
+	secretID, err := parseLocation(coord)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.client.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		if err = wrapFetchError(coord, err); errors.Is(err, types.ErrSecretNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if res.DeletedDate != nil {
+		return false, nil
+	}
+
+	version := coord.Param(types.ParamVersion)
+	if len(version) == 0 {
+		return true, nil
+	}
+	for versionID, stages := range res.VersionIdsToStages {
+		if versionID == version || slices.Contains(stages, version) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// List lists the secrets whose name starts with the prefix (e.g. `aws://prod/`), via `ListSecrets`:
+// `aws:///` lists all secrets. Secrets scheduled for deletion are left out.
+// Secrets are referred to by name, unless their name can't be told apart from an ARN suffix.
+func (s *SecretSourceAWS) List(ctx context.Context, prefix types.SecretCoord) ([]types.SecretCoord, error) {
+	namePrefix := strings.TrimPrefix(prefix.Location, "/")
+
+	input := &secretsmanager.ListSecretsInput{}
+	if len(namePrefix) > 0 {
+		input.Filters = []smtypes.Filter{{
+			Key:    smtypes.FilterNameStringTypeName,
+			Values: []string{namePrefix},
+		}}
+	}
+
+	var children []types.SecretCoord
+	paginator := secretsmanager.NewListSecretsPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, wrapFetchError(prefix, err)
+		}
+
+		for _, entry := range page.SecretList {
+			location := aws.ToString(entry.Name)
+			if secretNameDisallowedSuffixRegexp.MatchString(location) {
+				location = "/" + aws.ToString(entry.ARN)
+			}
+			children = append(children, types.SecretCoord{Type: prefix.Type, Location: location})
+		}
+	}
+	return children, nil
+}
+
+// Put writes the secret via `PutSecretValue`, creating a new version labelled `AWSCURRENT`: as `SecretString`
+// or, when value is not valid UTF-8 (e.g. raw bytes copied from a binary secret), as `SecretBinary`.
+// If the secret doesn't exist and is referred to by name, it's created via `CreateSecret`.
+// Secrets scheduled for deletion can't be written, until restored. As every write creates a new version,
+// a specific version (see types.ParamVersion) can't be written. AWS Secrets Manager doesn't offer compare-and-set.
+func (s *SecretSourceAWS) Put(
+	ctx context.Context,
+	coord types.SecretCoord,
+	value string,
+	opts types.PutOptions,
+) error {
+	if len(opts.IfVersion) > 0 {
+		return fmt.Errorf("%w: AWS Secrets Manager has no conditional writes", types.ErrCompareAndSetNotSupported)
+	}
+	secretID, err := parseUnversionedLocation(coord)
+	if err != nil {
+		return err
+	}
+
+	// AWS rejects a SecretString that is not valid UTF-8
+	var secretString *string
+	var secretBinary []byte
+	if utf8.ValidString(value) {
+		secretString = aws.String(value)
+	} else {
+		secretBinary = []byte(value)
+	}
+
+	_, err = s.client.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(secretID),
+		SecretString: secretString,
+		SecretBinary: secretBinary,
+	})
+	if _, notFound := errors.AsType[*smtypes.ResourceNotFoundException](err); notFound &&
+		!secretARNRegexp.MatchString(secretID) {
+		_, err = s.client.CreateSecret(ctx, &secretsmanager.CreateSecretInput{
+			Name:         aws.String(secretID),
+			SecretString: secretString,
+			SecretBinary: secretBinary,
+		})
+	}
+	if err != nil {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// Delete schedules the secret for deletion via `DeleteSecret`, with the default recovery window
+// (30 days): until then, it can be restored. Versions can't be deleted one by one.
+func (s *SecretSourceAWS) Delete(ctx context.Context, coord types.SecretCoord) error {
+	secretID, err := parseUnversionedLocation(coord)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	if _, notFound := errors.AsType[*smtypes.ResourceNotFoundException](err); err != nil && !notFound {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// ValidateLocation checks that the location is a valid secret name or ARN.
+func (s *SecretSourceAWS) ValidateLocation(coord types.SecretCoord) error {
+	_, err := parseLocation(coord)
+	return err
+}
+
+// parseLocation returns the secret ID (i.e. name or ARN) the location of coord points at.
+func parseLocation(coord types.SecretCoord) (string, error) {
 	// Strip trailing slash if present (often happens when the URI contains query parameters e.g. /?jp=$.password)
 	location := coord.Location
 	if len(location) > 0 && location[len(location)-1] == '/' {
@@ -82,7 +328,12 @@ func (s *SecretSourceAWS) DigUp(ctx context.Context, coord types.SecretCoord) (s
 		// Valid ARN, nothing more to check
 	case secretNameRegexp.MatchString(secretID):
 		if secretNameDisallowedSuffixRegexp.MatchString(secretID) {
-			return "", fmt.Errorf("%w: %q", ErrSecretSourceAWSInvalidNameSuffix, coord.Location)
+			return "", fmt.Errorf(
+				"%w: %w: %q",
+				types.ErrInvalidLocation,
+				ErrSecretSourceAWSInvalidNameSuffix,
+				coord.Location,
+			)
 		}
 	default:
 		return "", fmt.Errorf(
@@ -91,32 +342,84 @@ func (s *SecretSourceAWS) DigUp(ctx context.Context, coord types.SecretCoord) (s
 			coord.Location,
 		)
 	}
+	return secretID, nil
+}
 
-	// Retrieve secret
-	res, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
-		SecretId: aws.String(secretID),
-	})
-	if err != nil {
-		// Differentiate between not found and other errors if possible
-		if strings.Contains(err.Error(), "ResourceNotFoundException") {
-			return "", fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
-		}
-		return "", fmt.Errorf("%w (%q): %w", types.ErrCouldNotFetchSecret, coord.Location, err)
+// parseUnversionedLocation returns the secret ID, like parseLocation,
+// once checked that no version is requested (see types.ParamVersion).
+func parseUnversionedLocation(coord types.SecretCoord) (string, error) {
+	if version := coord.Param(types.ParamVersion); len(version) > 0 {
+		return "", fmt.Errorf(
+			"%w: expected no version, got %q (%q)",
+			types.ErrInvalidLocation,
+			version,
+			coord.Location,
+		)
 	}
+	return parseLocation(coord)
+}
 
-	// Extract and return secret, or error if missing
-	if res.SecretString != nil {
-		// Secret is a string
-		return *res.SecretString, nil
+// wrapFetchError wraps a failure of the AWS API reading a secret, differentiating between not found and
+// other errors, and classifying the latter as permission denied, unauthenticated or transient if they are.
+func wrapFetchError(coord types.SecretCoord, err error) error {
+	return wrapAPIError(types.ErrCouldNotFetchSecret, coord, err)
+}
+
+// wrapWriteError wraps a failure of the AWS API writing or deleting a secret, like wrapFetchError:
+// a secret created concurrently (i.e. `ResourceExistsException`) is classified as a version conflict.
+func wrapWriteError(coord types.SecretCoord, err error) error {
+	if _, errMatched := errors.AsType[*smtypes.ResourceExistsException](err); errMatched {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			types.ErrCouldNotWriteSecret,
+			coord.Location,
+			types.ErrVersionConflict,
+			err,
+		)
 	}
-	if res.SecretBinary != nil {
-		// Secret is a binary, and AWS Secret Manager returns it as Base64-encoded array of bytes.
-		// We return it as a string but leave it encoded: the user will decide how to handle it.
-		return string(res.SecretBinary), nil
+	return wrapAPIError(types.ErrCouldNotWriteSecret, coord, err)
+}
+
+// wrapAPIError wraps a failure of the AWS API in the given failure error, unless it's a not found.
+func wrapAPIError(failure error, coord types.SecretCoord, err error) error {
+	if _, errMatched := errors.AsType[*smtypes.ResourceNotFoundException](err); errMatched {
+		return fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
 	}
-	return "", fmt.Errorf(
-		"%w (%q): secret contains no data",
-		types.ErrSecretNotFound,
-		coord.Location,
-	)
+	class := classifyAPIError(err)
+	if class == nil && isTransient(err) {
+		class = types.ErrTransient
+	}
+	if class != nil {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			failure,
+			coord.Location,
+			class,
+			err,
+		)
+	}
+	return fmt.Errorf("%w (%q): %w", failure, coord.Location, err)
+}
+
+// classifyAPIError returns types.ErrPermissionDenied or types.ErrUnauthenticated,
+// if err is an AWS API error with a matching code, or nil otherwise.
+func classifyAPIError(err error) error {
+	apiErr, errMatched := errors.AsType[smithy.APIError](err)
+	if !errMatched {
+		return nil
+	}
+	switch apiErr.ErrorCode() {
+	case "AccessDeniedException":
+		return types.ErrPermissionDenied
+	case "UnrecognizedClientException", "InvalidSignatureException", "ExpiredTokenException":
+		return types.ErrUnauthenticated
+	default:
+		return nil
+	}
+}
+
+// isTransient returns true if err is one the AWS SDK considers retryable
+// (e.g. throttling, 5xx responses, connection errors).
+func isTransient(err error) bool {
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
 }
@@ -118,6 +118,21 @@ func TestSecretSourceAWS_DigUp_Integration(t *testing.T) {
 			coordStr: fmt.Sprintf("aws:///%s?b64d", *(secrets[plainSecretName]).ARN),
 			want:     plainSecretValue,
 		},
+		{
+			name:     "(json) secret at version ID",
+			coordStr: fmt.Sprintf("aws://%s?@version=%s", jsonSecretName, *(secrets[jsonSecretName]).VersionId),
+			want:     jsonSecretValue,
+		},
+		{
+			name:     "(json) secret at staging label",
+			coordStr: fmt.Sprintf("aws://%s?@version=AWSCURRENT&jp=$.key", jsonSecretName),
+			want:     "value",
+		},
+		{
+			name:     "(json) secret at staging label that does not exist",
+			coordStr: fmt.Sprintf("aws://%s?@version=AWSPREVIOUS", jsonSecretName),
+			errMatch: types.ErrSecretNotFound,
+		},
 		{
 			name:     "secret that does not exist",
 			coordStr: "aws://missing/secret",
@@ -167,6 +182,197 @@ func TestSecretSourceAWS_DigUp_Integration(t *testing.T) {
 	}
 }
 
+func TestSecretSourceAWS_Exists_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	awsClient, err := setupAWSTestContainer(t)
+	require.NoError(t, err)
+	secrets := createTestSecrets(t, awsClient)
+
+	_, err = awsClient.DeleteSecret(ctx, &secretsmanager.DeleteSecretInput{
+		SecretId: aws.String(flatSecretName),
+	})
+	require.NoError(t, err)
+
+	spelunker := spelunk.NewSpelunker(spelunkaws.WithAWS(awsClient))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     bool
+		errMatch error
+	}{
+		{
+			name:     "secret by name",
+			coordStr: fmt.Sprintf("aws://%s", jsonSecretName),
+			want:     true,
+		},
+		{
+			name:     "secret by exact ARN (with ///)",
+			coordStr: fmt.Sprintf("aws:///%s", *(secrets[plainSecretName]).ARN),
+			want:     true,
+		},
+		{
+			name:     "secret at version ID",
+			coordStr: fmt.Sprintf("aws://%s?@version=%s", jsonSecretName, *(secrets[jsonSecretName]).VersionId),
+			want:     true,
+		},
+		{
+			name:     "secret at staging label that does not exist",
+			coordStr: fmt.Sprintf("aws://%s?@version=AWSPREVIOUS", jsonSecretName),
+			want:     false,
+		},
+		{
+			name:     "secret scheduled for deletion",
+			coordStr: fmt.Sprintf("aws://%s", flatSecretName),
+			want:     false,
+		},
+		{
+			name:     "secret that does not exist",
+			coordStr: "aws://missing/secret",
+			want:     false,
+		},
+		{
+			name:     "invalid location",
+			coordStr: "aws://invalid!name",
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.Exists(ctx, coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSecretSourceAWS_List_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	awsClient, err := setupAWSTestContainer(t)
+	require.NoError(t, err)
+	createTestSecrets(t, awsClient)
+
+	spelunker := spelunk.NewSpelunker(spelunkaws.WithAWS(awsClient))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     []string
+	}{
+		{
+			name:     "name prefix",
+			coordStr: "aws://my-app/",
+			want:     []string{"aws://" + jsonSecretName, "aws://" + plainSecretName},
+		},
+		{
+			name:     "all secrets",
+			coordStr: "aws:///",
+			want:     []string{"aws://" + flatSecretName, "aws://" + jsonSecretName, "aws://" + plainSecretName},
+		},
+		{
+			name:     "no match",
+			coordStr: "aws://missing/",
+			want:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.List(t.Context(), coord)
+			require.NoError(t, err)
+
+			uris := []string{}
+			for _, child := range got {
+				uris = append(uris, child.URI())
+			}
+			require.Equal(t, tt.want, uris)
+		})
+	}
+}
+
+func TestSecretSourceAWS_PutDelete_Version(t *testing.T) {
+	s := &spelunkaws.SecretSourceAWS{}
+
+	// Versions are rejected before any call is made
+	coord, err := types.NewSecretCoord("aws://my-app/secret?@version=AWSPREVIOUS")
+	require.NoError(t, err)
+	require.ErrorIs(t, s.Put(t.Context(), *coord, "val", types.PutOptions{}), types.ErrInvalidLocation)
+	require.ErrorIs(t, s.Delete(t.Context(), *coord), types.ErrInvalidLocation)
+}
+
+func TestSecretSourceAWS_PutDelete_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	awsClient, err := setupAWSTestContainer(t)
+	require.NoError(t, err)
+	secrets := createTestSecrets(t, awsClient)
+
+	spelunker := spelunk.NewSpelunker(spelunkaws.WithAWS(awsClient))
+	coord := func(coordStr string) *types.SecretCoord {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return coord
+	}
+
+	// Created by name, then updated
+	newSecret := coord("aws://seeded/secret")
+	require.NoError(t, spelunker.Put(ctx, newSecret, "first", types.PutOptions{}))
+	require.NoError(t, spelunker.Put(ctx, newSecret, "second", types.PutOptions{}))
+	got, err := spelunker.DigUp(ctx, newSecret)
+	require.NoError(t, err)
+	require.Equal(t, "second", got)
+
+	// Updated by ARN
+	byARN := coord(fmt.Sprintf("aws:///%s", *(secrets[plainSecretName]).ARN))
+	require.NoError(t, spelunker.Put(ctx, byARN, "rotated", types.PutOptions{}))
+	got, err = spelunker.DigUp(ctx, byARN)
+	require.NoError(t, err)
+	require.Equal(t, "rotated", got)
+
+	// Values that are not valid UTF-8 are written as binary
+	binarySecret := coord("aws://seeded/binary")
+	binaryValue := string([]byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0xff})
+	require.NoError(t, spelunker.Put(ctx, binarySecret, binaryValue, types.PutOptions{}))
+	require.NoError(t, spelunker.Put(ctx, binarySecret, binaryValue+"\xfe", types.PutOptions{}))
+	gotBytes, err := spelunker.DigUpBytes(ctx, binarySecret)
+	require.NoError(t, err)
+	require.Equal(t, []byte(binaryValue+"\xfe"), gotBytes)
+	res, err := awsClient.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String("seeded/binary")})
+	require.NoError(t, err)
+	require.Nil(t, res.SecretString)
+
+	err = spelunker.Put(ctx, newSecret, "third", types.PutOptions{IfVersion: "1"})
+	require.ErrorIs(t, err, types.ErrCompareAndSetNotSupported)
+
+	// Scheduled for deletion, also when it doesn't exist
+	require.NoError(t, spelunker.Delete(ctx, newSecret))
+	require.NoError(t, spelunker.Delete(ctx, coord("aws://missing/secret")))
+	exists, err := spelunker.Exists(ctx, newSecret)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
 func createTestSecrets(
 	t *testing.T,
 	client *secretsmanager.Client,
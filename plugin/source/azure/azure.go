@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"regexp"
 	"strings"
 
@@ -29,6 +31,9 @@ var (
 	latestSecretVersionShortNameRegexp = regexp.MustCompile(
 		`^([a-zA-Z0-9-]{1,127})$`,
 	)
+
+	// versionRegexp matches the versions of a secret.
+	versionRegexp = regexp.MustCompile(`^[a-fA-F0-9]{32}$`)
 )
 
 // SecretSourceAzure digs up secrets from Azure Key Vault.
@@ -38,6 +43,10 @@ var (
 //	az://<SECRET_NAME>
 //	az://<SECRET_NAME>/<VERSION>
 //
+// The version can also be set via the `version` parameter (see types.ParamVersion):
+//
+//	az://<SECRET_NAME>?@version=<VERSION>
+//
 // NOTE: Since the Azure Key Vault client (`azsecrets.Client`) is explicitly bound to a specific
 // vault URL when instantiated, the dug-up secret is assumed to be present in the vault the client is bound to.
 // Spelunk expects just the secret name and optional version.
@@ -49,22 +58,256 @@ type SecretSourceAzure struct {
 	client *azsecrets.Client
 }
 
+// New creates a SecretSourceAzure: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(client *azsecrets.Client) *SecretSourceAzure {
+	return &SecretSourceAzure{
+		client: client,
+	}
+}
+
 // WithAzure enables the SecretSourceAzure.
 func WithAzure(client *azsecrets.Client) spelunk.SpelunkerOption {
-	return spelunk.WithSource(&SecretSourceAzure{
-		client: client,
-	})
+	return spelunk.WithSource(New(client))
 }
 
 const Type = "az"
 
-var _ types.SecretSource = (*SecretSourceAzure)(nil)
+var (
+	_ types.MetadataSource    = (*SecretSourceAzure)(nil)
+	_ types.LocationValidator = (*SecretSourceAzure)(nil)
+	_ types.ExistenceChecker  = (*SecretSourceAzure)(nil)
+	_ types.SecretLister      = (*SecretSourceAzure)(nil)
+	_ types.SecretWriter      = (*SecretSourceAzure)(nil)
+	_ types.ParamSource       = (*SecretSourceAzure)(nil)
+)
 
 func (s *SecretSourceAzure) Type() string {
 	return Type
 }
 
+// Params returns the parameters supported: types.ParamVersion, alternative to the `/<VERSION>` suffix.
+func (s *SecretSourceAzure) Params() []string {
+	return []string{types.ParamVersion}
+}
+
 func (s *SecretSourceAzure) DigUp(ctx context.Context, coord types.SecretCoord) (string, error) {
+	val, _, err := s.DigUpWithMetadata(ctx, coord)
+	return val, err
+}
+
+// DigUpWithMetadata returns the secret alongside its metadata: version, creation and expiry time,
+// content type, and tags (as extras).
+func (s *SecretSourceAzure) DigUpWithMetadata(
+	ctx context.Context,
+	coord types.SecretCoord,
+) (string, *types.SecretMetadata, error) {
+	secretName, version, err := parseLocation(coord)
+	if err != nil {
+		return "", nil, err
+	}
+
+	res, err := s.client.GetSecret(ctx, secretName, version, nil)
+	if err != nil {
+		return "", nil, wrapFetchError(coord, err)
+	}
+
+	if res.Value == nil {
+		return "", nil, fmt.Errorf(
+			"%w (%q): secret contains no data",
+			types.ErrSecretNotFound,
+			coord.Location,
+		)
+	}
+
+	return *res.Value, secretMetadata(res.Secret), nil
+}
+
+// Exists checks if the secret version exists by listing the properties of the versions of the secret,
+// without getting its value. If no version is given, the latest (i.e. most recently created) is checked.
+// Disabled versions are considered as not existing, as their value can't be retrieved.
+func (s *SecretSourceAzure) Exists(ctx context.Context, coord types.SecretCoord) (bool, error) {
+	secretName, version, err := parseLocation(coord)
+	if err != nil {
+		return false, err
+	}
+
+	var found *azsecrets.SecretProperties
+	pager := s.client.NewListSecretPropertiesVersionsPager(secretName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			if err = wrapFetchError(coord, err); errors.Is(err, types.ErrSecretNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		for _, props := range page.Value {
+			if props == nil || props.ID == nil {
+				continue
+			}
+			if len(version) > 0 {
+				if strings.EqualFold(props.ID.Version(), version) {
+					return isEnabled(props), nil
+				}
+			} else if found == nil || createdAfter(props, found) {
+				found = props
+			}
+		}
+	}
+	return found != nil && isEnabled(found), nil
+}
+
+// List lists the secrets whose name starts with the prefix (e.g. `az://app-`; `az:///` lists all secrets),
+// or, if the prefix is a secret name followed by `/` (e.g. `az://app-db/`), the enabled versions of that secret.
+// Only properties are listed, never values.
+func (s *SecretSourceAzure) List(ctx context.Context, prefix types.SecretCoord) ([]types.SecretCoord, error) {
+	location := strings.TrimPrefix(prefix.Location, "/")
+
+	// Versions of a secret
+	if secretName, isSecret := strings.CutSuffix(location, "/"); isSecret &&
+		latestSecretVersionShortNameRegexp.MatchString(secretName) {
+		var children []types.SecretCoord
+		pager := s.client.NewListSecretPropertiesVersionsPager(secretName, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				if err = wrapFetchError(prefix, err); errors.Is(err, types.ErrSecretNotFound) {
+					return nil, nil
+				}
+				return nil, err
+			}
+			for _, props := range page.Value {
+				if props != nil && props.ID != nil && isEnabled(props) {
+					children = append(children, types.SecretCoord{
+						Type:     prefix.Type,
+						Location: secretName + "/" + props.ID.Version(),
+					})
+				}
+			}
+		}
+		return children, nil
+	}
+
+	// Secrets by name prefix
+	var children []types.SecretCoord
+	pager := s.client.NewListSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, wrapFetchError(prefix, err)
+		}
+		for _, props := range page.Value {
+			if props == nil || props.ID == nil {
+				continue
+			}
+			if name := props.ID.Name(); strings.HasPrefix(name, location) {
+				children = append(children, types.SecretCoord{Type: prefix.Type, Location: name})
+			}
+		}
+	}
+	return children, nil
+}
+
+// isEnabled returns true unless the secret version is explicitly disabled.
+func isEnabled(props *azsecrets.SecretProperties) bool {
+	return props.Attributes == nil || props.Attributes.Enabled == nil || *props.Attributes.Enabled
+}
+
+// createdAfter returns true if the secret version a was created after b.
+func createdAfter(a, b *azsecrets.SecretProperties) bool {
+	if a.Attributes == nil || a.Attributes.Created == nil {
+		return false
+	}
+	if b.Attributes == nil || b.Attributes.Created == nil {
+		return true
+	}
+	return a.Attributes.Created.After(*b.Attributes.Created)
+}
+
+// secretMetadata extracts the metadata of secret.
+func secretMetadata(secret azsecrets.Secret) *types.SecretMetadata {
+	metadata := &types.SecretMetadata{}
+	if secret.ID != nil {
+		metadata.Version = secret.ID.Version()
+	}
+	if secret.Attributes != nil {
+		if secret.Attributes.Created != nil {
+			metadata.CreatedAt = *secret.Attributes.Created
+		}
+		if secret.Attributes.Expires != nil {
+			metadata.ExpiresAt = *secret.Attributes.Expires
+		}
+	}
+	if secret.ContentType != nil {
+		metadata.ContentType = *secret.ContentType
+	}
+	for k, v := range secret.Tags {
+		if v != nil {
+			metadata.SetExtra(k, *v)
+		}
+	}
+	return metadata
+}
+
+// Put writes the secret via `SetSecret`, creating it if it doesn't exist, or adding a new version otherwise.
+// As versions are immutable, the location can't point at a specific version.
+// Key Vault doesn't offer compare-and-set.
+func (s *SecretSourceAzure) Put(
+	ctx context.Context,
+	coord types.SecretCoord,
+	value string,
+	opts types.PutOptions,
+) error {
+	if len(opts.IfVersion) > 0 {
+		return fmt.Errorf("%w: Key Vault has no conditional writes", types.ErrCompareAndSetNotSupported)
+	}
+	secretName, err := parseUnversionedLocation(coord)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.SetSecret(ctx, secretName, azsecrets.SetSecretParameters{Value: &value}, nil)
+	if err != nil {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// Delete deletes the secret (and all its versions) via `DeleteSecret`: if the vault has soft-delete
+// enabled, it can be recovered until purged. The location can't point at a specific version.
+func (s *SecretSourceAzure) Delete(ctx context.Context, coord types.SecretCoord) error {
+	secretName, err := parseUnversionedLocation(coord)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteSecret(ctx, secretName, nil)
+	if err != nil && !isNotFound(err) {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// ValidateLocation checks that the location is a valid secret name, optionally followed by a version.
+func (s *SecretSourceAzure) ValidateLocation(coord types.SecretCoord) error {
+	_, _, err := parseLocation(coord)
+	return err
+}
+
+// parseLocation returns the secret name and version the location of coord points at,
+// or set via types.ParamVersion. The version is empty for the latest version.
+func parseLocation(coord types.SecretCoord) (string, string, error) {
+	version := coord.Param(types.ParamVersion)
+	if len(version) > 0 && !versionRegexp.MatchString(version) {
+		return "", "", fmt.Errorf(
+			"%w: expected a 32 characters hex version, got %q",
+			types.ErrInvalidLocation,
+			version,
+		)
+	}
+
 	// Strip trailing slash if present (often happens when the URI contains query parameters e.g. /?jp=$.password)
 	location := coord.Location
 	if len(location) > 0 && location[len(location)-1] == '/' {
@@ -75,51 +318,113 @@ func (s *SecretSourceAzure) DigUp(ctx context.Context, coord types.SecretCoord)
 	location = strings.TrimPrefix(location, "/")
 
 	// Enforce one of 2 possible regexp to validate the location format and extract parts
-	var secretName, version string
-
 	switch {
 	case secretVersionNameRegexp.MatchString(location):
+		if len(version) > 0 {
+			return "", "", fmt.Errorf(
+				"%w: the version is set by both location and parameter, got %q",
+				types.ErrInvalidLocation,
+				coord.Location,
+			)
+		}
 		matches := secretVersionNameRegexp.FindStringSubmatch(location)
-		secretName = matches[1]
-		version = matches[2]
+		return matches[1], matches[2], nil
 	case latestSecretVersionShortNameRegexp.MatchString(location):
 		matches := latestSecretVersionShortNameRegexp.FindStringSubmatch(location)
-		secretName = matches[1]
-		version = "" // API gets latest when version is empty
+		return matches[1], version, nil // API gets latest when version is empty
 	default:
-		return "", fmt.Errorf(
+		return "", "", fmt.Errorf(
 			"%w: expected <SECRET_NAME>[/<VERSION>], got %q",
 			types.ErrInvalidLocation,
 			coord.Location,
 		)
 	}
+}
 
-	res, err := s.client.GetSecret(ctx, secretName, version, nil)
+// parseUnversionedLocation returns the secret name the location of coord points at,
+// failing if it points at a specific version (e.g. to write it).
+func parseUnversionedLocation(coord types.SecretCoord) (string, error) {
+	secretName, version, err := parseLocation(coord)
 	if err != nil {
-		// Use azcore.ResponseError to accurately detect 404
-		if respErr, errMatched := errors.AsType[*azcore.ResponseError](
-			err,
-		); errMatched &&
-			respErr.StatusCode == 404 {
-			return "", fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
-		}
+		return "", err
+	}
+	if len(version) > 0 {
+		return "", fmt.Errorf(
+			"%w: versions are immutable, expected <SECRET_NAME>, got %q",
+			types.ErrInvalidLocation,
+			coord.Location,
+		)
+	}
+	return secretName, nil
+}
 
-		// Fallback to string matching for other cases
-		if strings.Contains(err.Error(), "SecretNotFound") ||
-			strings.Contains(err.Error(), "NotFoundException") {
-			return "", fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
-		}
+// wrapFetchError wraps a failure of the Key Vault API reading a secret, differentiating between not found
+// and other errors, and classifying the latter as transient, permission denied or unauthenticated if they are.
+func wrapFetchError(coord types.SecretCoord, err error) error {
+	return wrapAPIError(types.ErrCouldNotFetchSecret, coord, err)
+}
 
-		return "", fmt.Errorf("%w (%q): %w", types.ErrCouldNotFetchSecret, coord.Location, err)
+// wrapWriteError wraps a failure of the Key Vault API writing or deleting a secret, like wrapFetchError.
+func wrapWriteError(coord types.SecretCoord, err error) error {
+	return wrapAPIError(types.ErrCouldNotWriteSecret, coord, err)
+}
+
+// wrapAPIError wraps a failure of the Key Vault API in the given failure error, unless it's a not found.
+func wrapAPIError(failure error, coord types.SecretCoord, err error) error {
+	if isNotFound(err) {
+		return fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
 	}
 
-	if res.Value == nil {
-		return "", fmt.Errorf(
-			"%w (%q): secret contains no data",
-			types.ErrSecretNotFound,
+	class := classifyResponseError(err)
+	if isTransient(err) {
+		class = types.ErrTransient
+	}
+	if class != nil {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			failure,
 			coord.Location,
+			class,
+			err,
 		)
 	}
+	return fmt.Errorf("%w (%q): %w", failure, coord.Location, err)
+}
+
+// isNotFound returns true if err is a 404 response error, or otherwise reports a secret not found.
+func isNotFound(err error) bool {
+	// Use azcore.ResponseError to accurately detect 404
+	if respErr, errMatched := errors.AsType[*azcore.ResponseError](err); errMatched &&
+		respErr.StatusCode == http.StatusNotFound {
+		return true
+	}
 
-	return *res.Value, nil
+	// Fallback to string matching for other cases
+	return strings.Contains(err.Error(), "SecretNotFound") ||
+		strings.Contains(err.Error(), "NotFoundException")
+}
+
+// classifyResponseError returns types.ErrPermissionDenied or types.ErrUnauthenticated,
+// if err is a 403 or 401 response error respectively, or nil otherwise.
+func classifyResponseError(err error) error {
+	if respErr, errMatched := errors.AsType[*azcore.ResponseError](err); errMatched {
+		switch respErr.StatusCode {
+		case http.StatusForbidden:
+			return types.ErrPermissionDenied
+		case http.StatusUnauthorized:
+			return types.ErrUnauthenticated
+		}
+	}
+	return nil
+}
+
+// isTransient returns true if err is a throttling or server-side response error,
+// or a network error.
+func isTransient(err error) bool {
+	if respErr, errMatched := errors.AsType[*azcore.ResponseError](err); errMatched {
+		return respErr.StatusCode == http.StatusTooManyRequests ||
+			respErr.StatusCode >= http.StatusInternalServerError
+	}
+	_, errMatched := errors.AsType[net.Error](err)
+	return errMatched
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -33,6 +34,47 @@ const (
 	jsonSecretValue = `{"key":"value"}`
 )
 
+func TestSecretSourceAzure_ValidateLocation_Version(t *testing.T) {
+	s := azure.New(nil)
+	version := "0123456789abcdef0123456789ABCDEF"
+
+	tests := []struct {
+		name     string
+		coordStr string
+		errMatch error
+	}{
+		{
+			name:     "version via param",
+			coordStr: "az://my-secret?@version=" + version,
+		},
+		{
+			name:     "invalid version via param",
+			coordStr: "az://my-secret?@version=3",
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "version set twice",
+			coordStr: "az://my-secret/" + version + "?@version=" + version,
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+			if tt.errMatch == nil {
+				require.NoError(t, s.ValidateLocation(*coord))
+
+				// Versions can't be written
+				require.ErrorIs(t, s.Put(t.Context(), *coord, "val", types.PutOptions{}), types.ErrInvalidLocation)
+				return
+			}
+			require.ErrorIs(t, s.ValidateLocation(*coord), tt.errMatch)
+		})
+	}
+}
+
 func TestSecretSourceAzure_DigUp_Integration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -79,6 +121,11 @@ func TestSecretSourceAzure_DigUp_Integration(t *testing.T) {
 			coordStr: fmt.Sprintf("az:///%s/%s", plainSecretName, plainSecretVersion),
 			want:     plainSecretValue,
 		},
+		{
+			name:     "secret by name and version via param",
+			coordStr: fmt.Sprintf("az://%s?@version=%s", plainSecretName, plainSecretVersion),
+			want:     plainSecretValue,
+		},
 		{
 			name:     "secret by name via jp modifier",
 			coordStr: fmt.Sprintf("az://%s/?jp=$.key", jsonSecretName),
@@ -121,6 +168,156 @@ func TestSecretSourceAzure_DigUp_Integration(t *testing.T) {
 	}
 }
 
+func TestSecretSourceAzure_Exists_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	azClient, err := setupAzureTestContainer(t, ctx)
+	require.NoError(t, err)
+	plainSecretVersion := createTestSecrets(t, azClient)
+
+	spelunker := spelunk.NewSpelunker(azure.WithAzure(azClient))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     bool
+		errMatch error
+	}{
+		{
+			name:     "secret by name",
+			coordStr: fmt.Sprintf("az://%s", plainSecretName),
+			want:     true,
+		},
+		{
+			name:     "secret by name and version",
+			coordStr: fmt.Sprintf("az://%s/%s", plainSecretName, plainSecretVersion),
+			want:     true,
+		},
+		{
+			name:     "version that does not exist",
+			coordStr: fmt.Sprintf("az://%s/%s", plainSecretName, strings.Repeat("0", 32)),
+			want:     false,
+		},
+		{
+			name:     "secret that does not exist",
+			coordStr: "az://missing-secret",
+			want:     false,
+		},
+		{
+			name:     "invalid secret name format",
+			coordStr: "az://invalid_secret_name",
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.Exists(ctx, coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSecretSourceAzure_List_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	azClient, err := setupAzureTestContainer(t, ctx)
+	require.NoError(t, err)
+	plainSecretVersion := createTestSecrets(t, azClient)
+
+	spelunker := spelunk.NewSpelunker(azure.WithAzure(azClient))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     []string
+	}{
+		{
+			name:     "all secrets",
+			coordStr: "az:///",
+			want:     []string{jsonSecretName, plainSecretName},
+		},
+		{
+			name:     "name prefix",
+			coordStr: "az://my-s",
+			want:     []string{plainSecretName},
+		},
+		{
+			name:     "versions of a secret",
+			coordStr: fmt.Sprintf("az://%s/", plainSecretName),
+			want:     []string{plainSecretName + "/" + plainSecretVersion},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.List(ctx, coord)
+			require.NoError(t, err)
+
+			locations := []string{}
+			for _, child := range got {
+				locations = append(locations, child.Location)
+			}
+			require.Equal(t, tt.want, locations)
+		})
+	}
+}
+
+func TestSecretSourceAzure_PutDelete_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	azClient, err := setupAzureTestContainer(t, ctx)
+	require.NoError(t, err)
+	plainSecretVersion := createTestSecrets(t, azClient)
+
+	spelunker := spelunk.NewSpelunker(azure.WithAzure(azClient))
+	coord := func(coordStr string) *types.SecretCoord {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return coord
+	}
+
+	// Created, then a new version added
+	newSecret := coord("az://seeded-secret")
+	require.NoError(t, spelunker.Put(ctx, newSecret, "first", types.PutOptions{}))
+	require.NoError(t, spelunker.Put(ctx, newSecret, "second", types.PutOptions{}))
+	got, err := spelunker.DigUp(ctx, newSecret)
+	require.NoError(t, err)
+	require.Equal(t, "second", got)
+
+	err = spelunker.Put(ctx, coord(fmt.Sprintf("az://%s/%s", plainSecretName, plainSecretVersion)), "val", types.PutOptions{})
+	require.ErrorIs(t, err, types.ErrInvalidLocation)
+	err = spelunker.Put(ctx, newSecret, "third", types.PutOptions{IfVersion: "1"})
+	require.ErrorIs(t, err, types.ErrCompareAndSetNotSupported)
+
+	// Deleted, also when it doesn't exist anymore
+	require.NoError(t, spelunker.Delete(ctx, newSecret))
+	require.NoError(t, spelunker.Delete(ctx, coord("az://missing-secret")))
+	exists, err := spelunker.Exists(ctx, newSecret)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
 func createTestSecrets(t *testing.T, client *azsecrets.Client) string {
 	resp, err := client.SetSecret(
 		t.Context(),
@@ -23,17 +23,25 @@ type SecretSourceBitwarden struct {
 	client sdk.BitwardenClientInterface
 }
 
-// WithBitwarden enables the SecretSourceBitwarden.
-func WithBitwarden(client sdk.BitwardenClientInterface) spelunk.SpelunkerOption {
-	source := &SecretSourceBitwarden{
+// New creates a SecretSourceBitwarden: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(client sdk.BitwardenClientInterface) *SecretSourceBitwarden {
+	return &SecretSourceBitwarden{
 		client: client,
 	}
-	return spelunk.WithSource(source)
+}
+
+// WithBitwarden enables the SecretSourceBitwarden.
+func WithBitwarden(client sdk.BitwardenClientInterface) spelunk.SpelunkerOption {
+	return spelunk.WithSource(New(client))
 }
 
 const Type = "bw"
 
-var _ types.SecretSource = (*SecretSourceBitwarden)(nil)
+var (
+	_ types.LocationValidator = (*SecretSourceBitwarden)(nil)
+	_ types.SecretLister      = (*SecretSourceBitwarden)(nil)
+)
 
 func (s *SecretSourceBitwarden) Type() string {
 	return Type
@@ -43,6 +51,94 @@ func (s *SecretSourceBitwarden) DigUp(
 	_ context.Context,
 	coord types.SecretCoord,
 ) (string, error) {
+	secretID, err := parseLocation(coord)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := s.client.Secrets().Get(secretID)
+	if err != nil {
+		return "", wrapAPIError(types.ErrCouldNotFetchSecret, coord.Location, err)
+	}
+
+	return secret.Value, nil
+}
+
+// List lists the secrets of the organization whose ID (a UUID) is the prefix (e.g. `bw://ORGANIZATION_ID`),
+// as `bw://SECRET_ID`. Secret values are never returned.
+func (s *SecretSourceBitwarden) List(_ context.Context, prefix types.SecretCoord) ([]types.SecretCoord, error) {
+	organizationID := strings.Trim(prefix.Location, "/")
+	if err := uuid.Validate(organizationID); err != nil {
+		return nil, fmt.Errorf(
+			"%w: expected ORGANIZATION_ID to be a valid UUID, got %q",
+			types.ErrInvalidLocation,
+			prefix.Location,
+		)
+	}
+
+	res, err := s.client.Secrets().List(organizationID)
+	if err != nil {
+		return nil, wrapAPIError(types.ErrCouldNotFetchSecret, prefix.Location, err)
+	}
+
+	children := make([]types.SecretCoord, 0, len(res.Data))
+	for _, secret := range res.Data {
+		children = append(children, types.SecretCoord{Type: prefix.Type, Location: secret.ID})
+	}
+	return children, nil
+}
+
+// wrapAPIError wraps an error returned by the Bitwarden SDK into failure, classifying it.
+// The SDK reports every failure as a plain "API error: MESSAGE", where MESSAGE quotes the
+// HTTP status of the failed request, if any: that's the only thing to classify it by.
+func wrapAPIError(failure error, location string, err error) error {
+	msg := strings.ToLower(err.Error())
+	if containsAny(msg, "not found") {
+		return fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, location, err)
+	}
+
+	var class error
+	switch {
+	case containsAny(msg, "unauthorized", "access token", "invalid_grant", "invalid_client"):
+		class = types.ErrUnauthenticated
+	case containsAny(msg, "forbidden"):
+		class = types.ErrPermissionDenied
+	case containsAny(
+		msg,
+		"too many requests",
+		"internal server error",
+		"bad gateway",
+		"service unavailable",
+		"gateway timeout",
+		"error sending request",
+		"timed out",
+	):
+		class = types.ErrTransient
+	}
+	if class != nil {
+		return fmt.Errorf("%w (%q): %w: %w", failure, location, class, err)
+	}
+	return fmt.Errorf("%w (%q): %w", failure, location, err)
+}
+
+// containsAny returns true if s contains any of the substrings.
+func containsAny(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateLocation checks that the location is a valid secret ID (i.e. a UUIDv4).
+func (s *SecretSourceBitwarden) ValidateLocation(coord types.SecretCoord) error {
+	_, err := parseLocation(coord)
+	return err
+}
+
+// parseLocation returns the secret ID the location of coord points at.
+func parseLocation(coord types.SecretCoord) (string, error) {
 	secretID := strings.Trim(coord.Location, "/")
 	id, err := uuid.Parse(secretID)
 	if err != nil || id.Version() != 4 {
@@ -52,11 +148,5 @@ func (s *SecretSourceBitwarden) DigUp(
 			coord.Location,
 		)
 	}
-
-	secret, err := s.client.Secrets().Get(secretID)
-	if err != nil {
-		return "", fmt.Errorf("%w (%q): %w", types.ErrCouldNotFetchSecret, coord.Location, err)
-	}
-
-	return secret.Value, nil
+	return secretID, nil
 }
@@ -31,6 +31,19 @@ func (m *mockSecrets) Get(id string) (*sdk.SecretResponse, error) {
 	return nil, errors.New("secret not found in mock")
 }
 
+func (m *mockSecrets) List(organizationID string) (*sdk.SecretIdentifiersResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	res := &sdk.SecretIdentifiersResponse{}
+	for id, s := range m.secrets {
+		if s.OrganizationID == organizationID {
+			res.Data = append(res.Data, sdk.SecretIdentifierResponse{ID: id, Key: s.Key, OrganizationID: organizationID})
+		}
+	}
+	return res, nil
+}
+
 type mockBitwardenClient struct {
 	sdk.BitwardenClientInterface
 	secrets *mockSecrets
@@ -99,9 +112,9 @@ func TestSecretSourceBitwarden_DigUp(t *testing.T) {
 			errMatch: types.ErrInvalidLocation,
 		},
 		{
-			name:     "secret retrieval error",
+			name:     "secret not found",
 			coordStr: fmt.Sprintf("bw://%s", uuid.NewString()),
-			errMatch: types.ErrCouldNotFetchSecret,
+			errMatch: types.ErrSecretNotFound,
 		},
 	}
 
@@ -120,3 +133,90 @@ func TestSecretSourceBitwarden_DigUp(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretSourceBitwarden_DigUp_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		errMatch []error
+	}{
+		{
+			name:     "not found",
+			err:      errors.New("API error: Received error message from server: [404 Not Found] Resource not found."),
+			errMatch: []error{types.ErrSecretNotFound},
+		},
+		{
+			name:     "unauthorized",
+			err:      errors.New("API error: Received error message from server: [401 Unauthorized]"),
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrUnauthenticated},
+		},
+		{
+			name:     "forbidden",
+			err:      errors.New("API error: Received error message from server: [403 Forbidden]"),
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrPermissionDenied},
+		},
+		{
+			name:     "rate limited",
+			err:      errors.New("API error: Received error message from server: [429 Too Many Requests]"),
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrTransient},
+		},
+		{
+			name:     "unclassified",
+			err:      errors.New("API error: unknown"),
+			errMatch: []error{types.ErrCouldNotFetchSecret},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockBitwardenClient{secrets: &mockSecrets{err: tt.err}}
+			spelunker := spelunk.NewSpelunker(spelunkbw.WithBitwarden(mockClient))
+
+			coord, err := types.NewSecretCoord(fmt.Sprintf("bw://%s", uuid.NewString()))
+			require.NoError(t, err)
+			_, err = spelunker.DigUp(t.Context(), coord)
+			for _, match := range tt.errMatch {
+				require.ErrorIs(t, err, match)
+			}
+
+			// Listing fails the same way
+			coord, err = types.NewSecretCoord(fmt.Sprintf("bw://%s", uuid.NewString()))
+			require.NoError(t, err)
+			_, err = spelunker.List(t.Context(), coord)
+			for _, match := range tt.errMatch {
+				require.ErrorIs(t, err, match)
+			}
+		})
+	}
+}
+
+func TestSecretSourceBitwarden_List(t *testing.T) {
+	orgID, otherOrgID := uuid.NewString(), uuid.NewString()
+	secretIDs := []string{uuid.NewString(), uuid.NewString()}
+	mockClient := &mockBitwardenClient{
+		secrets: &mockSecrets{
+			secrets: map[string]*sdk.SecretResponse{
+				secretIDs[0]:     {Key: "db-password", OrganizationID: orgID},
+				secretIDs[1]:     {Key: "api-token", OrganizationID: orgID},
+				uuid.NewString(): {Key: "other", OrganizationID: otherOrgID},
+			},
+		},
+	}
+	spelunker := spelunk.NewSpelunker(spelunkbw.WithBitwarden(mockClient))
+
+	coord, err := types.NewSecretCoord(fmt.Sprintf("bw://%s", orgID))
+	require.NoError(t, err)
+	got, err := spelunker.List(t.Context(), coord)
+	require.NoError(t, err)
+
+	locations := []string{}
+	for _, child := range got {
+		locations = append(locations, child.Location)
+	}
+	require.ElementsMatch(t, secretIDs, locations)
+
+	coord, err = types.NewSecretCoord("bw://not-an-organization")
+	require.NoError(t, err)
+	_, err = spelunker.List(t.Context(), coord)
+	require.ErrorIs(t, err, types.ErrInvalidLocation)
+}
@@ -3,8 +3,10 @@ package gcp
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1"
 	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
@@ -24,6 +26,19 @@ var (
 	latestSecretVersionShortNameRegexp = regexp.MustCompile(
 		`^projects/(?:[a-z][-a-z0-9]{4,28}[a-z0-9]|\d{5,20})/secrets/([a-zA-Z0-9_-]{1,255})$`,
 	)
+
+	// versionRegexp matches the versions of a secret.
+	versionRegexp = regexp.MustCompile(`^(?:\d+|latest)$`)
+
+	// projectPrefixRegexp matches prefixes listing the secrets of a project.
+	projectPrefixRegexp = regexp.MustCompile(
+		`^(projects/(?:[a-z][-a-z0-9]{4,28}[a-z0-9]|\d{5,20}))(?:/secrets)?$`,
+	)
+
+	// secretPrefixRegexp matches prefixes listing the versions of a secret.
+	secretPrefixRegexp = regexp.MustCompile(
+		`^(projects/(?:[a-z][-a-z0-9]{4,28}[a-z0-9]|\d{5,20})/secrets/[a-zA-Z0-9_-]{1,255})(?:/versions)?$`,
+	)
 )
 
 // SecretSourceGCP digs up secrets from Google Cloud Secret Manager.
@@ -33,12 +48,16 @@ var (
 //	gcp://projects/<PROJECT_ID_OR_NUM>/secrets/<SECRET_NAME>
 //	gcp://projects/<PROJECT_ID_OR_NUM>/secrets/<SECRET_NAME>/versions/<VERSION>
 //
-// If the version is omitted, a "/versions/latest" suffix is appended.
+// If the version is omitted, a "/versions/latest" suffix is appended. Instead of the suffix,
+// the version can be set via the `version` parameter (see types.ParamVersion):
+//
+//	gcp://projects/<PROJECT_ID_OR_NUM>/secrets/<SECRET_NAME>?@version=<VERSION>
 //
 // Because GCP Secret Manager payloads are strictly binary (`[]byte`), this source explicitly
 // converts and returns the payload data as a base64-encoded string. It is up to the user
 // to decode it using the `?b64d` modifier (or handle it in their application) if plain text
 // or JSON parsing is required.
+// When dug-up via spelunk.Spelunker.DigUpBytes instead, the payload is returned as is.
 //
 // Expected format of `<PROJECT_ID_OR_NUM>` is documented at: https://google.aip.dev/cloud/2510.
 // Expected format of `<SECRET_NAME>` is documented at: https://cloud.google.com/security/products/secret-manager.
@@ -48,35 +67,284 @@ type SecretSourceGCP struct {
 	client *secretmanager.Client
 }
 
+// New creates a SecretSourceGCP: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(client *secretmanager.Client) *SecretSourceGCP {
+	return &SecretSourceGCP{
+		client: client,
+	}
+}
+
 // WithGCP enables the SecretSourceGCP.
 func WithGCP(client *secretmanager.Client) spelunk.SpelunkerOption {
-	return spelunk.WithSource(&SecretSourceGCP{
-		client: client,
-	})
+	return spelunk.WithSource(New(client))
 }
 
 const Type = "gcp"
 
-var _ types.SecretSource = (*SecretSourceGCP)(nil)
+var (
+	_ types.BytesSource       = (*SecretSourceGCP)(nil)
+	_ types.MetadataSource    = (*SecretSourceGCP)(nil)
+	_ types.LocationValidator = (*SecretSourceGCP)(nil)
+	_ types.ExistenceChecker  = (*SecretSourceGCP)(nil)
+	_ types.SecretLister      = (*SecretSourceGCP)(nil)
+	_ types.SecretWriter      = (*SecretSourceGCP)(nil)
+	_ types.ParamSource       = (*SecretSourceGCP)(nil)
+)
 
 func (s *SecretSourceGCP) Type() string {
 	return Type
 }
 
+// Params returns the parameters supported: types.ParamVersion, alternative to the `/versions/<VERSION>` suffix.
+func (s *SecretSourceGCP) Params() []string {
+	return []string{types.ParamVersion}
+}
+
 func (s *SecretSourceGCP) DigUp(ctx context.Context, coord types.SecretCoord) (string, error) {
+	payload, err := s.DigUpBytes(ctx, coord)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(payload), nil
+}
+
+// DigUpBytes returns the payload of the secret as is, without base64-encoding it.
+func (s *SecretSourceGCP) DigUpBytes(ctx context.Context, coord types.SecretCoord) ([]byte, error) {
+	res, err := s.accessSecretVersion(ctx, coord)
+	if err != nil {
+		return nil, err
+	}
+	return res.Payload.Data, nil
+}
+
+// DigUpWithMetadata returns the secret (base64-encoded, like DigUp) alongside its metadata:
+// the version accessed (i.e. `latest` is resolved), and its full resource name (as extra `name`).
+func (s *SecretSourceGCP) DigUpWithMetadata(
+	ctx context.Context,
+	coord types.SecretCoord,
+) (string, *types.SecretMetadata, error) {
+	res, err := s.accessSecretVersion(ctx, coord)
+	if err != nil {
+		return "", nil, err
+	}
+
+	metadata := &types.SecretMetadata{
+		Version: res.Name[strings.LastIndex(res.Name, "/")+1:],
+	}
+	metadata.SetExtra("name", res.Name)
+	return base64.StdEncoding.EncodeToString(res.Payload.Data), metadata, nil
+}
+
+// accessSecretVersion accesses the version of the secret pointed at by coord,
+// making sure it has a payload.
+func (s *SecretSourceGCP) accessSecretVersion(
+	ctx context.Context,
+	coord types.SecretCoord,
+) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	secretVersionName, err := parseLocation(coord)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve secret
+	res, err := s.client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+		Name: secretVersionName,
+	})
+	if err != nil {
+		return nil, wrapFetchError(coord, err)
+	}
+
+	// Extract and return payload, or error if missing
+	if res.Payload == nil || res.Payload.Data == nil {
+		return nil, fmt.Errorf(
+			"%w (%q): secret contains no data",
+			types.ErrSecretNotFound,
+			coord.Location,
+		)
+	}
+	return res, nil
+}
+
+// Exists checks if the secret version exists via `GetSecretVersion`, without accessing its payload.
+// Disabled and destroyed versions are considered as not existing, as their payload can't be accessed.
+func (s *SecretSourceGCP) Exists(ctx context.Context, coord types.SecretCoord) (bool, error) {
+	secretVersionName, err := parseLocation(coord)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.client.GetSecretVersion(ctx, &secretmanagerpb.GetSecretVersionRequest{
+		Name: secretVersionName,
+	})
+	if err != nil {
+		if err = wrapFetchError(coord, err); errors.Is(err, types.ErrSecretNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return res.State == secretmanagerpb.SecretVersion_ENABLED, nil
+}
+
+// List lists, for the prefix `projects/<PROJECT_ID_OR_NUM>`, the secrets of the project (via `ListSecrets`)
+// and, for the prefix `projects/<PROJECT_ID_OR_NUM>/secrets/<SECRET_NAME>`, the enabled versions of
+// the secret (via `ListSecretVersions`). No payload is accessed.
+func (s *SecretSourceGCP) List(ctx context.Context, prefix types.SecretCoord) ([]types.SecretCoord, error) {
+	location := strings.Trim(prefix.Location, "/")
+
+	var children []types.SecretCoord
+	switch {
+	case projectPrefixRegexp.MatchString(location):
+		parent := projectPrefixRegexp.FindStringSubmatch(location)[1]
+		it := s.client.ListSecrets(ctx, &secretmanagerpb.ListSecretsRequest{Parent: parent})
+		for secret, err := range it.All() {
+			if err != nil {
+				return nil, wrapFetchError(prefix, err)
+			}
+			children = append(children, types.SecretCoord{Type: prefix.Type, Location: secret.Name})
+		}
+	case secretPrefixRegexp.MatchString(location):
+		parent := secretPrefixRegexp.FindStringSubmatch(location)[1]
+		it := s.client.ListSecretVersions(ctx, &secretmanagerpb.ListSecretVersionsRequest{Parent: parent})
+		for version, err := range it.All() {
+			if err != nil {
+				if err = wrapFetchError(prefix, err); errors.Is(err, types.ErrSecretNotFound) {
+					return nil, nil
+				}
+				return nil, err
+			}
+			if version.State == secretmanagerpb.SecretVersion_ENABLED {
+				children = append(children, types.SecretCoord{Type: prefix.Type, Location: version.Name})
+			}
+		}
+	default:
+		return nil, fmt.Errorf(
+			"%w: expected 'projects/<PROJECT_ID_OR_NUM>[/secrets/<SECRET_NAME>]', got %q",
+			types.ErrInvalidLocation,
+			prefix.Location,
+		)
+	}
+	return children, nil
+}
+
+// Put adds a new version to the secret via `AddSecretVersion`, with value as its payload (as is: it will be
+// dug-up base64-encoded, like any other payload). If the secret doesn't exist, it's created via `CreateSecret`,
+// with automatic replication. As versions are immutable, the location can't point at a specific version.
+// Secret Manager doesn't offer compare-and-set when adding versions.
+func (s *SecretSourceGCP) Put(
+	ctx context.Context,
+	coord types.SecretCoord,
+	value string,
+	opts types.PutOptions,
+) error {
+	if len(opts.IfVersion) > 0 {
+		return fmt.Errorf("%w: Secret Manager has no conditional version adding", types.ErrCompareAndSetNotSupported)
+	}
+	secretVersionName, err := parseLocation(coord)
+	if err != nil {
+		return err
+	}
+	secretName, version, _ := strings.Cut(secretVersionName, "/versions/")
+	if version != "latest" {
+		return fmt.Errorf(
+			"%w: versions are immutable, expected 'projects/<PROJECT_ID_OR_NUM>/secrets/<SECRET_NAME>', got %q",
+			types.ErrInvalidLocation,
+			coord.Location,
+		)
+	}
+
+	addVersion := func() error {
+		_, err := s.client.AddSecretVersion(ctx, &secretmanagerpb.AddSecretVersionRequest{
+			Parent:  secretName,
+			Payload: &secretmanagerpb.SecretPayload{Data: []byte(value)},
+		})
+		return err
+	}
+	err = addVersion()
+	if status.Code(err) == codes.NotFound {
+		project, secretID, _ := strings.Cut(secretName, "/secrets/")
+		_, err = s.client.CreateSecret(ctx, &secretmanagerpb.CreateSecretRequest{
+			Parent:   project,
+			SecretId: secretID,
+			Secret: &secretmanagerpb.Secret{
+				Replication: &secretmanagerpb.Replication{
+					Replication: &secretmanagerpb.Replication_Automatic_{
+						Automatic: &secretmanagerpb.Replication_Automatic{},
+					},
+				},
+			},
+		})
+		if err == nil {
+			err = addVersion()
+		}
+	}
+	if err != nil {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// Delete deletes the secret (and all its versions) via `DeleteSecret` or, if the location points at
+// a specific version, destroys only that version via `DestroySecretVersion`.
+func (s *SecretSourceGCP) Delete(ctx context.Context, coord types.SecretCoord) error {
+	secretVersionName, err := parseLocation(coord)
+	if err != nil {
+		return err
+	}
+
+	if secretName, version, _ := strings.Cut(secretVersionName, "/versions/"); version == "latest" {
+		err = s.client.DeleteSecret(ctx, &secretmanagerpb.DeleteSecretRequest{Name: secretName})
+	} else {
+		_, err = s.client.DestroySecretVersion(ctx, &secretmanagerpb.DestroySecretVersionRequest{
+			Name: secretVersionName,
+		})
+	}
+	if err != nil && status.Code(err) != codes.NotFound {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// ValidateLocation checks that the location is a valid secret (version) resource name.
+func (s *SecretSourceGCP) ValidateLocation(coord types.SecretCoord) error {
+	_, err := parseLocation(coord)
+	return err
+}
+
+// parseLocation returns the full secret version resource name the location of coord points at
+// (i.e. `projects/<PROJECT_ID_OR_NUM>/secrets/<SECRET_NAME>/versions/<VERSION>`), honouring types.ParamVersion.
+func parseLocation(coord types.SecretCoord) (string, error) {
 	// Strip trailing slash if present (often happens when the URI contains query parameters e.g. `/?jp=$.password`)
 	location := coord.Location
 	if len(location) > 0 && location[len(location)-1] == '/' {
 		location = location[:len(location)-1]
 	}
 
+	version := coord.Param(types.ParamVersion)
+	if len(version) > 0 && !versionRegexp.MatchString(version) {
+		return "", fmt.Errorf(
+			"%w: expected a version number or 'latest', got %q",
+			types.ErrInvalidLocation,
+			version,
+		)
+	}
+
 	// Enforce one of 2 possible regexp to validate the location format
-	var secretVersionName string
 	switch {
 	case fullSecretVersionNameRegexp.MatchString(location):
-		secretVersionName = location
+		if len(version) > 0 {
+			return "", fmt.Errorf(
+				"%w: the version is set by both location and parameter, got %q",
+				types.ErrInvalidLocation,
+				coord.Location,
+			)
+		}
+		return location, nil
 	case latestSecretVersionShortNameRegexp.MatchString(location):
-		secretVersionName = fmt.Sprintf("%s/versions/latest", location)
+		if len(version) == 0 {
+			version = "latest"
+		}
+		return fmt.Sprintf("%s/versions/%s", location, version), nil
 	default:
 		return "", fmt.Errorf(
 			"%w: expected 'projects/<PROJECT_ID_OR_NUM>/secrets/<SECRET_NAME>[/versions/<VERSION>]', got %q",
@@ -84,25 +352,56 @@ func (s *SecretSourceGCP) DigUp(ctx context.Context, coord types.SecretCoord) (s
 			coord.Location,
 		)
 	}
+}
 
-	// Retrieve secret
-	res, err := s.client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
-		Name: secretVersionName,
-	})
-	if err != nil {
-		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
-			return "", fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
-		}
-		return "", fmt.Errorf("%w (%q): %w", types.ErrCouldNotFetchSecret, coord.Location, err)
-	}
+// wrapFetchError wraps a failure of the Secret Manager API reading a secret, differentiating between
+// not found and other errors, and classifying the latter by their gRPC status code.
+func wrapFetchError(coord types.SecretCoord, err error) error {
+	return wrapAPIError(types.ErrCouldNotFetchSecret, coord, err)
+}
 
-	// Extract and return payload encoded in base64, or error if missing
-	if res.Payload == nil || res.Payload.Data == nil {
-		return "", fmt.Errorf(
-			"%w (%q): secret contains no data",
-			types.ErrSecretNotFound,
+// wrapWriteError wraps a failure of the Secret Manager API writing or deleting a secret, like
+// wrapFetchError: a secret created concurrently (i.e. `AlreadyExists`) is classified as a version conflict.
+func wrapWriteError(coord types.SecretCoord, err error) error {
+	if status.Code(err) == codes.AlreadyExists {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			types.ErrCouldNotWriteSecret,
 			coord.Location,
+			types.ErrVersionConflict,
+			err,
 		)
 	}
-	return base64.StdEncoding.EncodeToString(res.Payload.Data), nil
+	return wrapAPIError(types.ErrCouldNotWriteSecret, coord, err)
+}
+
+// wrapAPIError wraps a failure of the Secret Manager API in the given failure error, unless it's a not found.
+func wrapAPIError(failure error, coord types.SecretCoord, err error) error {
+	if st, ok := status.FromError(err); ok {
+		var class error
+		switch st.Code() {
+		case codes.NotFound:
+			return fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
+		case codes.PermissionDenied:
+			class = types.ErrPermissionDenied
+		case codes.Unauthenticated:
+			class = types.ErrUnauthenticated
+		case codes.Unavailable,
+			codes.DeadlineExceeded,
+			codes.ResourceExhausted,
+			codes.Aborted,
+			codes.Internal:
+			class = types.ErrTransient
+		}
+		if class != nil {
+			return fmt.Errorf(
+				"%w (%q): %w: %w",
+				failure,
+				coord.Location,
+				class,
+				err,
+			)
+		}
+	}
+	return fmt.Errorf("%w (%q): %w", failure, coord.Location, err)
 }
@@ -84,6 +84,16 @@ func TestSecretSourceGCP_DigUp_Integration(t *testing.T) {
 			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s/versions/1", projectID, secretName),
 			want:     base64.StdEncoding.EncodeToString([]byte(secretValue)),
 		},
+		{
+			name:     "valid secret specific version via param",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s?@version=1", projectID, secretName),
+			want:     base64.StdEncoding.EncodeToString([]byte(secretValue)),
+		},
+		{
+			name:     "secret version not found via param",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s?@version=99", projectID, secretName),
+			errMatch: types.ErrSecretNotFound,
+		},
 		{
 			name: "valid secret specific latest version",
 			coordStr: fmt.Sprintf(
@@ -160,6 +170,264 @@ func TestSecretSourceGCP_DigUp_Integration(t *testing.T) {
 			require.Equal(t, tt.want, got)
 		})
 	}
+
+	t.Run("valid secret as bytes", func(t *testing.T) {
+		coord, err := types.NewSecretCoord(
+			fmt.Sprintf("gcp://projects/%s/secrets/%s", projectID, secretName),
+		)
+		require.NoError(t, err)
+
+		got, err := spelunker.DigUpBytes(t.Context(), coord)
+		require.NoError(t, err)
+		require.Equal(t, []byte(secretValue), got)
+	})
+}
+
+func TestSecretSourceGCP_Exists_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	client, err := setupGCPTestContainer(t)
+	require.NoError(t, err)
+	createTestSecrets(t, client)
+
+	spelunker := spelunk.NewSpelunker(gcp.WithGCP(client))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     bool
+		errMatch error
+	}{
+		{
+			name:     "secret latest",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s", projectID, secretName),
+			want:     true,
+		},
+		{
+			name:     "secret specific version",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s/versions/1", projectID, secretName),
+			want:     true,
+		},
+		{
+			name:     "version that does not exist",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s/versions/99", projectID, secretName),
+			want:     false,
+		},
+		{
+			name:     "secret that does not exist",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/missing-secret", projectID),
+			want:     false,
+		},
+		{
+			name:     "specific version via param",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s?@version=1", projectID, secretName),
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "invalid location",
+			coordStr: "gcp://projects/p/secrets/s",
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.Exists(t.Context(), coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSecretSourceGCP_List_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	client, err := setupGCPTestContainer(t)
+	require.NoError(t, err)
+	createTestSecrets(t, client)
+
+	spelunker := spelunk.NewSpelunker(gcp.WithGCP(client))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     []string
+		errMatch error
+	}{
+		{
+			name:     "project",
+			coordStr: fmt.Sprintf("gcp://projects/%s/", projectID),
+			want: []string{
+				fmt.Sprintf("projects/%s/secrets/%s", projectID, underscoreSecretName),
+				fmt.Sprintf("projects/%s/secrets/%s", projectID, jsonSecretName),
+				fmt.Sprintf("projects/%s/secrets/%s", projectID, secretName),
+			},
+		},
+		{
+			name:     "secret",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s", projectID, secretName),
+			want:     []string{fmt.Sprintf("projects/%s/secrets/%s/versions/1", projectID, secretName)},
+		},
+		{
+			name:     "secret that does not exist",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/missing-secret", projectID),
+			want:     []string{},
+		},
+		{
+			name:     "invalid location",
+			coordStr: "gcp://projects/p",
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.List(t.Context(), coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+
+			locations := []string{}
+			for _, child := range got {
+				locations = append(locations, child.Location)
+			}
+			require.Equal(t, tt.want, locations)
+		})
+	}
+}
+
+func TestSecretSourceGCP_Put_Parsing(t *testing.T) {
+	s := gcp.New(nil)
+
+	tests := []struct {
+		name     string
+		coordStr string
+		opts     types.PutOptions
+		errMatch error
+	}{
+		{
+			name:     "specific version",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s/versions/1", projectID, secretName),
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "invalid location",
+			coordStr: "gcp://projects/p/secrets/s",
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "compare-and-set",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s", projectID, secretName),
+			opts:     types.PutOptions{IfVersion: "1"},
+			errMatch: types.ErrCompareAndSetNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+			require.ErrorIs(t, s.Put(t.Context(), *coord, "val", tt.opts), tt.errMatch)
+		})
+	}
+}
+
+func TestSecretSourceGCP_ValidateLocation_Version(t *testing.T) {
+	s := gcp.New(nil)
+
+	tests := []struct {
+		name     string
+		coordStr string
+		errMatch error
+	}{
+		{
+			name:     "version via param",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s?@version=3", projectID, secretName),
+		},
+		{
+			name:     "latest version via param",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s/?@version=latest", projectID, secretName),
+		},
+		{
+			name:     "invalid version via param",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s?@version=v3", projectID, secretName),
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "version set twice",
+			coordStr: fmt.Sprintf("gcp://projects/%s/secrets/%s/versions/3?@version=3", projectID, secretName),
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+			if tt.errMatch == nil {
+				require.NoError(t, s.ValidateLocation(*coord))
+				return
+			}
+			require.ErrorIs(t, s.ValidateLocation(*coord), tt.errMatch)
+		})
+	}
+}
+
+func TestSecretSourceGCP_PutDelete_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	client, err := setupGCPTestContainer(t)
+	require.NoError(t, err)
+	createTestSecrets(t, client)
+
+	spelunker := spelunk.NewSpelunker(gcp.WithGCP(client))
+	coord := func(coordStr string) *types.SecretCoord {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return coord
+	}
+
+	// Created, then a new version added
+	newSecret := coord(fmt.Sprintf("gcp://projects/%s/secrets/seeded-secret", projectID))
+	require.NoError(t, spelunker.Put(t.Context(), newSecret, "first", types.PutOptions{}))
+	require.NoError(t, spelunker.Put(t.Context(), newSecret, "second", types.PutOptions{}))
+	got, err := spelunker.DigUpBytes(t.Context(), newSecret)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(got))
+	got, err = spelunker.DigUpBytes(t.Context(), coord(newSecret.URI()+"/versions/1"))
+	require.NoError(t, err)
+	require.Equal(t, "first", string(got))
+
+	// Destroying a version, then deleting the whole secret (also when it doesn't exist anymore)
+	firstVersion := coord(newSecret.URI() + "/versions/1")
+	require.NoError(t, spelunker.Delete(t.Context(), firstVersion))
+	exists, err := spelunker.Exists(t.Context(), firstVersion)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, spelunker.Delete(t.Context(), newSecret))
+	require.NoError(t, spelunker.Delete(t.Context(), newSecret))
+	exists, err = spelunker.Exists(t.Context(), newSecret)
+	require.NoError(t, err)
+	require.False(t, exists)
 }
 
 func createTestSecrets(t *testing.T, client *secretmanager.Client) {
@@ -2,7 +2,9 @@ package keeper
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 
@@ -31,16 +33,25 @@ type SecretSourceKeeper struct {
 	client *ksm.SecretsManager
 }
 
+// New creates a SecretSourceKeeper: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(client *ksm.SecretsManager) *SecretSourceKeeper {
+	return &SecretSourceKeeper{
+		client: client,
+	}
+}
+
 // WithKeeper enables the SecretSourceKeeper.
 func WithKeeper(client *ksm.SecretsManager) spelunk.SpelunkerOption {
-	return spelunk.WithSource(&SecretSourceKeeper{
-		client: client,
-	})
+	return spelunk.WithSource(New(client))
 }
 
 const Type = "kp"
 
-var _ types.SecretSource = (*SecretSourceKeeper)(nil)
+var (
+	_ types.LocationValidator = (*SecretSourceKeeper)(nil)
+	_ types.SecretLister      = (*SecretSourceKeeper)(nil)
+)
 
 func (s *SecretSourceKeeper) Type() string {
 	return Type
@@ -50,21 +61,14 @@ func (s *SecretSourceKeeper) DigUp(
 	_ context.Context,
 	coord types.SecretCoord,
 ) (string, error) {
-	matches := locationRegex.FindStringSubmatch(coord.Location)
-	if matches == nil {
-		return "", fmt.Errorf(
-			"%w: expected a valid 22-character base64url RECORD UID optionally followed by /FIELD, got %q",
-			types.ErrInvalidLocation,
-			coord.Location,
-		)
+	recordUID, field, err := parseLocation(coord)
+	if err != nil {
+		return "", err
 	}
 
-	recordUID := matches[locationRegex.SubexpIndex("recordUID")]
-	field := matches[locationRegex.SubexpIndex("field")]
-
 	records, err := s.client.GetSecrets([]string{recordUID})
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, err)
+		return "", wrapAPIError(types.ErrCouldNotFetchSecret, coord.Location, err)
 	}
 
 	if len(records) == 0 {
@@ -106,3 +110,112 @@ func (s *SecretSourceKeeper) DigUp(
 		coord.Location,
 	)
 }
+
+// List lists, for the empty prefix (i.e. `kp:///`), the records shared with the application (i.e. `RECORD_UID/`)
+// and, for the prefix `RECORD_UID`, the fields of the record that can be dug-up: "title", "notes" (if any),
+// "password" (if any) and fields with a label. Field values are never returned.
+func (s *SecretSourceKeeper) List(_ context.Context, prefix types.SecretCoord) ([]types.SecretCoord, error) {
+	location := strings.Trim(prefix.Location, "/")
+
+	// All records
+	if len(location) == 0 {
+		records, err := s.client.GetSecrets(nil)
+		if err != nil {
+			return nil, wrapAPIError(types.ErrCouldNotFetchSecret, prefix.Location, err)
+		}
+		children := make([]types.SecretCoord, 0, len(records))
+		for _, record := range records {
+			children = append(children, types.SecretCoord{Type: prefix.Type, Location: record.Uid + "/"})
+		}
+		return children, nil
+	}
+
+	// Fields of a record
+	recordUID, field, err := parseLocation(types.SecretCoord{Location: location})
+	if err != nil || len(field) > 0 {
+		return nil, fmt.Errorf(
+			"%w: expected an empty prefix or a valid 22-character base64url RECORD UID, got %q",
+			types.ErrInvalidLocation,
+			prefix.Location,
+		)
+	}
+	records, err := s.client.GetSecrets([]string{recordUID})
+	if err != nil {
+		return nil, wrapAPIError(types.ErrCouldNotFetchSecret, prefix.Location, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	fields := []string{"title"}
+	if _, found := records[0].RecordDict["notes"]; found {
+		fields = append(fields, "notes")
+	}
+	for _, f := range records[0].GetFieldsBySection(ksm.FieldSectionBoth) {
+		fieldMap, ok := f.(map[string]any)
+		if !ok {
+			continue
+		}
+		if label, _ := fieldMap["label"].(string); len(label) > 0 {
+			fields = append(fields, label)
+		} else if fieldType, _ := fieldMap["type"].(string); fieldType == "password" {
+			fields = append(fields, "password")
+		}
+	}
+
+	children := make([]types.SecretCoord, 0, len(fields))
+	for _, field := range fields {
+		children = append(children, types.SecretCoord{Type: prefix.Type, Location: recordUID + "/" + field})
+	}
+	return children, nil
+}
+
+// wrapAPIError wraps an error returned by the Keeper SDK into failure, classifying it.
+func wrapAPIError(failure error, location string, err error) error {
+	var class error
+	if httpErr, errMatched := errors.AsType[*ksm.KeeperHTTPError](err); errMatched {
+		switch {
+		case httpErr.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, location, err)
+		case httpErr.StatusCode == http.StatusUnauthorized:
+			class = types.ErrUnauthenticated
+		case httpErr.StatusCode == http.StatusForbidden:
+			// Keeper answers 403 also for unknown or expired client credentials
+			class = types.ErrPermissionDenied
+			if httpErr.ResultCode == "invalid_client" || httpErr.ResultCode == "invalid_client_version" {
+				class = types.ErrUnauthenticated
+			}
+		case httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode >= http.StatusInternalServerError ||
+			httpErr.ResultCode == "throttled":
+			class = types.ErrTransient
+		}
+	} else if strings.HasPrefix(err.Error(), "error during POST request") {
+		// The SDK flattens network failures into a plain error
+		class = types.ErrTransient
+	}
+	if class != nil {
+		return fmt.Errorf("%w (%q): %w: %w", failure, location, class, err)
+	}
+	return fmt.Errorf("%w (%q): %w", failure, location, err)
+}
+
+// ValidateLocation checks that the location is a valid record UID, optionally followed by a field.
+func (s *SecretSourceKeeper) ValidateLocation(coord types.SecretCoord) error {
+	_, _, err := parseLocation(coord)
+	return err
+}
+
+// parseLocation returns the record UID and the (optional) field the location of coord points at.
+func parseLocation(coord types.SecretCoord) (string, string, error) {
+	matches := locationRegex.FindStringSubmatch(coord.Location)
+	if matches == nil {
+		return "", "", fmt.Errorf(
+			"%w: expected a valid 22-character base64url RECORD UID optionally followed by /FIELD, got %q",
+			types.ErrInvalidLocation,
+			coord.Location,
+		)
+	}
+
+	return matches[locationRegex.SubexpIndex("recordUID")], matches[locationRegex.SubexpIndex("field")], nil
+}
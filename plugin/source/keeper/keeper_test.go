@@ -2,6 +2,9 @@ package keeper_test
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/detro/spelunk/plugin/modifier/jsonpath/v2"
@@ -94,6 +97,140 @@ func TestSecretSourceKeeper_DigUp_Parsing(t *testing.T) {
 
 			_, err = spelunker.DigUp(context.Background(), coord)
 			require.ErrorIs(t, err, tt.errMatch)
+
+			// Validation is offline: only invalid locations fail
+			err = spelunker.Validate(coord)
+			if tt.errMatch == types.ErrInvalidLocation {
+				require.ErrorIs(t, err, types.ErrInvalidLocation)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// roundTripperFunc implements http.RoundTripper for testing, answering requests with a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestSecretSourceKeeper_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		errMatch []error
+	}{
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			body:     `{"result_code":"not_found","message":"record not found"}`,
+			errMatch: []error{types.ErrSecretNotFound},
+		},
+		{
+			name:     "invalid client",
+			status:   http.StatusForbidden,
+			body:     `{"result_code":"invalid_client","message":"invalid client"}`,
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrUnauthenticated},
+		},
+		{
+			name:     "access denied",
+			status:   http.StatusForbidden,
+			body:     `{"result_code":"access_denied","message":"access denied"}`,
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrPermissionDenied},
+		},
+		{
+			name:     "throttled",
+			status:   http.StatusTooManyRequests,
+			body:     `{"result_code":"throttled","message":"too many requests"}`,
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrTransient},
+		},
+		{
+			name:     "server error",
+			status:   http.StatusBadGateway,
+			body:     `bad gateway`,
+			errMatch: []error{types.ErrCouldNotFetchSecret, types.ErrTransient},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The SDK lets tests replace its HTTP transport through its context
+			ksmCtx := &ksm.Context{Transport: roundTripperFunc(func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: tt.status,
+					Status:     http.StatusText(tt.status),
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader(tt.body)),
+				}, nil
+			})}
+			client := ksm.NewSecretsManager(&ksm.ClientOptions{
+				Token:  "US:dummy-token",
+				Config: ksm.NewMemoryKeyValueStorage(),
+			}, &ksmCtx)
+			spelunker := spelunk.NewSpelunker(spelunkkeeper.WithKeeper(client))
+
+			coord, err := types.NewSecretCoord("kp://abcdefghijklmnopqrstuv/password")
+			require.NoError(t, err)
+			_, err = spelunker.DigUp(t.Context(), coord)
+			for _, match := range tt.errMatch {
+				require.ErrorIs(t, err, match)
+			}
+
+			// Listing fails the same way
+			coord, err = types.NewSecretCoord("kp:///")
+			require.NoError(t, err)
+			_, err = spelunker.List(t.Context(), coord)
+			for _, match := range tt.errMatch {
+				require.ErrorIs(t, err, match)
+			}
+		})
+	}
+}
+
+func TestSecretSourceKeeper_List_Parsing(t *testing.T) {
+	dummyClient := ksm.NewSecretsManager(&ksm.ClientOptions{
+		Token:  "US:dummy-token",
+		Config: ksm.NewMemoryKeyValueStorage(),
+	})
+	s := spelunkkeeper.New(dummyClient)
+
+	tests := []struct {
+		name     string
+		coordStr string
+		errMatch error
+	}{
+		{
+			name:     "all records (attempts fetch)",
+			coordStr: "kp:///",
+			errMatch: types.ErrCouldNotFetchSecret,
+		},
+		{
+			name:     "fields of a record (attempts fetch)",
+			coordStr: "kp://abcdefghijklmnopqrstuv/",
+			errMatch: types.ErrCouldNotFetchSecret,
+		},
+		{
+			name:     "invalid location (record field)",
+			coordStr: "kp://abcdefghijklmnopqrstuv/password",
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "invalid location (UID too short)",
+			coordStr: "kp://123456789012345678901",
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			_, err = s.List(t.Context(), *coord)
+			require.ErrorIs(t, err, tt.errMatch)
 		})
 	}
 }
@@ -3,14 +3,21 @@ package kubernetes
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"maps"
+	"net"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/detro/spelunk/v2"
 	"github.com/detro/spelunk/v2/types"
+	corev1api "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
@@ -38,33 +45,373 @@ const (
 // When `/KEY` is appended, Spelunk extracts the specific value in the secret's data map.
 // Otherwise, if it ends with `/`, it returns the whole secret's data key-value map as JSON.
 //
+// Kubernetes keeps no past versions of secrets: the `version` parameter (see types.ParamVersion)
+// pins the `resourceVersion` the secret must be at, and it's considered as not found otherwise.
+//
+//	k8s://NAMESPACE/NAME/KEY?@version=<RESOURCE_VERSION>
+//
 // This types.SecretSource is a plug-in to spelunker.Spelunker and must be enabled explicitly.
 type SecretSourceKubernetes struct {
 	k8sClient corev1.SecretsGetter
 }
 
-// WithKubernetes enables the SecretSourceKubernetes.
-func WithKubernetes(k8sClient corev1.SecretsGetter) spelunk.SpelunkerOption {
-	source := &SecretSourceKubernetes{
+// New creates a SecretSourceKubernetes: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(k8sClient corev1.SecretsGetter) *SecretSourceKubernetes {
+	return &SecretSourceKubernetes{
 		k8sClient,
 	}
-	return spelunk.WithSource(source)
 }
 
-var _ types.SecretSource = (*SecretSourceKubernetes)(nil)
+// WithKubernetes enables the SecretSourceKubernetes.
+func WithKubernetes(k8sClient corev1.SecretsGetter) spelunk.SpelunkerOption {
+	return spelunk.WithSource(New(k8sClient))
+}
+
+var (
+	_ types.WatchableSource   = (*SecretSourceKubernetes)(nil)
+	_ types.MetadataSource    = (*SecretSourceKubernetes)(nil)
+	_ types.LocationValidator = (*SecretSourceKubernetes)(nil)
+	_ types.ExistenceChecker  = (*SecretSourceKubernetes)(nil)
+	_ types.SecretLister      = (*SecretSourceKubernetes)(nil)
+	_ types.SecretWriter      = (*SecretSourceKubernetes)(nil)
+	_ types.ParamSource       = (*SecretSourceKubernetes)(nil)
+)
 
 func (s *SecretSourceKubernetes) Type() string {
 	return Type
 }
 
+// Params returns the parameters supported: types.ParamVersion, the `resourceVersion` to pin.
+func (s *SecretSourceKubernetes) Params() []string {
+	return []string{types.ParamVersion}
+}
+
 func (s *SecretSourceKubernetes) DigUp(
 	ctx context.Context,
 	coord types.SecretCoord,
 ) (string, error) {
-	parts := strings.Split(coord.Location, "/")
+	val, _, err := s.DigUpWithMetadata(ctx, coord)
+	return val, err
+}
+
+// DigUpWithMetadata returns the secret alongside its metadata: `resourceVersion` (as version),
+// `creationTimestamp`, and `type` and `uid` (as extras).
+func (s *SecretSourceKubernetes) DigUpWithMetadata(
+	ctx context.Context,
+	coord types.SecretCoord,
+) (string, *types.SecretMetadata, error) {
+	namespace, name, key, err := parseLocation(coord)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Retrieve
+	secret, err := s.k8sClient.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil, fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
+		}
+		return "", nil, wrapFetchError(coord, err)
+	}
+	if err := checkVersion(coord, secret); err != nil {
+		return "", nil, err
+	}
+
+	metadata := &types.SecretMetadata{
+		Version:   secret.ResourceVersion,
+		CreatedAt: secret.CreationTimestamp.Time,
+	}
+	metadata.SetExtra("type", string(secret.Type))
+	metadata.SetExtra("uid", string(secret.UID))
+
+	// No key requested: return the whole `Data` map
+	if len(key) == 0 {
+		// Convert map[string][]byte to map[string]string for JSON serialization
+		stringData := make(map[string]string, len(secret.Data))
+		for k, v := range secret.Data {
+			stringData[k] = string(v)
+		}
+		dataJsonBytes, err := json.Marshal(stringData)
+		if err != nil {
+			return "", nil, err
+		}
+		return string(dataJsonBytes), metadata, nil
+	}
+
+	if val, found := secret.Data[key]; found {
+		return string(val), metadata, nil
+	}
+
+	return "", nil, fmt.Errorf("%w (%q)", types.ErrSecretKeyNotFound, coord.Location)
+}
+
+// Exists checks if the Kubernetes Secret exists (at the `resourceVersion` pinned, if any),
+// and contains the key (if any), without reading any value in the secret's data map.
+func (s *SecretSourceKubernetes) Exists(ctx context.Context, coord types.SecretCoord) (bool, error) {
+	namespace, name, key, err := parseLocation(coord)
+	if err != nil {
+		return false, err
+	}
+
+	secret, err := s.k8sClient.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, wrapFetchError(coord, err)
+	}
+	if checkVersion(coord, secret) != nil {
+		return false, nil
+	}
+
+	if len(key) == 0 {
+		return true, nil
+	}
+	_, found := secret.Data[key]
+	return found, nil
+}
+
+// List lists what is available under the prefix `NAMESPACE[/NAME]` (note that, unlike when digging up,
+// the namespace is never implied): for a namespace, each Secret in it (i.e. `NAMESPACE/NAME/`),
+// followed by its keys (i.e. `NAMESPACE/NAME/KEY`); for a Secret, its keys.
+// Keys are only found in the secrets' data maps, so whole Secrets are fetched, values included:
+// those are never returned.
+func (s *SecretSourceKubernetes) List(
+	ctx context.Context,
+	prefix types.SecretCoord,
+) ([]types.SecretCoord, error) {
+	parts := strings.Split(strings.Trim(prefix.Location, "/"), "/")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf(
+			"%w: expected NAMESPACE or NAMESPACE/NAME, got %q",
+			types.ErrInvalidLocation,
+			prefix.Location,
+		)
+	}
+	for _, name := range parts {
+		if !isValidDNSSubdomain(name) {
+			return nil, fmt.Errorf(
+				"%w: %w: invalid name %q",
+				types.ErrInvalidLocation,
+				ErrSecretSourceKubernetesInvalidName,
+				name,
+			)
+		}
+	}
+	namespace := parts[0]
+
+	var secrets []corev1api.Secret
+	if len(parts) == 2 {
+		secret, err := s.k8sClient.Secrets(namespace).Get(ctx, parts[1], metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, wrapFetchError(prefix, err)
+		}
+		secrets = append(secrets, *secret)
+	} else {
+		list, err := s.k8sClient.Secrets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, wrapFetchError(prefix, err)
+		}
+		secrets = list.Items
+	}
+
+	var children []types.SecretCoord
+	for _, secret := range secrets {
+		secretLocation := namespace + "/" + secret.Name + "/"
+		if len(parts) == 1 {
+			children = append(children, types.SecretCoord{Type: prefix.Type, Location: secretLocation})
+		}
+		for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+			children = append(children, types.SecretCoord{Type: prefix.Type, Location: secretLocation + key})
+		}
+	}
+	return children, nil
+}
+
+// Put writes the Kubernetes Secret, creating it (as an Opaque secret) if it doesn't exist.
+// When `/KEY` is appended, only that key is set in the secret's data map. Otherwise, if it ends with `/`,
+// value must be a JSON object of strings, replacing the whole secret's data map.
+//
+// Updates are conditional on the `resourceVersion` just read, so concurrent writes are never lost:
+// PutOptions.IfVersion, if set, must match it (and the secret must exist), unless it's types.VersionNone:
+// the secret (or, when `/KEY` is appended, the key) must then not exist yet. The `version` parameter
+// (see types.ParamVersion) is not accepted: PutOptions.IfVersion is the way to write conditionally.
+func (s *SecretSourceKubernetes) Put(
+	ctx context.Context,
+	coord types.SecretCoord,
+	value string,
+	opts types.PutOptions,
+) error {
+	namespace, name, key, err := parseUnversionedLocation(coord)
+	if err != nil {
+		return err
+	}
+
+	// No key: the value replaces the whole `Data` map
+	var data map[string][]byte
+	if len(key) == 0 {
+		var stringData map[string]string
+		// The unmarshalling error is not wrapped, as it could contain part of the secret
+		if err := json.Unmarshal([]byte(value), &stringData); err != nil || stringData == nil {
+			return fmt.Errorf(
+				"%w: expected a JSON object of strings to write the whole secret %q",
+				types.ErrInvalidSecretValue,
+				coord.Location,
+			)
+		}
+		data = make(map[string][]byte, len(stringData))
+		for k, v := range stringData {
+			data[k] = []byte(v)
+		}
+	}
+
+	secrets := s.k8sClient.Secrets(namespace)
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		if len(opts.IfVersion) > 0 && opts.IfVersion != types.VersionNone {
+			return fmt.Errorf(
+				"%w (%q): %w: expected resourceVersion %q, but secret does not exist",
+				types.ErrCouldNotWriteSecret,
+				coord.Location,
+				types.ErrVersionConflict,
+				opts.IfVersion,
+			)
+		}
+		if data == nil {
+			data = map[string][]byte{key: []byte(value)}
+		}
+		_, err = secrets.Create(ctx, &corev1api.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Type:       corev1api.SecretTypeOpaque,
+			Data:       data,
+		}, metav1.CreateOptions{})
+	case err != nil:
+		return wrapFetchError(coord, err)
+	default:
+		if opts.IfVersion == types.VersionNone {
+			if _, keyFound := secret.Data[key]; len(key) == 0 || keyFound {
+				return fmt.Errorf(
+					"%w (%q): %w: already exists",
+					types.ErrCouldNotWriteSecret,
+					coord.Location,
+					types.ErrVersionConflict,
+				)
+			}
+		} else if len(opts.IfVersion) > 0 && opts.IfVersion != secret.ResourceVersion {
+			return fmt.Errorf(
+				"%w (%q): %w: expected resourceVersion %q, got %q",
+				types.ErrCouldNotWriteSecret,
+				coord.Location,
+				types.ErrVersionConflict,
+				opts.IfVersion,
+				secret.ResourceVersion,
+			)
+		}
+		if data == nil {
+			data = secret.Data
+			if data == nil {
+				data = make(map[string][]byte, 1)
+			}
+			data[key] = []byte(value)
+		}
+		secret.Data = data
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// Delete deletes the Kubernetes Secret or, when `/KEY` is appended, only that key in the secret's data map
+// (conditional on the `resourceVersion` just read). The `version` parameter is not accepted, like for Put.
+func (s *SecretSourceKubernetes) Delete(ctx context.Context, coord types.SecretCoord) error {
+	namespace, name, key, err := parseUnversionedLocation(coord)
+	if err != nil {
+		return err
+	}
+
+	secrets := s.k8sClient.Secrets(namespace)
+	if len(key) == 0 {
+		if err := secrets.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return wrapWriteError(coord, err)
+		}
+		return nil
+	}
 
-	// Take Location apart
-	var namespace, name, key string
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return wrapFetchError(coord, err)
+	}
+	if _, found := secret.Data[key]; !found {
+		return nil
+	}
+	delete(secret.Data, key)
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// Watch notifies every time the Kubernetes Secret pointed at by coord is added, modified or deleted,
+// via the Kubernetes watch API. The notifications stop when the API server ends the watch.
+func (s *SecretSourceKubernetes) Watch(
+	ctx context.Context,
+	coord types.SecretCoord,
+) (<-chan struct{}, error) {
+	namespace, name, _, err := parseLocation(coord)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.k8sClient.Secrets(namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+	if err != nil {
+		return nil, wrapFetchError(coord, err)
+	}
+
+	// Buffered, to coalesce notifications arriving while the previous is being handled
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		defer w.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-w.ResultChan():
+				if !ok || event.Type == watch.Error {
+					return
+				}
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// ValidateLocation checks that the location has a valid namespace, name and key.
+func (s *SecretSourceKubernetes) ValidateLocation(coord types.SecretCoord) error {
+	_, _, _, err := parseLocation(coord)
+	return err
+}
+
+// parseLocation takes the location of coord apart, and validates it.
+func parseLocation(coord types.SecretCoord) (namespace, name, key string, err error) {
+	parts := strings.Split(coord.Location, "/")
 	switch len(parts) {
 	case 1:
 		namespace = defaultNamespace
@@ -79,56 +426,120 @@ func (s *SecretSourceKubernetes) DigUp(
 		name = parts[1]
 		key = parts[2]
 	default:
-		return "", fmt.Errorf(
+		return "", "", "", fmt.Errorf(
 			"%w: expected NAMESPACE/NAME/KEY, NAME/KEY, NAMESPACE/NAME/ or NAME/, got %q",
 			types.ErrInvalidLocation,
 			coord.Location,
 		)
 	}
 
-	// Validate
 	if !isValidDNSSubdomain(namespace) {
-		return "", fmt.Errorf(
-			"%w: invalid namespace %q",
+		return "", "", "", fmt.Errorf(
+			"%w: %w: invalid namespace %q",
+			types.ErrInvalidLocation,
 			ErrSecretSourceKubernetesInvalidName,
 			namespace,
 		)
 	}
 	if !isValidDNSSubdomain(name) {
-		return "", fmt.Errorf(
-			"%w: invalid secret name %q",
+		return "", "", "", fmt.Errorf(
+			"%w: %w: invalid secret name %q",
+			types.ErrInvalidLocation,
 			ErrSecretSourceKubernetesInvalidName,
 			name,
 		)
 	}
-	// Retrieve
-	secret, err := s.k8sClient.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return "", fmt.Errorf("%w (%q): %w", types.ErrSecretNotFound, coord.Location, err)
-		}
-		return "", fmt.Errorf("%w (%q): %w", types.ErrCouldNotFetchSecret, coord.Location, err)
+	return namespace, name, key, nil
+}
+
+// parseUnversionedLocation takes the location of coord apart, like parseLocation,
+// failing if a version is pinned (see types.ParamVersion).
+func parseUnversionedLocation(coord types.SecretCoord) (namespace, name, key string, err error) {
+	if version := coord.Param(types.ParamVersion); len(version) > 0 {
+		return "", "", "", fmt.Errorf(
+			"%w: expected no version (see PutOptions.IfVersion), got %q (%q)",
+			types.ErrInvalidLocation,
+			version,
+			coord.Location,
+		)
 	}
+	return parseLocation(coord)
+}
 
-	// No key requested: return the whole `Data` map
-	if len(key) == 0 {
-		// Convert map[string][]byte to map[string]string for JSON serialization
-		stringData := make(map[string]string, len(secret.Data))
-		for k, v := range secret.Data {
-			stringData[k] = string(v)
-		}
-		dataJsonBytes, err := json.Marshal(stringData)
-		if err != nil {
-			return "", err
-		}
-		return string(dataJsonBytes), nil
+// checkVersion returns an error wrapping types.ErrSecretNotFound,
+// if secret is not at the `resourceVersion` pinned by coord (see types.ParamVersion).
+func checkVersion(coord types.SecretCoord, secret *corev1api.Secret) error {
+	version := coord.Param(types.ParamVersion)
+	if len(version) == 0 || version == secret.ResourceVersion {
+		return nil
 	}
+	return fmt.Errorf(
+		"%w (%q): version %q not found, the secret is at version %q",
+		types.ErrSecretNotFound,
+		coord.Location,
+		version,
+		secret.ResourceVersion,
+	)
+}
 
-	if val, found := secret.Data[key]; found {
-		return string(val), nil
+// wrapFetchError wraps a failure of the Kubernetes API reading a secret, classifying it as transient,
+// permission denied or unauthenticated if it is.
+func wrapFetchError(coord types.SecretCoord, err error) error {
+	return wrapAPIError(types.ErrCouldNotFetchSecret, coord, err)
+}
+
+// wrapWriteError wraps a failure of the Kubernetes API writing or deleting a secret: a conflict
+// (i.e. the secret changed, or was created, since it was read) is classified as a version conflict.
+func wrapWriteError(coord types.SecretCoord, err error) error {
+	if errors.IsConflict(err) || errors.IsAlreadyExists(err) {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			types.ErrCouldNotWriteSecret,
+			coord.Location,
+			types.ErrVersionConflict,
+			err,
+		)
+	}
+	return wrapAPIError(types.ErrCouldNotWriteSecret, coord, err)
+}
+
+// wrapAPIError wraps a failure of the Kubernetes API in the given failure error,
+// classifying it as transient, permission denied or unauthenticated if it is.
+func wrapAPIError(failure error, coord types.SecretCoord, err error) error {
+	var class error
+	switch {
+	case isTransient(err):
+		class = types.ErrTransient
+	case errors.IsForbidden(err):
+		class = types.ErrPermissionDenied
+	case errors.IsUnauthorized(err):
+		class = types.ErrUnauthenticated
 	}
+	if class != nil {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			failure,
+			coord.Location,
+			class,
+			err,
+		)
+	}
+	return fmt.Errorf("%w (%q): %w", failure, coord.Location, err)
+}
 
-	return "", fmt.Errorf("%w (%q)", types.ErrSecretKeyNotFound, coord.Location)
+// isTransient returns true if err is a throttling, timeout or server-side API error,
+// or a network error.
+func isTransient(err error) bool {
+	if errors.IsTooManyRequests(err) ||
+		errors.IsTimeout(err) ||
+		errors.IsServerTimeout(err) ||
+		errors.IsServiceUnavailable(err) ||
+		errors.IsInternalError(err) ||
+		errors.IsUnexpectedServerError(err) {
+		return true
+	}
+	var netErr net.Error
+	return stderrors.As(err, &netErr)
 }
 
 func isValidDNSSubdomain(s string) bool {
@@ -1,8 +1,10 @@
 package kubernetes_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/detro/spelunk/plugin/modifier/jsonpath/v2"
 	"github.com/detro/spelunk/plugin/source/kubernetes/v2"
@@ -12,8 +14,13 @@ import (
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/k3s"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -54,6 +61,10 @@ func TestSecretSourceKubernetes_DigUp_Parsing(t *testing.T) {
 
 			_, err = s.DigUp(t.Context(), *coord)
 			require.ErrorIs(t, err, tt.errMatch)
+
+			err = s.ValidateLocation(*coord)
+			require.ErrorIs(t, err, tt.errMatch)
+			require.ErrorIs(t, err, types.ErrInvalidLocation)
 		})
 	}
 }
@@ -214,3 +225,343 @@ func setupK3STestContainer(t *testing.T) (*typedcorev1.CoreV1Client, error) {
 
 	return k8sClient, err
 }
+
+func TestSecretSourceKubernetes_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	clientset := fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: secretNamespace},
+		Data:       map[string][]byte{secretKey: []byte("v1")},
+	})
+	spelunker := spelunk.NewSpelunker(
+		kubernetes.WithKubernetes(clientset.CoreV1()),
+	)
+
+	coord, err := types.NewSecretCoord(fmt.Sprintf("k8s://%s/%s/%s", secretNamespace, secretName, secretKey))
+	require.NoError(t, err)
+	// Long interval: changes are only noticed via the watch API
+	updates, err := spelunker.Watch(ctx, coord, spelunk.WithWatchInterval(time.Hour))
+	require.NoError(t, err)
+
+	next := func() spelunk.Update {
+		select {
+		case u := <-updates:
+			return u
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no update received")
+			return spelunk.Update{}
+		}
+	}
+	require.Equal(t, spelunk.Update{Value: "v1"}, next())
+
+	_, err = clientset.CoreV1().Secrets(secretNamespace).Update(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: secretNamespace},
+		Data:       map[string][]byte{secretKey: []byte("v2")},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.Equal(t, spelunk.Update{Value: "v2"}, next())
+
+	err = clientset.CoreV1().Secrets(secretNamespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.ErrorIs(t, next().Err, types.ErrSecretNotFound)
+}
+
+func TestSecretSourceKubernetes_DigUpWithMetadata(t *testing.T) {
+	created := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	clientset := fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              secretName,
+			Namespace:         secretNamespace,
+			ResourceVersion:   "42",
+			UID:               "1234-abcd",
+			CreationTimestamp: created,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{secretKey: []byte(secretValue)},
+	})
+
+	coord, err := types.NewSecretCoord(fmt.Sprintf("k8s://%s/%s/%s", secretNamespace, secretName, secretKey))
+	require.NoError(t, err)
+
+	got, metadata, err := kubernetes.New(clientset.CoreV1()).DigUpWithMetadata(t.Context(), *coord)
+	require.NoError(t, err)
+	require.Equal(t, secretValue, got)
+	require.Equal(t, "42", metadata.Version)
+	require.True(t, created.Time.Equal(metadata.CreatedAt))
+	require.Equal(t, map[string]string{"type": "Opaque", "uid": "1234-abcd"}, metadata.Extras)
+
+	// The version pinned must be the current one
+	coord.Params = map[string]string{types.ParamVersion: "42"}
+	got, _, err = kubernetes.New(clientset.CoreV1()).DigUpWithMetadata(t.Context(), *coord)
+	require.NoError(t, err)
+	require.Equal(t, secretValue, got)
+	coord.Params = map[string]string{types.ParamVersion: "41"}
+	_, _, err = kubernetes.New(clientset.CoreV1()).DigUpWithMetadata(t.Context(), *coord)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+}
+
+func TestSecretSourceKubernetes_DigUp_ErrorClassification(t *testing.T) {
+	gr := schema.GroupResource{Resource: "secrets"}
+	tests := []struct {
+		name      string
+		apiErr    error
+		wantClass types.ErrorClass
+	}{
+		{
+			name:      "forbidden",
+			apiErr:    apierrors.NewForbidden(gr, secretName, fmt.Errorf("rbac")),
+			wantClass: types.ErrorClassPermissionDenied,
+		},
+		{
+			name:      "unauthorized",
+			apiErr:    apierrors.NewUnauthorized("expired token"),
+			wantClass: types.ErrorClassUnauthenticated,
+		},
+		{
+			name:      "throttled",
+			apiErr:    apierrors.NewTooManyRequests("slow down", 1),
+			wantClass: types.ErrorClassTransient,
+		},
+		{
+			name:      "not found",
+			apiErr:    apierrors.NewNotFound(gr, secretName),
+			wantClass: types.ErrorClassNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset()
+			clientset.PrependReactor("get", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, tt.apiErr
+			})
+
+			coord, err := types.NewSecretCoord(fmt.Sprintf("k8s://%s/%s/%s", secretNamespace, secretName, secretKey))
+			require.NoError(t, err)
+
+			_, err = spelunk.NewSpelunker(kubernetes.WithKubernetes(clientset.CoreV1())).DigUp(t.Context(), coord)
+			require.Error(t, err)
+			require.Equal(t, tt.wantClass, types.ClassifyError(err))
+		})
+	}
+}
+
+func TestSecretSourceKubernetes_Exists(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: secretNamespace, ResourceVersion: "7"},
+		Data:       map[string][]byte{secretKey: []byte(secretValue)},
+	})
+	s := kubernetes.New(clientset.CoreV1())
+
+	tests := []struct {
+		name      string
+		coordStr  string
+		want      bool
+		wantClass types.ErrorClass
+	}{
+		{
+			name:     "secret",
+			coordStr: fmt.Sprintf("k8s://%s/%s/", secretNamespace, secretName),
+			want:     true,
+		},
+		{
+			name:     "secret key",
+			coordStr: fmt.Sprintf("k8s://%s/%s/%s", secretNamespace, secretName, secretKey),
+			want:     true,
+		},
+		{
+			name:     "secret key that does not exist",
+			coordStr: fmt.Sprintf("k8s://%s/%s/missing", secretNamespace, secretName),
+			want:     false,
+		},
+		{
+			name:     "secret at the version pinned",
+			coordStr: fmt.Sprintf("k8s://%s/%s/%s?@version=7", secretNamespace, secretName, secretKey),
+			want:     true,
+		},
+		{
+			name:     "secret at another version",
+			coordStr: fmt.Sprintf("k8s://%s/%s/%s?@version=6", secretNamespace, secretName, secretKey),
+			want:     false,
+		},
+		{
+			name:     "secret that does not exist",
+			coordStr: fmt.Sprintf("k8s://%s/missing/%s", secretNamespace, secretKey),
+			want:     false,
+		},
+		{
+			name:      "invalid location",
+			coordStr:  "k8s://Invalid_Name/key",
+			wantClass: types.ErrorClassInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := s.Exists(t.Context(), *coord)
+			if tt.wantClass != "" {
+				require.Equal(t, tt.wantClass, types.ClassifyError(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	clientset.PrependReactor("get", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, secretName, fmt.Errorf("rbac"))
+	})
+	coord, err := types.NewSecretCoord(fmt.Sprintf("k8s://%s/%s/", secretNamespace, secretName))
+	require.NoError(t, err)
+	_, err = s.Exists(t.Context(), *coord)
+	require.ErrorIs(t, err, types.ErrPermissionDenied)
+}
+
+func TestSecretSourceKubernetes_List(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: secretNamespace},
+			Data:       map[string][]byte{"user": []byte("app"), "password": []byte("s3cret")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: secretNamespace},
+			Data:       map[string][]byte{"token": []byte("t0ken")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other-namespace"},
+		},
+	)
+	spelunker := spelunk.NewSpelunker(kubernetes.WithKubernetes(clientset.CoreV1()))
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     []string
+		errMatch error
+	}{
+		{
+			name:     "namespace",
+			coordStr: fmt.Sprintf("k8s://%s/", secretNamespace),
+			want: []string{
+				secretNamespace + "/api/",
+				secretNamespace + "/api/token",
+				secretNamespace + "/db/",
+				secretNamespace + "/db/password",
+				secretNamespace + "/db/user",
+			},
+		},
+		{
+			name:     "secret",
+			coordStr: fmt.Sprintf("k8s://%s/db", secretNamespace),
+			want:     []string{secretNamespace + "/db/password", secretNamespace + "/db/user"},
+		},
+		{
+			name:     "secret that does not exist",
+			coordStr: fmt.Sprintf("k8s://%s/missing/", secretNamespace),
+			want:     []string{},
+		},
+		{
+			name:     "invalid location (too many parts)",
+			coordStr: fmt.Sprintf("k8s://%s/db/password", secretNamespace),
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "invalid location (invalid name)",
+			coordStr: "k8s://Invalid_Namespace/",
+			errMatch: kubernetes.ErrSecretSourceKubernetesInvalidName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.List(t.Context(), coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+
+			locations := []string{}
+			for _, child := range got {
+				locations = append(locations, child.Location)
+			}
+			require.Equal(t, tt.want, locations)
+		})
+	}
+}
+
+func TestSecretSourceKubernetes_PutDelete(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: secretNamespace, ResourceVersion: "1"},
+		Data:       map[string][]byte{secretKey: []byte(secretValue)},
+	})
+	spelunker := spelunk.NewSpelunker(kubernetes.WithKubernetes(clientset.CoreV1()))
+
+	coord := func(coordStr string) *types.SecretCoord {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return coord
+	}
+	digUp := func(coordStr string) string {
+		got, err := spelunker.DigUp(t.Context(), coord(coordStr))
+		require.NoError(t, err)
+		return got
+	}
+	secretCoordStr := fmt.Sprintf("k8s://%s/%s/", secretNamespace, secretName)
+
+	// Setting a key keeps all others
+	require.NoError(t, spelunker.Put(t.Context(), coord(secretCoordStr+"user"), "admin", types.PutOptions{IfVersion: "1"}))
+	require.JSONEq(t, fmt.Sprintf(`{%q: %q, "user": "admin"}`, secretKey, secretValue), digUp(secretCoordStr))
+
+	// The whole secret is replaced, or created
+	require.NoError(t, spelunker.Put(t.Context(), coord(secretCoordStr), `{"token": "t0k3n"}`, types.PutOptions{}))
+	require.JSONEq(t, `{"token": "t0k3n"}`, digUp(secretCoordStr))
+	require.NoError(t, spelunker.Put(t.Context(), coord("k8s://new-secret/token"), "t0k3n", types.PutOptions{}))
+	require.Equal(t, "t0k3n", digUp("k8s://default/new-secret/token"))
+
+	err := spelunker.Put(t.Context(), coord(secretCoordStr), `["not", "an", "object"]`, types.PutOptions{})
+	require.ErrorIs(t, err, types.ErrInvalidSecretValue)
+
+	// Compare-and-set
+	err = spelunker.Put(t.Context(), coord(secretCoordStr+"user"), "admin", types.PutOptions{IfVersion: "42"})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+
+	// Create-only, for whole secrets and for keys
+	err = spelunker.Put(t.Context(), coord(secretCoordStr), `{"token": "t0k3n"}`, types.PutOptions{IfVersion: types.VersionNone})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+	err = spelunker.Put(t.Context(), coord(secretCoordStr+"token"), "stale", types.PutOptions{IfVersion: types.VersionNone})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+	require.NoError(t, spelunker.Put(t.Context(), coord(secretCoordStr+"other"), "new", types.PutOptions{IfVersion: types.VersionNone}))
+	require.NoError(t, spelunker.Delete(t.Context(), coord(secretCoordStr+"other")))
+	require.NoError(t, spelunker.Put(t.Context(), coord("k8s://created/key"), "val", types.PutOptions{IfVersion: types.VersionNone}))
+	require.Equal(t, "val", digUp("k8s://default/created/key"))
+	err = spelunker.Put(t.Context(), coord("k8s://missing/key"), "val", types.PutOptions{IfVersion: "1"})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+	err = spelunker.Put(t.Context(), coord(secretCoordStr+"user?@version=1"), "admin", types.PutOptions{})
+	require.ErrorIs(t, err, types.ErrInvalidLocation)
+
+	// Deleting a key, then the whole secret
+	require.NoError(t, spelunker.Put(t.Context(), coord(secretCoordStr+"user"), "admin", types.PutOptions{}))
+	require.NoError(t, spelunker.Delete(t.Context(), coord(secretCoordStr+"token")))
+	require.JSONEq(t, `{"user": "admin"}`, digUp(secretCoordStr))
+	require.NoError(t, spelunker.Delete(t.Context(), coord(secretCoordStr)))
+	require.NoError(t, spelunker.Delete(t.Context(), coord(secretCoordStr)))
+	exists, err := spelunker.Exists(t.Context(), coord(secretCoordStr))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// Concurrent updates are reported by the API server as conflicts
+	clientset.PrependReactor("update", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, "new-secret", fmt.Errorf("modified"))
+	})
+	err = spelunker.Put(t.Context(), coord("k8s://new-secret/token"), "t0k3n", types.PutOptions{})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+	require.ErrorIs(t, err, types.ErrCouldNotWriteSecret)
+}
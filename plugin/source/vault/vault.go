@@ -3,8 +3,13 @@ package vault
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/detro/spelunk/v2"
 	"github.com/detro/spelunk/v2/types"
@@ -29,54 +34,84 @@ import (
 //	vault://<ENGINE_MOUNT>/data/<PATH/TO/SECRET>/KEY
 //	vault://<ENGINE_MOUNT>/data/<PATH/TO/SECRET>/
 //
+// A specific version of KV version 2 secrets is dug-up via the `version` parameter (see types.ParamVersion):
+//
+//	vault://<ENGINE_MOUNT>/data/<PATH/TO/SECRET>/KEY?@version=3
+//
 // This types.SecretSource is a plug-in to spelunker.Spelunker and must be enabled explicitly.
 type SecretSourceVault struct {
 	vaultClient *api.Client
 }
 
-// WithVault enables the SecretSourceVault.
-func WithVault(vaultClient *api.Client) spelunk.SpelunkerOption {
-	source := &SecretSourceVault{
+// New creates a SecretSourceVault: use it with spelunk.WithSourceAs,
+// to enable multiple instances under custom schemes.
+func New(vaultClient *api.Client) *SecretSourceVault {
+	return &SecretSourceVault{
 		vaultClient,
 	}
-	return spelunk.WithSource(source)
+}
+
+// WithVault enables the SecretSourceVault.
+func WithVault(vaultClient *api.Client) spelunk.SpelunkerOption {
+	return spelunk.WithSource(New(vaultClient))
 }
 
 const Type = "vault"
 
-var _ types.SecretSource = (*SecretSourceVault)(nil)
+var ErrSecretSourceVaultInvalidVersion = fmt.Errorf("invalid KV version 2 secret version")
+
+var (
+	_ types.MetadataSource    = (*SecretSourceVault)(nil)
+	_ types.SecretWriter      = (*SecretSourceVault)(nil)
+	_ types.LocationValidator = (*SecretSourceVault)(nil)
+	_ types.ExistenceChecker  = (*SecretSourceVault)(nil)
+	_ types.SecretLister      = (*SecretSourceVault)(nil)
+	_ types.ParamSource       = (*SecretSourceVault)(nil)
+)
 
 func (s *SecretSourceVault) Type() string {
 	return Type
 }
 
+// Params returns the parameters supported: types.ParamVersion, for KV version 2 secrets.
+func (s *SecretSourceVault) Params() []string {
+	return []string{types.ParamVersion}
+}
+
 func (s *SecretSourceVault) DigUp(
 	ctx context.Context,
 	coord types.SecretCoord,
 ) (string, error) {
-	parts := strings.Split(coord.Location, "/")
+	val, _, err := s.DigUpWithMetadata(ctx, coord)
+	return val, err
+}
 
-	if len(parts) < 3 {
-		return "", fmt.Errorf(
-			"%w: expected <MOUNT>/<PATH/TO/SECRET>/<KEY> or <MOUNT>/<PATH/TO/SECRET>/, got %q",
-			types.ErrInvalidLocation,
-			coord.Location,
-		)
+// DigUpWithMetadata returns the secret alongside its metadata: for KV version 2 secrets,
+// their version, creation and deletion time, and custom metadata (as extras).
+// For leased secrets (e.g. dynamic credentials), the lease expiry and ID.
+func (s *SecretSourceVault) DigUpWithMetadata(
+	ctx context.Context,
+	coord types.SecretCoord,
+) (string, *types.SecretMetadata, error) {
+	path, key, err := parseLocation(coord)
+	if err != nil {
+		return "", nil, err
+	}
+	version, err := parseVersion(coord, path)
+	if err != nil {
+		return "", nil, err
 	}
-
-	path := strings.Join(parts[:len(parts)-1], "/")
-	key := parts[len(parts)-1]
 
 	// Retrieve
-	secret, err := s.vaultClient.Logical().ReadWithContext(ctx, path)
+	secret, err := s.read(ctx, coord, path, version)
 	if err != nil {
-		return "", fmt.Errorf("%w (%q): %w", types.ErrCouldNotFetchSecret, coord.Location, err)
+		return "", nil, err
 	}
 	if secret == nil {
-		return "", fmt.Errorf("%w (%q)", types.ErrSecretNotFound, coord.Location)
+		return "", nil, fmt.Errorf("%w (%q)", types.ErrSecretNotFound, coord.Location)
 	}
 	if secret.Data == nil {
-		return "", fmt.Errorf(
+		return "", nil, fmt.Errorf(
 			"%w (%q): secret contains no data",
 			types.ErrSecretNotFound,
 			coord.Location,
@@ -91,20 +126,491 @@ func (s *SecretSourceVault) DigUp(
 		// KV v1 or other logical paths
 		data = secret.Data
 	}
+	metadata := secretMetadata(secret)
 
 	// No key requested: return the whole `data` map
 	if len(key) == 0 {
 		dataJsonBytes, err := json.Marshal(data)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
-		return string(dataJsonBytes), nil
+		return string(dataJsonBytes), metadata, nil
 	}
 
 	// Return specific key
 	if val, found := data[key]; found {
-		return fmt.Sprintf("%v", val), nil
+		return fmt.Sprintf("%v", val), metadata, nil
+	}
+
+	return "", nil, fmt.Errorf("%w (%q)", types.ErrSecretKeyNotFound, coord.Location)
+}
+
+// Exists checks if the secret exists. For whole KV version 2 secrets (i.e. `<ENGINE_MOUNT>/data/.../`),
+// it reads their metadata endpoint, without reading the secret data: a secret whose current version
+// (or the version requested, see types.ParamVersion) is deleted or destroyed is considered as not existing.
+// As metadata doesn't list the keys of a secret, for all other coordinates the secret is read,
+// and the presence of the key checked.
+func (s *SecretSourceVault) Exists(ctx context.Context, coord types.SecretCoord) (bool, error) {
+	path, key, err := parseLocation(coord)
+	if err != nil {
+		return false, err
+	}
+	version, err := parseVersion(coord, path)
+	if err != nil {
+		return false, err
+	}
+
+	// KV v2 whole secret: read the metadata endpoint
+	mount, secretPath, isKVv2 := splitKVv2Path(path)
+	if isKVv2 && len(key) == 0 {
+		secret, err := s.vaultClient.Logical().ReadWithContext(ctx, mount+"/metadata/"+secretPath)
+		if err != nil {
+			return false, wrapFetchError(coord, err)
+		}
+		if secret == nil || secret.Data == nil {
+			return false, nil
+		}
+
+		if len(version) == 0 {
+			version = fmt.Sprintf("%v", secret.Data["current_version"])
+		}
+		versions, _ := secret.Data["versions"].(map[string]any)
+		current, _ := versions[version].(map[string]any)
+		if current == nil {
+			return false, nil
+		}
+		deletionTime, _ := current["deletion_time"].(string)
+		destroyed, _ := current["destroyed"].(bool)
+		return deletionTime == "" && !destroyed, nil
+	}
+
+	// KV v2 secret key, KV v1 or other logical paths: read the secret
+	secret, err := s.read(ctx, coord, path, version)
+	if err != nil {
+		return false, err
+	}
+	if secret == nil || secret.Data == nil {
+		return false, nil
+	}
+	data := secret.Data
+	if isKVv2 {
+		// The data of a deleted version is null, but its metadata is still returned
+		data, _ = secret.Data["data"].(map[string]any)
+		if data == nil {
+			return false, nil
+		}
+	}
+	if len(key) == 0 {
+		return true, nil
+	}
+	_, found := data[key]
+	return found, nil
+}
+
+// List lists the secrets and folders under the prefix `<ENGINE_MOUNT>[/<PATH>]`, via the LIST operation:
+// for KV version 2 prefixes (i.e. `<ENGINE_MOUNT>/data/...`), on the metadata endpoint.
+// Each child is returned as the coordinates of the whole secret (i.e. ending with `/`):
+// if it's a folder, they can be listed in turn.
+func (s *SecretSourceVault) List(ctx context.Context, prefix types.SecretCoord) ([]types.SecretCoord, error) {
+	base := strings.Trim(prefix.Location, "/")
+	if len(base) == 0 {
+		return nil, fmt.Errorf(
+			"%w: expected <MOUNT>[/<PATH>], got %q",
+			types.ErrInvalidLocation,
+			prefix.Location,
+		)
+	}
+
+	// KV v2: list the metadata endpoint
+	listPath := base
+	if parts := strings.SplitN(base, "/", 3); len(parts) >= 2 && parts[1] == "data" {
+		listPath = parts[0] + "/metadata"
+		if len(parts) == 3 {
+			listPath += "/" + parts[2]
+		}
+	}
+
+	secret, err := s.vaultClient.Logical().ListWithContext(ctx, listPath)
+	if err != nil {
+		return nil, wrapFetchError(prefix, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, nil
+	}
+
+	keys, _ := secret.Data["keys"].([]any)
+	children := make([]types.SecretCoord, 0, len(keys))
+	for _, key := range keys {
+		if name, ok := key.(string); ok {
+			children = append(children, types.SecretCoord{
+				Type:     prefix.Type,
+				Location: base + "/" + strings.TrimSuffix(name, "/") + "/",
+			})
+		}
+	}
+	return children, nil
+}
+
+// Put writes the secret. When `/KEY` is appended, only that key is set in the secret's data key-value map
+// (creating the secret, if it doesn't exist). Otherwise, if it ends with `/`, value must be a JSON object,
+// replacing the whole secret's data key-value map.
+//
+// For KV version 2 secrets, PutOptions.IfVersion is sent as the check-and-set parameter (`cas`; "0" to
+// only create the secret). Without it, setting a single key sends the version just read instead,
+// so that concurrent writes to other keys are never lost: with types.VersionNone, only if the key
+// is not in it yet. KV version 1 doesn't support compare-and-set.
+// As every write creates a new version, a specific version (see types.ParamVersion) can't be written.
+func (s *SecretSourceVault) Put(
+	ctx context.Context,
+	coord types.SecretCoord,
+	value string,
+	opts types.PutOptions,
+) error {
+	path, key, err := parseLocation(coord)
+	if err != nil {
+		return err
+	}
+	if len(coord.Param(types.ParamVersion)) > 0 {
+		return fmt.Errorf(
+			"%w: every write creates a new version, a specific version can't be written (%q)",
+			types.ErrInvalidLocation,
+			coord.Location,
+		)
+	}
+	if _, _, isKVv2 := splitKVv2Path(path); !isKVv2 && len(opts.IfVersion) > 0 {
+		return fmt.Errorf("%w: only KV version 2 secrets have a version", types.ErrCompareAndSetNotSupported)
+	}
+
+	// No key: replace the whole `data` map
+	if len(key) == 0 {
+		var data map[string]any
+		// The unmarshalling error is not wrapped, as it could contain part of the secret
+		if err := json.Unmarshal([]byte(value), &data); err != nil || data == nil {
+			return fmt.Errorf(
+				"%w: expected a JSON object to write the whole secret %q",
+				types.ErrInvalidSecretValue,
+				coord.Location,
+			)
+		}
+		return s.write(ctx, coord, path, data, opts.IfVersion)
+	}
+
+	// Set the key, keeping all others
+	data, version, err := s.readData(ctx, coord, path)
+	if err != nil {
+		return err
+	}
+	switch _, keyFound := data[key]; {
+	case opts.IfVersion == types.VersionNone && keyFound:
+		return fmt.Errorf(
+			"%w (%q): %w: key already exists",
+			types.ErrCouldNotWriteSecret,
+			coord.Location,
+			types.ErrVersionConflict,
+		)
+	case opts.IfVersion == types.VersionNone:
+		// Creating the key: the version just read guards against it being created meanwhile
+	case len(opts.IfVersion) > 0:
+		version = opts.IfVersion
+	}
+	data[key] = value
+	return s.write(ctx, coord, path, data, version)
+}
+
+// Delete deletes the secret or, when `/KEY` is appended, only that key in the secret's data key-value map.
+// For KV version 2 secrets, deleting the whole secret deletes its current version, or the version requested
+// (see types.ParamVersion), that can be undeleted. Deleting a key writes a new version (with check-and-set).
+func (s *SecretSourceVault) Delete(ctx context.Context, coord types.SecretCoord) error {
+	path, key, err := parseLocation(coord)
+	if err != nil {
+		return err
+	}
+	version, err := parseVersion(coord, path)
+	if err != nil {
+		return err
+	}
+
+	if len(version) > 0 {
+		if len(key) > 0 {
+			return fmt.Errorf(
+				"%w: a key can't be deleted from a specific version, expected <MOUNT>/data/<PATH/TO/SECRET>/, got %q",
+				types.ErrInvalidLocation,
+				coord.Location,
+			)
+		}
+		mount, secretPath, _ := splitKVv2Path(path)
+		versionNum, _ := strconv.Atoi(version)
+		_, err := s.vaultClient.Logical().WriteWithContext(
+			ctx,
+			mount+"/delete/"+secretPath,
+			map[string]any{"versions": []int{versionNum}},
+		)
+		if err != nil {
+			return wrapWriteError(coord, err)
+		}
+		return nil
 	}
 
-	return "", fmt.Errorf("%w (%q)", types.ErrSecretKeyNotFound, coord.Location)
+	if len(key) == 0 {
+		if _, err := s.vaultClient.Logical().DeleteWithContext(ctx, path); err != nil {
+			return wrapWriteError(coord, err)
+		}
+		return nil
+	}
+
+	data, version, err := s.readData(ctx, coord, path)
+	if err != nil {
+		return err
+	}
+	if _, found := data[key]; !found {
+		return nil
+	}
+	delete(data, key)
+	return s.write(ctx, coord, path, data, version)
+}
+
+// read reads the secret at path: at the given version, if any (i.e. for KV version 2 secrets).
+func (s *SecretSourceVault) read(
+	ctx context.Context,
+	coord types.SecretCoord,
+	path string,
+	version string,
+) (*api.Secret, error) {
+	var (
+		secret *api.Secret
+		err    error
+	)
+	if len(version) > 0 {
+		secret, err = s.vaultClient.Logical().ReadWithDataWithContext(ctx, path, map[string][]string{"version": {version}})
+	} else {
+		secret, err = s.vaultClient.Logical().ReadWithContext(ctx, path)
+	}
+	if err != nil {
+		return nil, wrapFetchError(coord, err)
+	}
+	return secret, nil
+}
+
+// readData reads the data key-value map of the secret at path (empty, if it doesn't exist) and,
+// for KV version 2 secrets, its current version ("0", if it doesn't exist).
+func (s *SecretSourceVault) readData(
+	ctx context.Context,
+	coord types.SecretCoord,
+	path string,
+) (map[string]any, string, error) {
+	secret, err := s.vaultClient.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return nil, "", wrapFetchError(coord, err)
+	}
+
+	_, _, isKVv2 := splitKVv2Path(path)
+	data, version := map[string]any{}, ""
+	if isKVv2 {
+		version = "0"
+	}
+	if secret == nil || secret.Data == nil {
+		return data, version, nil
+	}
+
+	if !isKVv2 {
+		return secret.Data, version, nil
+	}
+	// The data of a deleted version is null, but its metadata is still returned
+	if v2Data, ok := secret.Data["data"].(map[string]any); ok {
+		data = v2Data
+	}
+	if v := secretMetadata(secret).Version; len(v) > 0 {
+		version = v
+	}
+	return data, version, nil
+}
+
+// write writes data as the data key-value map of the secret at path:
+// for KV version 2 secrets, with the check-and-set version cas (if any).
+func (s *SecretSourceVault) write(
+	ctx context.Context,
+	coord types.SecretCoord,
+	path string,
+	data map[string]any,
+	cas string,
+) error {
+	body := data
+	if _, _, isKVv2 := splitKVv2Path(path); isKVv2 {
+		body = map[string]any{"data": data}
+		if len(cas) > 0 {
+			version, err := strconv.Atoi(cas)
+			if err != nil || version < 0 {
+				return fmt.Errorf(
+					"%w: expected a non-negative integer, got %q",
+					ErrSecretSourceVaultInvalidVersion,
+					cas,
+				)
+			}
+			body["options"] = map[string]any{"cas": version}
+		}
+	}
+
+	if _, err := s.vaultClient.Logical().WriteWithContext(ctx, path, body); err != nil {
+		return wrapWriteError(coord, err)
+	}
+	return nil
+}
+
+// secretMetadata extracts the metadata of secret: KV version 2 `metadata` field, and lease.
+func secretMetadata(secret *api.Secret) *types.SecretMetadata {
+	metadata := &types.SecretMetadata{}
+
+	if kvMetadata, ok := secret.Data["metadata"].(map[string]any); ok {
+		if version, found := kvMetadata["version"]; found && version != nil {
+			metadata.Version = fmt.Sprintf("%v", version)
+		}
+		if created, ok := kvMetadata["created_time"].(string); ok {
+			metadata.CreatedAt, _ = time.Parse(time.RFC3339Nano, created)
+		}
+		if deletion, ok := kvMetadata["deletion_time"].(string); ok {
+			metadata.ExpiresAt, _ = time.Parse(time.RFC3339Nano, deletion)
+		}
+		if custom, ok := kvMetadata["custom_metadata"].(map[string]any); ok {
+			for k, v := range custom {
+				metadata.SetExtra(k, fmt.Sprintf("%v", v))
+			}
+		}
+	}
+
+	if secret.LeaseID != "" {
+		metadata.SetExtra("lease_id", secret.LeaseID)
+	}
+	if secret.LeaseDuration > 0 {
+		metadata.ExpiresAt = time.Now().Add(time.Duration(secret.LeaseDuration) * time.Second)
+	}
+	return metadata
+}
+
+// ValidateLocation checks that the location has a mount, a path and an (optionally empty) key,
+// and that the version requested (if any) is valid.
+func (s *SecretSourceVault) ValidateLocation(coord types.SecretCoord) error {
+	path, _, err := parseLocation(coord)
+	if err == nil {
+		_, err = parseVersion(coord, path)
+	}
+	return err
+}
+
+// splitKVv2Path returns the engine mount and the path of the secret inside it,
+// if path points at a KV version 2 secret (i.e. `<ENGINE_MOUNT>/data/<PATH/TO/SECRET>`).
+func splitKVv2Path(path string) (mount, secretPath string, isKVv2 bool) {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) == 3 && parts[1] == "data" {
+		return parts[0], parts[2], true
+	}
+	return "", "", false
+}
+
+// parseLocation returns the path of the secret the location of coord points at, and the key inside it.
+// The key is empty to point at the entire secret.
+func parseLocation(coord types.SecretCoord) (string, string, error) {
+	parts := strings.Split(coord.Location, "/")
+
+	if len(parts) < 3 {
+		return "", "", fmt.Errorf(
+			"%w: expected <MOUNT>/<PATH/TO/SECRET>/<KEY> or <MOUNT>/<PATH/TO/SECRET>/, got %q",
+			types.ErrInvalidLocation,
+			coord.Location,
+		)
+	}
+
+	return strings.Join(parts[:len(parts)-1], "/"), parts[len(parts)-1], nil
+}
+
+// parseVersion returns the version requested via types.ParamVersion, if any, once checked that
+// it's a positive integer, and that path points at a KV version 2 secret (the only ones with versions).
+func parseVersion(coord types.SecretCoord, path string) (string, error) {
+	version := coord.Param(types.ParamVersion)
+	if len(version) == 0 {
+		return "", nil
+	}
+
+	if _, _, isKVv2 := splitKVv2Path(path); !isKVv2 {
+		return "", fmt.Errorf(
+			"%w: only KV version 2 secrets have versions, expected <MOUNT>/data/<PATH/TO/SECRET>, got %q",
+			types.ErrInvalidLocation,
+			coord.Location,
+		)
+	}
+	if versionNum, err := strconv.Atoi(version); err != nil || versionNum <= 0 {
+		return "", fmt.Errorf(
+			"%w: %w: expected a positive integer, got %q",
+			types.ErrInvalidLocation,
+			ErrSecretSourceVaultInvalidVersion,
+			version,
+		)
+	}
+	return version, nil
+}
+
+// wrapFetchError wraps a failure of the Vault API reading a secret, classifying it as transient,
+// permission denied or unauthenticated if it is.
+func wrapFetchError(coord types.SecretCoord, err error) error {
+	return wrapAPIError(types.ErrCouldNotFetchSecret, coord, err)
+}
+
+// wrapWriteError wraps a failure of the Vault API writing or deleting a secret, classifying it
+// as a version conflict if the check-and-set failed, or as wrapFetchError does otherwise.
+func wrapWriteError(coord types.SecretCoord, err error) error {
+	if respErr, errMatched := errors.AsType[*api.ResponseError](err); errMatched &&
+		respErr.StatusCode == http.StatusBadRequest &&
+		strings.Contains(strings.Join(respErr.Errors, " "), "check-and-set") {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			types.ErrCouldNotWriteSecret,
+			coord.Location,
+			types.ErrVersionConflict,
+			err,
+		)
+	}
+	return wrapAPIError(types.ErrCouldNotWriteSecret, coord, err)
+}
+
+// wrapAPIError wraps a failure of the Vault API in the given failure error,
+// classifying it as transient, permission denied or unauthenticated if it is.
+func wrapAPIError(failure error, coord types.SecretCoord, err error) error {
+	class := classifyResponseError(err)
+	if isTransient(err) {
+		class = types.ErrTransient
+	}
+	if class != nil {
+		return fmt.Errorf(
+			"%w (%q): %w: %w",
+			failure,
+			coord.Location,
+			class,
+			err,
+		)
+	}
+	return fmt.Errorf("%w (%q): %w", failure, coord.Location, err)
+}
+
+// classifyResponseError returns types.ErrPermissionDenied or types.ErrUnauthenticated,
+// if err is a 403 or 401 response error respectively, or nil otherwise.
+func classifyResponseError(err error) error {
+	if respErr, errMatched := errors.AsType[*api.ResponseError](err); errMatched {
+		switch respErr.StatusCode {
+		case http.StatusForbidden:
+			return types.ErrPermissionDenied
+		case http.StatusUnauthorized:
+			return types.ErrUnauthenticated
+		}
+	}
+	return nil
+}
+
+// isTransient returns true if err is a throttling or server-side response error
+// (e.g. Vault sealed or in standby), or a network error.
+func isTransient(err error) bool {
+	if respErr, errMatched := errors.AsType[*api.ResponseError](err); errMatched {
+		return respErr.StatusCode == http.StatusTooManyRequests ||
+			respErr.StatusCode >= http.StatusInternalServerError
+	}
+	_, errMatched := errors.AsType[net.Error](err)
+	return errMatched
 }
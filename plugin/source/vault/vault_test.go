@@ -2,7 +2,13 @@ package vault_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -45,6 +51,16 @@ func TestSecretSourceVault_DigUp_Parsing(t *testing.T) {
 			coordStr: "vault://mount/",
 			errMatch: types.ErrInvalidLocation,
 		},
+		{
+			name:     "invalid version (of a kv v1 secret)",
+			coordStr: "vault://kv1/app/key?@version=2",
+			errMatch: types.ErrInvalidLocation,
+		},
+		{
+			name:     "invalid version (not a positive integer)",
+			coordStr: "vault://kv/data/app/key?@version=latest",
+			errMatch: vault.ErrSecretSourceVaultInvalidVersion,
+		},
 	}
 
 	for _, tt := range tests {
@@ -54,6 +70,84 @@ func TestSecretSourceVault_DigUp_Parsing(t *testing.T) {
 
 			_, err = s.DigUp(t.Context(), *coord)
 			require.ErrorIs(t, err, tt.errMatch)
+
+			err = s.ValidateLocation(*coord)
+			require.ErrorIs(t, err, tt.errMatch)
+			require.ErrorIs(t, err, types.ErrInvalidLocation)
+		})
+	}
+}
+
+func TestSecretSourceVault_DigUp_ErrorClassification(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		errMatch      error
+		wantTransient bool
+		wantClass     types.ErrorClass
+	}{
+		{
+			name:          "sealed or standby",
+			statusCode:    http.StatusServiceUnavailable,
+			errMatch:      types.ErrCouldNotFetchSecret,
+			wantTransient: true,
+			wantClass:     types.ErrorClassTransient,
+		},
+		{
+			name:          "throttled",
+			statusCode:    http.StatusTooManyRequests,
+			errMatch:      types.ErrCouldNotFetchSecret,
+			wantTransient: true,
+			wantClass:     types.ErrorClassTransient,
+		},
+		{
+			name:          "permission denied",
+			statusCode:    http.StatusForbidden,
+			errMatch:      types.ErrPermissionDenied,
+			wantTransient: false,
+			wantClass:     types.ErrorClassPermissionDenied,
+		},
+		{
+			name:          "unauthenticated",
+			statusCode:    http.StatusUnauthorized,
+			errMatch:      types.ErrUnauthenticated,
+			wantTransient: false,
+			wantClass:     types.ErrorClassUnauthenticated,
+		},
+		{
+			name:          "not found",
+			statusCode:    http.StatusNotFound,
+			errMatch:      types.ErrSecretNotFound,
+			wantTransient: false,
+			wantClass:     types.ErrorClassNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer srv.Close()
+
+			cfg := api.DefaultConfig()
+			cfg.Address = srv.URL
+			cfg.MaxRetries = 0
+			client, err := api.NewClient(cfg)
+			require.NoError(t, err)
+
+			coord, err := types.NewSecretCoord("vault://mount/path/key")
+			require.NoError(t, err)
+
+			_, err = spelunk.NewSpelunker(vault.WithVault(client)).DigUp(t.Context(), coord)
+			require.ErrorIs(t, err, tt.errMatch)
+			require.Equal(t, tt.wantTransient, errors.Is(err, types.ErrTransient))
+
+			digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+			require.True(t, errMatched)
+			require.Equal(t, tt.wantClass, digUpErr.Class)
+			require.Equal(t, types.ErrorStageSource, digUpErr.Stage)
+			require.Equal(t, "vault", digUpErr.Type)
 		})
 	}
 }
@@ -226,3 +320,408 @@ func setupVaultTestContainer(t *testing.T) (*api.Client, error) {
 
 	return client, nil
 }
+
+func TestSecretSourceVault_DigUpWithMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"data": {
+				"data": {"password": "s3cret"},
+				"metadata": {
+					"created_time": "2026-01-02T03:04:05.123456Z",
+					"custom_metadata": {"owner": "team-a"},
+					"deletion_time": "",
+					"destroyed": false,
+					"version": 3
+				}
+			}
+		}`))
+	}))
+	defer srv.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = srv.URL
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+
+	coord, err := types.NewSecretCoord("vault://kv/data/app/password")
+	require.NoError(t, err)
+
+	got, metadata, err := vault.New(client).DigUpWithMetadata(t.Context(), *coord)
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", got)
+	require.Equal(t, &types.SecretMetadata{
+		Version:   "3",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC),
+		Extras:    map[string]string{"owner": "team-a"},
+	}, metadata)
+}
+
+func TestSecretSourceVault_Exists(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/kv/metadata/app", "/v1/kv/metadata/whole":
+			_, _ = w.Write([]byte(`{
+				"data": {
+					"current_version": 2,
+					"versions": {
+						"1": {"deletion_time": "", "destroyed": false},
+						"2": {"deletion_time": "", "destroyed": false}
+					}
+				}
+			}`))
+		case "/v1/kv/metadata/deleted":
+			_, _ = w.Write([]byte(`{
+				"data": {
+					"current_version": 1,
+					"versions": {
+						"1": {"deletion_time": "2026-01-02T03:04:05.123456Z", "destroyed": false}
+					}
+				}
+			}`))
+		case "/v1/kv1/app":
+			_, _ = w.Write([]byte(`{"data": {"password": "s3cret"}}`))
+		case "/v1/kv/data/app":
+			if r.URL.Query().Get("version") == "1" {
+				_, _ = w.Write([]byte(`{"data": {"data": {"user": "admin"}, "metadata": {"version": 1}}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": {"data": {"password": "s3cret"}, "metadata": {"version": 2}}}`))
+		case "/v1/kv/data/whole":
+			t.Errorf("secret data read for a whole KV v2 secret")
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = srv.URL
+	cfg.MaxRetries = 0
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+	s := vault.New(client)
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     bool
+	}{
+		{
+			name:     "kv v2 secret",
+			coordStr: "vault://kv/data/whole/",
+			want:     true,
+		},
+		{
+			name:     "kv v2 secret key",
+			coordStr: "vault://kv/data/app/password",
+			want:     true,
+		},
+		{
+			name:     "kv v2 secret key that does not exist",
+			coordStr: "vault://kv/data/app/missingkey",
+			want:     false,
+		},
+		{
+			name:     "kv v2 secret at a version",
+			coordStr: "vault://kv/data/whole/?@version=1",
+			want:     true,
+		},
+		{
+			name:     "kv v2 secret at a version that does not exist",
+			coordStr: "vault://kv/data/whole/?@version=3",
+			want:     false,
+		},
+		{
+			name:     "kv v2 secret key at a version",
+			coordStr: "vault://kv/data/app/user?@version=1",
+			want:     true,
+		},
+		{
+			name:     "kv v2 secret key not at a version",
+			coordStr: "vault://kv/data/app/password?@version=1",
+			want:     false,
+		},
+		{
+			name:     "kv v2 secret with current version deleted",
+			coordStr: "vault://kv/data/deleted/password",
+			want:     false,
+		},
+		{
+			name:     "kv v2 secret that does not exist",
+			coordStr: "vault://kv/data/missing/password",
+			want:     false,
+		},
+		{
+			name:     "kv v1 secret key",
+			coordStr: "vault://kv1/app/password",
+			want:     true,
+		},
+		{
+			name:     "kv v1 secret key that does not exist",
+			coordStr: "vault://kv1/app/missing",
+			want:     false,
+		},
+		{
+			name:     "kv v1 secret that does not exist",
+			coordStr: "vault://kv1/missing/",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := s.Exists(t.Context(), *coord)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSecretSourceVault_List(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "LIST" && r.URL.Query().Get("list") != "true" {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/kv/metadata/team-a", "/v1/kv1/team-a":
+			_, _ = w.Write([]byte(`{"data": {"keys": ["db", "services/"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = srv.URL
+	cfg.MaxRetries = 0
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+	s := vault.New(client)
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     []string
+		errMatch error
+	}{
+		{
+			name:     "kv v2 folder",
+			coordStr: "vault://kv/data/team-a/",
+			want:     []string{"kv/data/team-a/db/", "kv/data/team-a/services/"},
+		},
+		{
+			name:     "kv v1 folder",
+			coordStr: "vault://kv1/team-a",
+			want:     []string{"kv1/team-a/db/", "kv1/team-a/services/"},
+		},
+		{
+			name:     "folder that does not exist",
+			coordStr: "vault://kv/data/missing/",
+			want:     []string{},
+		},
+		{
+			name:     "invalid location",
+			coordStr: "vault:///",
+			errMatch: types.ErrInvalidLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := s.List(t.Context(), *coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+
+			locations := []string{}
+			for _, child := range got {
+				require.Equal(t, "vault", child.Type)
+				locations = append(locations, child.Location)
+			}
+			require.Equal(t, tt.want, locations)
+		})
+	}
+}
+
+// kvServer fakes the KV version 2 engine mounted at `kv` (honouring check-and-set, and keeping
+// all versions), and the KV version 1 engine mounted at `kv1`.
+type kvServer struct {
+	mu       sync.Mutex
+	versions map[string]int
+	data     map[string]map[string]any
+	history  map[string]map[int]map[string]any
+}
+
+func (kv *kvServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	_, isKVv2 := strings.CutPrefix(path, "kv/data/")
+	switch r.Method {
+	case http.MethodGet:
+		data, found := kv.data[path]
+		version := kv.versions[path]
+		if v := r.URL.Query().Get("version"); isKVv2 && len(v) > 0 {
+			version, _ = strconv.Atoi(v)
+			data, found = kv.history[path][version]
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body := map[string]any{"data": data}
+		if isKVv2 {
+			body = map[string]any{"data": map[string]any{
+				"data":     data,
+				"metadata": map[string]any{"version": version},
+			}}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	case http.MethodPut:
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if secretPath, isDelete := strings.CutPrefix(path, "kv/delete/"); isDelete {
+			versions, _ := body["versions"].([]any)
+			for _, version := range versions {
+				delete(kv.history["kv/data/"+secretPath], int(version.(float64)))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if !isKVv2 {
+			kv.data[path] = body
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if options, ok := body["options"].(map[string]any); ok {
+			if cas, ok := options["cas"].(float64); ok && int(cas) != kv.versions[path] {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors": ["check-and-set parameter did not match the current version"]}`))
+				return
+			}
+		}
+		kv.data[path], _ = body["data"].(map[string]any)
+		kv.versions[path]++
+		if kv.history[path] == nil {
+			kv.history[path] = map[int]map[string]any{}
+		}
+		kv.history[path][kv.versions[path]] = kv.data[path]
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"version": kv.versions[path]}})
+	case http.MethodDelete:
+		delete(kv.data, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestSecretSourceVault_PutDelete(t *testing.T) {
+	srv := httptest.NewServer(&kvServer{
+		versions: map[string]int{},
+		data:     map[string]map[string]any{},
+		history:  map[string]map[int]map[string]any{},
+	})
+	defer srv.Close()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = srv.URL
+	cfg.MaxRetries = 0
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+	s := vault.New(client)
+
+	put := func(coordStr, value string, opts types.PutOptions) error {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return s.Put(t.Context(), *coord, value, opts)
+	}
+	del := func(coordStr string) error {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return s.Delete(t.Context(), *coord)
+	}
+	digUp := func(coordStr string) (string, error) {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return s.DigUp(t.Context(), *coord)
+	}
+
+	// KV v2: setting keys creates the secret, then keeps the other keys (version 2)
+	require.NoError(t, put("vault://kv/data/app/password", "s3cret", types.PutOptions{}))
+	require.NoError(t, put("vault://kv/data/app/user", "admin", types.PutOptions{}))
+	got, err := digUp("vault://kv/data/app/")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"password": "s3cret", "user": "admin"}`, got)
+
+	// KV v2: versions are dug-up and deleted, but never written
+	got, err = digUp("vault://kv/data/app/password?@version=1")
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", got)
+	_, err = digUp("vault://kv/data/app/user?@version=1")
+	require.ErrorIs(t, err, types.ErrSecretKeyNotFound)
+	require.ErrorIs(t, put("vault://kv/data/app/password?@version=1", "s3cret", types.PutOptions{}), types.ErrInvalidLocation)
+	require.ErrorIs(t, del("vault://kv/data/app/password?@version=1"), types.ErrInvalidLocation)
+	require.NoError(t, del("vault://kv/data/app/?@version=1"))
+	_, err = digUp("vault://kv/data/app/password?@version=1")
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+
+	// KV v2: check-and-set
+	err = put("vault://kv/data/app/password", "stale", types.PutOptions{IfVersion: "1"})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+	require.ErrorIs(t, err, types.ErrCouldNotWriteSecret)
+	require.Equal(t, types.ErrorClassConflict, types.ClassifyError(err))
+	require.NoError(t, put("vault://kv/data/app/", `{"token": "t0k3n"}`, types.PutOptions{IfVersion: "2"}))
+	got, err = digUp("vault://kv/data/app/")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"token": "t0k3n"}`, got)
+	err = put("vault://kv/data/new/", `{"token": "t0k3n"}`, types.PutOptions{IfVersion: "latest"})
+	require.ErrorIs(t, err, vault.ErrSecretSourceVaultInvalidVersion)
+
+	// KV v2: create-only, for whole secrets and for keys
+	err = put("vault://kv/data/app/", `{"token": "t0k3n"}`, types.PutOptions{IfVersion: types.VersionNone})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+	err = put("vault://kv/data/app/token", "stale", types.PutOptions{IfVersion: types.VersionNone})
+	require.ErrorIs(t, err, types.ErrVersionConflict)
+	require.NoError(t, put("vault://kv/data/app/other", "new", types.PutOptions{IfVersion: types.VersionNone}))
+	require.NoError(t, del("vault://kv/data/app/other"))
+
+	// The whole secret must be a JSON object
+	err = put("vault://kv/data/app/", "not-json", types.PutOptions{})
+	require.ErrorIs(t, err, types.ErrInvalidSecretValue)
+	require.NotContains(t, err.Error(), "not-json")
+
+	// KV v2: deleting a key, then the whole secret
+	require.NoError(t, put("vault://kv/data/app/user", "admin", types.PutOptions{}))
+	require.NoError(t, del("vault://kv/data/app/token"))
+	require.NoError(t, del("vault://kv/data/app/missing"))
+	got, err = digUp("vault://kv/data/app/")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"user": "admin"}`, got)
+	require.NoError(t, del("vault://kv/data/app/"))
+	_, err = digUp("vault://kv/data/app/")
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+
+	// KV v1: no check-and-set
+	require.NoError(t, put("vault://kv1/app/password", "s3cret", types.PutOptions{}))
+	got, err = digUp("vault://kv1/app/password")
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", got)
+	err = put("vault://kv1/app/password", "s3cret", types.PutOptions{IfVersion: "1"})
+	require.ErrorIs(t, err, types.ErrCompareAndSetNotSupported)
+	err = put("vault://kv1/app/password", "s3cret", types.PutOptions{IfVersion: types.VersionNone})
+	require.ErrorIs(t, err, types.ErrCompareAndSetNotSupported)
+
+	require.ErrorIs(t, put("vault://kv", "s3cret", types.PutOptions{}), types.ErrInvalidLocation)
+}
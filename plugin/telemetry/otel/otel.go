@@ -0,0 +1,248 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter used by this package.
+const ScopeName = "github.com/detro/spelunk/plugin/telemetry/otel"
+
+// Attribute keys set on spans and metrics.
+const (
+	AttrSourceType    = attribute.Key("spelunk.source.type")
+	AttrModifierType  = attribute.Key("spelunk.modifier.type")
+	AttrModifierTypes = attribute.Key("spelunk.modifier.types")
+	AttrLocation      = attribute.Key("spelunk.location")
+	AttrCacheHit      = attribute.Key("spelunk.cache.hit")
+	AttrErrorType     = attribute.Key("error.type")
+)
+
+// Option options that can be provided to WithOpenTelemetry.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets the trace.TracerProvider used to create spans.
+// Default is the global one.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tracerProvider
+	}
+}
+
+// WithMeterProvider sets the metric.MeterProvider used to record metrics.
+// Default is the global one.
+func WithMeterProvider(meterProvider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = meterProvider
+	}
+}
+
+// WithOpenTelemetry instruments a spelunk.Spelunker with OpenTelemetry tracing and metrics.
+//
+// Every call to spelunk.Spelunker.DigUp gets a span, with a child span for the call to the source
+// and for each modifier applied. Spans carry the source type, the modifier types and the location
+// of the secret, redacted if it is the secret itself (see types.RedactLocation).
+// Values and modifier arguments are never recorded.
+//
+// Recorded metrics are:
+//
+//   - `spelunk.digup.duration`: latency of dig-ups, by source type and error class
+//   - `spelunk.source.duration`: latency of calls to sources, by source type and error class
+//   - `spelunk.modifier.duration`: latency of calls to modifiers, by modifier type and error class
+//   - `spelunk.cache.lookups`: lookups into the cache (see spelunk.WithCache), by source type and hit
+//
+// Instrumentation is made of middlewares (see spelunk.WithSourceMiddleware): add this option
+// before spelunk.WithCache for source spans to be created also when the value is cached.
+func WithOpenTelemetry(opts ...Option) spelunk.SpelunkerOption {
+	c := config{
+		tracerProvider: otelglobal.GetTracerProvider(),
+		meterProvider:  otelglobal.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	i := newInstrumentation(c)
+	return spelunk.WithOptions(
+		spelunk.WithDigUpMiddleware(i.digUpMiddleware),
+		spelunk.WithSourceMiddleware(i.sourceMiddleware),
+		spelunk.WithModifierMiddleware(i.modifierMiddleware),
+		spelunk.WithCacheObserver(i.observeCache),
+	)
+}
+
+// instrumentation holds the tracer and the metric instruments.
+type instrumentation struct {
+	tracer trace.Tracer
+
+	digUpDuration    metric.Float64Histogram
+	sourceDuration   metric.Float64Histogram
+	modifierDuration metric.Float64Histogram
+	cacheLookups     metric.Int64Counter
+}
+
+func newInstrumentation(c config) *instrumentation {
+	meter := c.meterProvider.Meter(ScopeName)
+	i := &instrumentation{
+		tracer: c.tracerProvider.Tracer(ScopeName),
+	}
+
+	// On failure, instruments are still returned (no-op): the error is reported to the global handler
+	var err error
+	i.digUpDuration, err = meter.Float64Histogram(
+		"spelunk.digup.duration",
+		metric.WithDescription("Duration of secret dig-ups, including modifiers."),
+		metric.WithUnit("s"),
+	)
+	handleErr(err)
+	i.sourceDuration, err = meter.Float64Histogram(
+		"spelunk.source.duration",
+		metric.WithDescription("Duration of calls to secret sources."),
+		metric.WithUnit("s"),
+	)
+	handleErr(err)
+	i.modifierDuration, err = meter.Float64Histogram(
+		"spelunk.modifier.duration",
+		metric.WithDescription("Duration of calls to secret modifiers."),
+		metric.WithUnit("s"),
+	)
+	handleErr(err)
+	i.cacheLookups, err = meter.Int64Counter(
+		"spelunk.cache.lookups",
+		metric.WithDescription("Lookups into the cache of secret values."),
+		metric.WithUnit("{lookup}"),
+	)
+	handleErr(err)
+
+	return i
+}
+
+func handleErr(err error) {
+	if err != nil {
+		otelglobal.Handle(err)
+	}
+}
+
+// digUpSpanKey is the context key of the span of the dig-up in progress.
+type digUpSpanKey struct{}
+
+func (i *instrumentation) digUpMiddleware(next spelunk.DigUpHandler) spelunk.DigUpHandler {
+	return func(ctx context.Context, coord *types.SecretCoord) (string, error) {
+		modifierTypes := make([]string, 0, len(coord.Modifiers))
+		for _, mod := range coord.Modifiers {
+			modifierTypes = append(modifierTypes, mod[0])
+		}
+
+		ctx, span := i.tracer.Start(ctx, "spelunk.DigUp",
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(
+				AttrSourceType.String(coord.Type),
+				AttrModifierTypes.StringSlice(modifierTypes),
+			),
+		)
+		defer span.End()
+		ctx = context.WithValue(ctx, digUpSpanKey{}, span)
+
+		start := time.Now()
+		val, err := next(ctx, coord)
+		i.digUpDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			outcomeAttributes(err, AttrSourceType.String(coord.Type))...,
+		))
+		endSpan(span, err)
+
+		return val, err
+	}
+}
+
+func (i *instrumentation) sourceMiddleware(next spelunk.SourceHandler) spelunk.SourceHandler {
+	return func(
+		ctx context.Context,
+		source types.SecretSource,
+		coord types.SecretCoord,
+	) (string, error) {
+		location := AttrLocation.String(types.RedactLocation(source, coord))
+		if digUpSpan, ok := ctx.Value(digUpSpanKey{}).(trace.Span); ok {
+			digUpSpan.SetAttributes(location)
+		}
+
+		ctx, span := i.tracer.Start(ctx, "spelunk.SecretSource.DigUp",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(AttrSourceType.String(source.Type()), location),
+		)
+		defer span.End()
+
+		start := time.Now()
+		val, err := next(ctx, source, coord)
+		i.sourceDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			outcomeAttributes(err, AttrSourceType.String(source.Type()))...,
+		))
+		endSpan(span, err)
+
+		return val, err
+	}
+}
+
+func (i *instrumentation) modifierMiddleware(next spelunk.ModifierHandler) spelunk.ModifierHandler {
+	return func(
+		ctx context.Context,
+		modifier types.SecretModifier,
+		coord types.SecretCoord,
+		value string,
+		arg string,
+	) (string, error) {
+		ctx, span := i.tracer.Start(ctx, "spelunk.SecretModifier.Modify",
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithAttributes(
+				AttrSourceType.String(coord.Type),
+				AttrModifierType.String(modifier.Type()),
+			),
+		)
+		defer span.End()
+
+		start := time.Now()
+		val, err := next(ctx, modifier, coord, value, arg)
+		i.modifierDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			outcomeAttributes(err, AttrModifierType.String(modifier.Type()))...,
+		))
+		endSpan(span, err)
+
+		return val, err
+	}
+}
+
+func (i *instrumentation) observeCache(ctx context.Context, coord types.SecretCoord, hit bool) {
+	i.cacheLookups.Add(ctx, 1, metric.WithAttributes(
+		AttrSourceType.String(coord.Type),
+		AttrCacheHit.Bool(hit),
+	))
+	trace.SpanFromContext(ctx).SetAttributes(AttrCacheHit.Bool(hit))
+}
+
+// outcomeAttributes returns attrs, plus the error class of err, if any.
+func outcomeAttributes(err error, attrs ...attribute.KeyValue) []attribute.KeyValue {
+	if err != nil {
+		attrs = append(attrs, AttrErrorType.String(string(types.ClassifyError(err))))
+	}
+	return attrs
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetAttributes(AttrErrorType.String(string(types.ClassifyError(err))))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,195 @@
+package otel_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/detro/spelunk/plugin/telemetry/otel/v2"
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestSpelunker(t *testing.T, opts ...spelunk.SpelunkerOption) (
+	*spelunk.Spelunker,
+	*tracetest.SpanRecorder,
+	*sdkmetric.ManualReader,
+) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	instrumentation := otel.WithOpenTelemetry(
+		otel.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		otel.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	return spelunk.NewSpelunker(append([]spelunk.SpelunkerOption{instrumentation}, opts...)...),
+		recorder,
+		reader
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func TestWithOpenTelemetry_Spans(t *testing.T) {
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	spelunker, recorder, _ := newTestSpelunker(t,
+		spelunk.WithSource(src),
+		spelunk.WithModifier(util.NewMockModifier("mod")),
+	)
+
+	coord, err := types.NewSecretCoord("test://path/to/secret?mod=arg")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(context.Background(), coord)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+
+	digUp := byName["spelunk.DigUp"]
+	require.NotNil(t, digUp)
+	attrs := spanAttributes(digUp)
+	require.Equal(t, "test", attrs[otel.AttrSourceType].AsString())
+	require.Equal(t, []string{"mod"}, attrs[otel.AttrModifierTypes].AsStringSlice())
+	require.Equal(t, "path/to/secret", attrs[otel.AttrLocation].AsString())
+
+	source := byName["spelunk.SecretSource.DigUp"]
+	require.NotNil(t, source)
+	require.Equal(t, digUp.SpanContext().SpanID(), source.Parent().SpanID())
+	require.Equal(t, "path/to/secret", spanAttributes(source)[otel.AttrLocation].AsString())
+
+	modifier := byName["spelunk.SecretModifier.Modify"]
+	require.NotNil(t, modifier)
+	require.Equal(t, digUp.SpanContext().SpanID(), modifier.Parent().SpanID())
+	require.Equal(t, "mod", spanAttributes(modifier)[otel.AttrModifierType].AsString())
+
+	// Values and modifier arguments are never recorded
+	for _, span := range spans {
+		for _, kv := range span.Attributes() {
+			require.NotContains(t, kv.Value.Emit(), "secret-value")
+			require.NotContains(t, kv.Value.Emit(), "arg")
+		}
+	}
+}
+
+func TestWithOpenTelemetry_RedactsInlineLocations(t *testing.T) {
+	spelunker, recorder, _ := newTestSpelunker(t)
+
+	coord, err := types.NewSecretCoord("plain://my-inline-secret")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(context.Background(), coord)
+	require.NoError(t, err)
+
+	for _, span := range recorder.Ended() {
+		for _, kv := range span.Attributes() {
+			require.NotContains(t, kv.Value.Emit(), "my-inline-secret")
+		}
+		if location, found := spanAttributes(span)[otel.AttrLocation]; found {
+			require.Equal(t, types.RedactedLocation, location.AsString())
+		}
+	}
+}
+
+func TestWithOpenTelemetry_Errors(t *testing.T) {
+	src := util.NewMockSource("test")
+	src.Err = wrap(types.ErrSecretNotFound)
+	spelunker, recorder, reader := newTestSpelunker(t, spelunk.WithSource(src))
+
+	coord, err := types.NewSecretCoord("test://missing")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(context.Background(), coord)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		require.Equal(t, codes.Error, span.Status().Code)
+		require.Equal(t, "not_found", spanAttributes(span)[otel.AttrErrorType].AsString())
+	}
+
+	metrics := collectMetrics(t, reader)
+	digUpDuration, ok := metrics["spelunk.digup.duration"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, digUpDuration.DataPoints, 1)
+	errorType, found := digUpDuration.DataPoints[0].Attributes.Value(otel.AttrErrorType)
+	require.True(t, found)
+	require.Equal(t, "not_found", errorType.AsString())
+}
+
+func TestWithOpenTelemetry_Metrics(t *testing.T) {
+	src := util.NewMockSource("test")
+	src.Val = "secret-value"
+	spelunker, _, reader := newTestSpelunker(t,
+		spelunk.WithSource(src),
+		spelunk.WithModifier(util.NewMockModifier("mod")),
+		spelunk.WithCache(),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc?mod=arg")
+	require.NoError(t, err)
+	for range 3 {
+		_, err = spelunker.DigUp(context.Background(), coord)
+		require.NoError(t, err)
+	}
+
+	metrics := collectMetrics(t, reader)
+	for _, name := range []string{
+		"spelunk.digup.duration",
+		"spelunk.source.duration",
+		"spelunk.modifier.duration",
+	} {
+		histogram, ok := metrics[name].(metricdata.Histogram[float64])
+		require.True(t, ok, name)
+		require.Len(t, histogram.DataPoints, 1, name)
+		require.Equal(t, uint64(3), histogram.DataPoints[0].Count, name)
+	}
+
+	lookups, ok := metrics["spelunk.cache.lookups"].(metricdata.Sum[int64])
+	require.True(t, ok)
+	counts := make(map[bool]int64)
+	for _, dp := range lookups.DataPoints {
+		hit, _ := dp.Attributes.Value(otel.AttrCacheHit)
+		counts[hit.AsBool()] = dp.Value
+	}
+	require.Equal(t, map[bool]int64{true: 2, false: 1}, counts)
+	require.Equal(t, 1, src.Calls())
+}
+
+// wrap wraps err, like sources do.
+func wrap(err error) error {
+	return fmt.Errorf("wrapped (%q): %w", "loc", err)
+}
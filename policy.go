@@ -0,0 +1,225 @@
+package spelunk
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+var ErrPolicyViolation = fmt.Errorf("policy violation")
+
+// PolicyViolationError reports coordinates rejected by the policy of a Spelunker (see WithPolicy).
+// It wraps ErrPolicyViolation and types.ErrPermissionDenied.
+type PolicyViolationError struct {
+	// Type is the type of the rejected coordinates.
+	Type string
+	// Location is the location of the rejected coordinates, redacted if it is the secret itself (see types.RedactLocation).
+	Location string
+	// Rule describes the rule that rejected the coordinates.
+	Rule string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("%v (%s://%s): %s", ErrPolicyViolation, e.Type, e.Location, e.Rule)
+}
+
+// Unwrap returns ErrPolicyViolation and types.ErrPermissionDenied.
+func (e *PolicyViolationError) Unwrap() []error {
+	return []error{ErrPolicyViolation, types.ErrPermissionDenied}
+}
+
+// PolicyOption options that can be provided to WithPolicy.
+type PolicyOption func(*policy)
+
+// WithPolicyAllowSources allows coordinates of the given source types.
+// Once any allow rule is set, coordinates not matching one are rejected.
+func WithPolicyAllowSources(sourceTypes ...string) PolicyOption {
+	return func(p *policy) {
+		p.allow = append(p.allow, sourceTypesRule("source type not allowed", sourceTypes))
+	}
+}
+
+// WithPolicyDenySources rejects coordinates of the given source types.
+func WithPolicyDenySources(sourceTypes ...string) PolicyOption {
+	return func(p *policy) {
+		p.deny = append(p.deny, sourceTypesRule("source type denied", sourceTypes))
+	}
+}
+
+// WithPolicyAllowLocations allows coordinates matching any of the given patterns,
+// in the form `<TYPE>://<LOCATION>` (e.g. `vault://kv/data/team-a/**`).
+// In patterns, `*` matches any sequence of characters except `/`, `**` matches any sequence of characters,
+// and `?` matches any single character except `/`: `vault://kv/data/team-a/*` matches `vault://kv/data/team-a/db`,
+// but not `vault://kv/data/team-a/db/password`, matched by `vault://kv/data/team-a/**` instead.
+// Once any allow rule is set, coordinates not matching one are rejected.
+//
+// Locations with `.`, `..` or empty path segments must match both as they are, and once cleaned
+// (like backends such as Vault do): path traversal can't escape allowed locations, nor denied ones.
+func WithPolicyAllowLocations(patterns ...string) PolicyOption {
+	return func(p *policy) {
+		p.allow = append(p.allow, locationsRule("location not allowed", patterns, true))
+	}
+}
+
+// WithPolicyDenyLocations rejects coordinates matching any of the given patterns.
+// See WithPolicyAllowLocations for the syntax of patterns.
+func WithPolicyDenyLocations(patterns ...string) PolicyOption {
+	return func(p *policy) {
+		p.deny = append(p.deny, locationsRule("location denied", patterns, false))
+	}
+}
+
+// WithPolicyDenyInlineSources rejects coordinates of any types.InlineSecretSource (e.g. `plain://`, `base64://`),
+// whose location is the secret itself.
+func WithPolicyDenyInlineSources() PolicyOption {
+	return func(p *policy) {
+		p.deny = append(p.deny, policyRule{
+			desc: "inline secret source denied",
+			match: func(source types.SecretSource, _ *types.SecretCoord) bool {
+				_, inline := source.(types.InlineSecretSource)
+				return inline
+			},
+		})
+	}
+}
+
+// WithPolicy restricts which coordinates a Spelunker digs up, according to the given PolicyOption.
+// Rejected coordinates fail with a *PolicyViolationError, before the source is used.
+//
+// Deny rules are checked first: coordinates matching any are rejected. Then, if any allow rule is set,
+// coordinates must match at least one. Multiple WithPolicy add up to the same policy.
+//
+// To remove a source altogether, use WithoutSource.
+func WithPolicy(opts ...PolicyOption) SpelunkerOption {
+	return func(o *options) {
+		if o.policy == nil {
+			o.policy = &policy{}
+		}
+		for _, opt := range opts {
+			opt(o.policy)
+		}
+	}
+}
+
+// Strict is a policy preset for production use: it rejects coordinates of any types.InlineSecretSource
+// (e.g. `plain://`, `base64://`), so that secrets can't be embedded in configuration.
+// It can be combined with further WithPolicy.
+func Strict() SpelunkerOption {
+	return WithPolicy(WithPolicyDenyInlineSources())
+}
+
+// policy is the set of rules coordinates must comply with, to be dug-up.
+type policy struct {
+	allow []policyRule
+	deny  []policyRule
+}
+
+// policyRule matches coordinates, and describes itself for PolicyViolationError.
+type policyRule struct {
+	desc  string
+	match func(source types.SecretSource, coord *types.SecretCoord) bool
+}
+
+// check returns a *PolicyViolationError if coord is rejected. A nil policy allows everything.
+func (p *policy) check(source types.SecretSource, coord *types.SecretCoord) error {
+	if p == nil {
+		return nil
+	}
+
+	newViolation := func(rule string) error {
+		return &PolicyViolationError{
+			Type:     coord.Type,
+			Location: types.RedactLocation(source, *coord),
+			Rule:     rule,
+		}
+	}
+	for _, rule := range p.deny {
+		if rule.match(source, coord) {
+			return newViolation(rule.desc)
+		}
+	}
+	if len(p.allow) == 0 {
+		return nil
+	}
+	for _, rule := range p.allow {
+		if rule.match(source, coord) {
+			return nil
+		}
+	}
+	return newViolation("not allowed by any rule")
+}
+
+func sourceTypesRule(desc string, sourceTypes []string) policyRule {
+	sourceTypes = slices.Clone(sourceTypes)
+	return policyRule{
+		desc: desc,
+		match: func(_ types.SecretSource, coord *types.SecretCoord) bool {
+			return slices.Contains(sourceTypes, coord.Type)
+		},
+	}
+}
+
+// locationsRule matches coordinates whose location matches any of patterns. The location is matched
+// as it is, and cleaned (see cleanLocation): if matchBoth, both must match (for allow rules),
+// otherwise either (for deny rules), so that the rule can't be escaped via path traversal.
+func locationsRule(desc string, patterns []string, matchBoth bool) policyRule {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		regexps = append(regexps, compileLocationPattern(pattern))
+	}
+	matches := func(uri string) bool {
+		return slices.ContainsFunc(regexps, func(re *regexp.Regexp) bool {
+			return re.MatchString(uri)
+		})
+	}
+	return policyRule{
+		desc: desc,
+		match: func(_ types.SecretSource, coord *types.SecretCoord) bool {
+			rawMatch := matches(coord.Type + "://" + coord.Location)
+			cleanMatch := matches(coord.Type + "://" + cleanLocation(coord.Location))
+			if matchBoth {
+				return rawMatch && cleanMatch
+			}
+			return rawMatch || cleanMatch
+		},
+	}
+}
+
+// cleanLocation returns location without `.`, `..` and empty path segments (see path.Clean),
+// keeping its trailing `/` (e.g. pointing at a whole secret), if any.
+func cleanLocation(location string) string {
+	if location == "" {
+		return location
+	}
+	cleaned := path.Clean(location)
+	if strings.HasSuffix(location, "/") && !strings.HasSuffix(cleaned, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// compileLocationPattern converts a location pattern (see WithPolicyAllowLocations) into a *regexp.Regexp.
+func compileLocationPattern(pattern string) *regexp.Regexp {
+	var re strings.Builder
+	re.WriteString("^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			re.WriteString(".*")
+			i++
+		case runes[i] == '*':
+			re.WriteString("[^/]*")
+		case runes[i] == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
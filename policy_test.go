@@ -0,0 +1,181 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// newValueSource returns a util.MockSource of type typ, digging up val.
+func newValueSource(typ, val string) *util.MockSource {
+	src := util.NewMockSource(typ)
+	src.Val = val
+	return src
+}
+
+func TestWithoutSource(t *testing.T) {
+	ctx := context.Background()
+	spelunker := spelunk.NewSpelunker(spelunk.WithoutSource("plain", "base64"))
+
+	for _, coordStr := range []string{"plain://val", "base64://dmFs"} {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		_, err = spelunker.DigUp(ctx, coord)
+		require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	}
+
+	coord, err := types.NewSecretCoord("mock://loc")
+	require.NoError(t, err)
+	_, err = spelunk.NewSpelunker(
+		spelunk.WithSource(newValueSource("mock", "val")),
+		spelunk.WithoutSource("plain"),
+	).DigUp(ctx, coord)
+	require.NoError(t, err)
+}
+
+func TestWithPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		opts     []spelunk.SpelunkerOption
+		coordStr string
+		wantRule string
+	}{
+		{
+			name:     "no policy",
+			coordStr: "plain://val",
+		},
+		{
+			name:     "strict rejects inline sources",
+			opts:     []spelunk.SpelunkerOption{spelunk.Strict()},
+			coordStr: "base64://dmFs",
+			wantRule: "inline secret source denied",
+		},
+		{
+			name:     "strict allows other sources",
+			opts:     []spelunk.SpelunkerOption{spelunk.Strict()},
+			coordStr: "mock://kv/data/team-a/db",
+		},
+		{
+			name: "denied source",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyDenySources("file", "mock")),
+			},
+			coordStr: "mock://kv/data/team-a/db",
+			wantRule: "source type denied",
+		},
+		{
+			name: "allowed location",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyAllowLocations("mock://kv/data/team-a/**")),
+			},
+			coordStr: "mock://kv/data/team-a/nested/db?mod=x",
+		},
+		{
+			name: "single star does not match nested locations",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyAllowLocations("mock://kv/data/team-a/*")),
+			},
+			coordStr: "mock://kv/data/team-a/nested/db",
+			wantRule: "not allowed by any rule",
+		},
+		{
+			name: "path traversal out of allowed location",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyAllowLocations("mock://kv/data/team-a/**")),
+			},
+			coordStr: "mock://kv/data/team-a/../team-b/secret",
+			wantRule: "not allowed by any rule",
+		},
+		{
+			name: "path traversal into denied location",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyDenyLocations("mock://kv/data/team-b/**")),
+			},
+			coordStr: "mock://kv/data/team-a/..//team-b/./secret",
+			wantRule: "location denied",
+		},
+		{
+			name: "allowed whole secret",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyAllowLocations("mock://kv/data/team-a/**")),
+			},
+			coordStr: "mock://kv/data/team-a/db/",
+		},
+		{
+			name: "location not allowed",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyAllowLocations("mock://kv/data/team-?/*")),
+			},
+			coordStr: "mock://kv/data/team-bb/db",
+			wantRule: "not allowed by any rule",
+		},
+		{
+			name: "allowed source, among allowed locations",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(
+					spelunk.WithPolicyAllowLocations("mock://kv/data/team-a/*"),
+					spelunk.WithPolicyAllowSources("env"),
+				),
+			},
+			coordStr: "env://HOME",
+		},
+		{
+			name: "deny wins over allow",
+			opts: []spelunk.SpelunkerOption{
+				spelunk.WithPolicy(spelunk.WithPolicyAllowSources("mock")),
+				spelunk.WithPolicy(spelunk.WithPolicyDenyLocations("mock://kv/data/team-a/admin*")),
+			},
+			coordStr: "mock://kv/data/team-a/admin-db",
+			wantRule: "location denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", "/home/test")
+			opts := append([]spelunk.SpelunkerOption{
+				spelunk.WithSource(newValueSource("mock", "val")),
+				spelunk.WithModifier(&mockModifier{typ: "mod"}),
+			}, tt.opts...)
+			spelunker := spelunk.NewSpelunker(opts...)
+
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+			_, err = spelunker.DigUp(ctx, coord)
+			if tt.wantRule == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, spelunk.ErrPolicyViolation)
+			var violation *spelunk.PolicyViolationError
+			require.True(t, errors.As(err, &violation))
+			require.Equal(t, coord.Type, violation.Type)
+			require.Equal(t, tt.wantRule, violation.Rule)
+			require.Equal(t, types.ErrorClassPermissionDenied, types.ClassifyError(err))
+		})
+	}
+}
+
+func TestWithPolicy_RedactsInlineLocations(t *testing.T) {
+	spelunker := spelunk.NewSpelunker(spelunk.Strict())
+
+	coord, err := types.NewSecretCoord("plain://super-secret")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(context.Background(), coord)
+
+	var violation *spelunk.PolicyViolationError
+	require.True(t, errors.As(err, &violation))
+	require.Equal(t, types.RedactedLocation, violation.Location)
+	require.NotContains(t, err.Error(), "super-secret")
+
+	_, err = spelunker.Watch(context.Background(), coord)
+	require.ErrorIs(t, err, spelunk.ErrPolicyViolation)
+}
@@ -0,0 +1,265 @@
+package spelunk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// ResolveTag is the struct tag used by Spelunker.Resolve to identify the fields to resolve.
+const ResolveTag = "spelunk"
+
+var (
+	ErrResolveInvalidTarget = fmt.Errorf("resolve target must be a non-nil pointer")
+	ErrResolveInvalidField  = fmt.Errorf("invalid field to resolve")
+	ErrFailedToResolve      = fmt.Errorf("failed to resolve secrets")
+)
+
+var (
+	secretCoordType = reflect.TypeFor[types.SecretCoord]()
+	secretType      = reflect.TypeFor[types.Secret]()
+	bytesType       = reflect.TypeFor[[]byte]()
+)
+
+// Resolve creates a new Spelunker, configured with the given SpelunkerOption,
+// and uses it to resolve all the secrets in target. See Spelunker.Resolve.
+func Resolve(ctx context.Context, target any, opts ...SpelunkerOption) error {
+	return NewSpelunker(opts...).Resolve(ctx, target)
+}
+
+// Resolve walks recursively the given target (a pointer to a struct, or to anything containing structs),
+// including nested structs, pointers, slices, arrays and maps, and digs up all the secrets it finds.
+//
+// Fields are resolved based on their type and the `spelunk` struct tag:
+//
+//	type Config struct {
+//		// A types.SecretCoord (or *types.SecretCoord) is resolved into the sibling field named by the tag
+//		PasswordCoord types.SecretCoord `spelunk:"Password"`
+//		Password      string
+//
+//		// A string containing coordinates is resolved into the sibling field named by the tag...
+//		TokenURI string `spelunk:"Token"`
+//		Token    []byte
+//
+//		// ... including a types.Secret (or *types.Secret), that can't leak by accident
+//		APIKeyURI string `spelunk:"APIKey"`
+//		APIKey    types.Secret
+//
+//		// ... or into the field itself, if the tag is empty
+//		DSN string `spelunk:""`
+//	}
+//
+// Fields receiving the secret value must be of type string, []byte, types.Secret or *types.Secret.
+// Every types.SecretCoord field must name its target field: one without the tag (or with an empty tag)
+// is reported as an error, unless its coordinates are empty. String fields without the tag, fields with
+// tag `spelunk:"-"`, unexported fields and fields with empty coordinates are left alone.
+//
+// Secrets are dug-up concurrently via Spelunker.DigUpAll. All failures are reported together,
+// in an error that names the path of each failing field (e.g. `DB.Replicas[1].PasswordCoord`).
+func (s *Spelunker) Resolve(ctx context.Context, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("%w: got %T", ErrResolveInvalidTarget, target)
+	}
+
+	r := &resolver{visited: make(map[uintptr]bool)}
+	r.walk(v, "")
+
+	coords := make(map[string]*types.SecretCoord, len(r.jobs))
+	for _, job := range r.jobs {
+		coords[job.path] = job.coord
+	}
+	results, err := s.DigUpAll(ctx, coords)
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+
+	for _, job := range r.jobs {
+		if val, found := results[job.path]; found {
+			job.set(val)
+		}
+	}
+	for _, finalize := range r.finalizers {
+		finalize()
+	}
+
+	if len(r.errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrFailedToResolve, errors.Join(r.errs...))
+	}
+	return nil
+}
+
+// resolveJob is a secret to dig-up, found at path, and how to set its value once dug-up.
+type resolveJob struct {
+	path  string
+	coord *types.SecretCoord
+	set   func(val string)
+}
+
+// resolver collects the resolveJob while walking a target.
+type resolver struct {
+	jobs []resolveJob
+	errs []error
+	// finalizers write back values that are not addressable (i.e. map values), once resolved
+	finalizers []func()
+	// visited keeps track of pointers already walked, to avoid cycles
+	visited map[uintptr]bool
+}
+
+func (r *resolver) walk(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || r.visited[v.Pointer()] {
+			return
+		}
+		r.visited[v.Pointer()] = true
+		r.walk(v.Elem(), path)
+	case reflect.Interface:
+		if !v.IsNil() {
+			r.walk(v.Elem(), path)
+		}
+	case reflect.Struct:
+		r.walkStruct(v, path)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			r.walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			key, elem := iter.Key(), iter.Value()
+
+			// Map values are not addressable: walk a copy, and write it back once resolved
+			elemCopy := reflect.New(elem.Type()).Elem()
+			elemCopy.Set(elem)
+			jobsBefore := len(r.jobs)
+			r.walk(elemCopy, fmt.Sprintf("%s[%s]", path, mapKeyString(key)))
+			if len(r.jobs) > jobsBefore {
+				r.finalizers = append(r.finalizers, func() {
+					v.SetMapIndex(key, elemCopy)
+				})
+			}
+		}
+	default:
+		// Nothing to resolve
+	}
+}
+
+func (r *resolver) walkStruct(v reflect.Value, path string) {
+	// Non-addressable structs (e.g. held by value in an interface) can't be set
+	if !v.CanAddr() {
+		return
+	}
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := v.Field(i)
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		tag, tagged := field.Tag.Lookup(ResolveTag)
+		if tag == "-" {
+			continue
+		}
+
+		switch {
+		case field.Type == secretCoordType ||
+			(field.Type.Kind() == reflect.Pointer && field.Type.Elem() == secretCoordType):
+			coord, _ := fieldValue.Addr().Interface().(*types.SecretCoord)
+			if field.Type.Kind() == reflect.Pointer {
+				coord, _ = fieldValue.Interface().(*types.SecretCoord)
+			}
+			if coord == nil || coord.Type == "" {
+				continue
+			}
+			if tag == "" {
+				// A forgotten tag would leave the secret unresolved, without anyone noticing
+				r.errs = append(r.errs, fmt.Errorf(
+					"%q: %w: the %q tag must name the field to resolve the coordinates into",
+					fieldPath,
+					ErrResolveInvalidField,
+					ResolveTag,
+				))
+				continue
+			}
+			r.addJob(v, fieldPath, tag, coord)
+		case field.Type.Kind() == reflect.String && tagged:
+			if fieldValue.String() == "" {
+				continue
+			}
+			coord, err := types.NewSecretCoord(fieldValue.String())
+			if err != nil {
+				r.errs = append(r.errs, fmt.Errorf("%q: %w", fieldPath, err))
+				continue
+			}
+			if tag == "" {
+				tag = field.Name
+			}
+			r.addJob(v, fieldPath, tag, coord)
+		default:
+			r.walk(fieldValue, fieldPath)
+		}
+	}
+}
+
+// addJob adds a resolveJob for the coord found at path, to be set into the
+// field of the given struct v with name targetName.
+func (r *resolver) addJob(
+	v reflect.Value,
+	path string,
+	targetName string,
+	coord *types.SecretCoord,
+) {
+	target := v.FieldByName(targetName)
+	if !target.IsValid() || !target.CanSet() {
+		r.errs = append(r.errs, fmt.Errorf(
+			"%q: %w: target field %q not found or not settable",
+			path,
+			ErrResolveInvalidField,
+			targetName,
+		))
+		return
+	}
+
+	var set func(val string)
+	switch {
+	case target.Kind() == reflect.String:
+		set = func(val string) { target.SetString(val) }
+	case target.Type() == bytesType:
+		set = func(val string) { target.SetBytes([]byte(val)) }
+	case target.Type() == secretType:
+		set = func(val string) { target.Set(reflect.ValueOf(types.NewSecret(val)).Elem()) }
+	case target.Kind() == reflect.Pointer && target.Type().Elem() == secretType:
+		set = func(val string) { target.Set(reflect.ValueOf(types.NewSecret(val))) }
+	default:
+		r.errs = append(r.errs, fmt.Errorf(
+			"%q: %w: target field %q must be of type string, []byte or types.Secret, got %s",
+			path,
+			ErrResolveInvalidField,
+			targetName,
+			target.Type(),
+		))
+		return
+	}
+
+	r.jobs = append(r.jobs, resolveJob{
+		path:  path,
+		coord: coord,
+		set:   set,
+	})
+}
+
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return fmt.Sprintf("%q", key.String())
+	}
+	return fmt.Sprintf("%v", key.Interface())
+}
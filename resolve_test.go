@@ -0,0 +1,179 @@
+package spelunk_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+type resolveDBConfig struct {
+	Host          string
+	PasswordCoord types.SecretCoord `spelunk:"Password"`
+	Password      string
+}
+
+type resolveConfig struct {
+	APIKey       string `spelunk:""`
+	TokenURI     string `spelunk:"Token"`
+	Token        []byte
+	SecretURI    string `spelunk:"Secret"`
+	Secret       types.Secret
+	SecretPtrURI string `spelunk:"SecretPtr"`
+	SecretPtr    *types.Secret
+	CertCoord    *types.SecretCoord `spelunk:"Cert"`
+	Cert         string
+	Untagged     string
+	Ignored      string            `spelunk:"-"`
+	EmptyCoord   types.SecretCoord `spelunk:"EmptyValue"`
+	EmptyValue   string
+	DB           resolveDBConfig
+	Replicas     []resolveDBConfig
+	ReplicaPtrs  []*resolveDBConfig
+	Tenants      map[string]resolveDBConfig
+	unexported   string `spelunk:""`
+}
+
+func TestSpelunker_Resolve(t *testing.T) {
+	mustCoord := func(coordStr string) types.SecretCoord {
+		coord, err := types.NewSecretCoord(coordStr)
+		require.NoError(t, err)
+		return *coord
+	}
+	certCoord := mustCoord("plain://cert")
+
+	cfg := resolveConfig{
+		APIKey:       "plain://api-key",
+		TokenURI:     "base64://dG9rZW4=",
+		SecretURI:    "plain://secret",
+		SecretPtrURI: "plain://secret-ptr",
+		CertCoord:    &certCoord,
+		Untagged:     "plain://untagged",
+		Ignored:      "plain://ignored",
+		DB: resolveDBConfig{
+			Host:          "plain://host",
+			PasswordCoord: mustCoord("plain://db-password"),
+		},
+		Replicas: []resolveDBConfig{
+			{PasswordCoord: mustCoord("plain://replica-0")},
+			{PasswordCoord: mustCoord("plain://replica-1")},
+		},
+		ReplicaPtrs: []*resolveDBConfig{
+			{PasswordCoord: mustCoord("plain://replica-ptr-0")},
+			nil,
+		},
+		Tenants: map[string]resolveDBConfig{
+			"a": {PasswordCoord: mustCoord("plain://tenant-a")},
+			"b": {Host: "b-host"},
+		},
+		unexported: "plain://unexported",
+	}
+
+	err := spelunk.Resolve(context.Background(), &cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, "api-key", cfg.APIKey)
+	require.Equal(t, "base64://dG9rZW4=", cfg.TokenURI)
+	require.Equal(t, []byte("token"), cfg.Token)
+	require.Equal(t, "secret", cfg.Secret.Reveal())
+	require.Equal(t, "secret-ptr", cfg.SecretPtr.Reveal())
+	require.Equal(t, "cert", cfg.Cert)
+	require.Equal(t, "plain://untagged", cfg.Untagged)
+	require.Equal(t, "plain://ignored", cfg.Ignored)
+	require.Empty(t, cfg.EmptyValue)
+	require.Equal(t, "plain://host", cfg.DB.Host)
+	require.Equal(t, "db-password", cfg.DB.Password)
+	require.Equal(t, "replica-0", cfg.Replicas[0].Password)
+	require.Equal(t, "replica-1", cfg.Replicas[1].Password)
+	require.Equal(t, "replica-ptr-0", cfg.ReplicaPtrs[0].Password)
+	require.Equal(t, "tenant-a", cfg.Tenants["a"].Password)
+	require.Equal(t, "b-host", cfg.Tenants["b"].Host)
+	require.Equal(t, "plain://unexported", cfg.unexported)
+}
+
+func TestSpelunker_Resolve_Errors(t *testing.T) {
+	type badTargets struct {
+		MissingCoord *types.SecretCoord `spelunk:"Missing"`
+		WrongCoord   *types.SecretCoord `spelunk:"Wrong"`
+		Wrong        int
+	}
+	type config struct {
+		Broken   string `spelunk:""`
+		Missing  string `spelunk:""`
+		Nested   badTargets
+		Replicas []struct {
+			Value string `spelunk:""`
+		}
+	}
+
+	coord, err := types.NewSecretCoord("plain://value")
+	require.NoError(t, err)
+	cfg := config{
+		Broken:  "not-a-coordinate",
+		Missing: "env://SPELUNK_RESOLVE_TEST_NON_EXISTENT_VAR",
+		Nested: badTargets{
+			MissingCoord: coord,
+			WrongCoord:   coord,
+		},
+		Replicas: []struct {
+			Value string `spelunk:""`
+		}{
+			{Value: "plain://ok"},
+			{Value: "unknown://ko"},
+		},
+	}
+
+	err = spelunk.NewSpelunker().Resolve(context.Background(), &cfg)
+	require.ErrorIs(t, err, spelunk.ErrFailedToResolve)
+	require.ErrorIs(t, err, types.ErrSecretCoordHaveNoType)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+	require.ErrorIs(t, err, spelunk.ErrResolveInvalidField)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+	require.ErrorContains(t, err, `"Broken"`)
+	require.ErrorContains(t, err, `"Missing"`)
+	require.ErrorContains(t, err, `"Nested.MissingCoord"`)
+	require.ErrorContains(t, err, `"Nested.WrongCoord"`)
+	require.ErrorContains(t, err, `"Replicas[1].Value"`)
+
+	// Successful fields are resolved regardless
+	require.Equal(t, "ok", cfg.Replicas[0].Value)
+}
+
+func TestSpelunker_Resolve_UntaggedCoord(t *testing.T) {
+	type config struct {
+		PasswordCoord types.SecretCoord
+		TokenCoord    *types.SecretCoord `spelunk:""`
+		EmptyCoord    types.SecretCoord
+		IgnoredCoord  types.SecretCoord `spelunk:"-"`
+		Password      string
+	}
+
+	coord, err := types.NewSecretCoord("plain://value")
+	require.NoError(t, err)
+	cfg := config{
+		PasswordCoord: *coord,
+		TokenCoord:    coord,
+		IgnoredCoord:  *coord,
+	}
+
+	err = spelunk.NewSpelunker().Resolve(context.Background(), &cfg)
+	require.ErrorIs(t, err, spelunk.ErrFailedToResolve)
+	require.ErrorIs(t, err, spelunk.ErrResolveInvalidField)
+	require.ErrorContains(t, err, `"PasswordCoord"`)
+	require.ErrorContains(t, err, `"TokenCoord"`)
+	require.NotContains(t, err.Error(), `"EmptyCoord"`)
+	require.NotContains(t, err.Error(), `"IgnoredCoord"`)
+	require.Empty(t, cfg.Password)
+}
+
+func TestSpelunker_Resolve_InvalidTarget(t *testing.T) {
+	spelunker := spelunk.NewSpelunker()
+
+	var nilCfg *resolveConfig
+	for _, target := range []any{nil, resolveConfig{}, nilCfg} {
+		err := spelunker.Resolve(context.Background(), target)
+		require.ErrorIs(t, err, spelunk.ErrResolveInvalidTarget)
+	}
+}
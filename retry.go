@@ -0,0 +1,262 @@
+package spelunk
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+const (
+	// defaultRetryMaxAttempts is the maximum amount of attempts at digging up a secret, unless configured otherwise.
+	defaultRetryMaxAttempts = 3
+
+	// defaultRetryInitialBackoff is the wait before the first retry, unless configured otherwise.
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+
+	// defaultRetryMaxBackoff is the upper bound of the wait between retries, unless configured otherwise.
+	defaultRetryMaxBackoff = 5 * time.Second
+
+	// defaultCircuitBreakerThreshold is the amount of consecutive transient failures that opens a circuit breaker.
+	defaultCircuitBreakerThreshold = 5
+
+	// defaultCircuitBreakerCooldown is how long a circuit breaker stays open.
+	defaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned while the circuit breaker of a source is open.
+// It wraps types.ErrTransient, as the source is called again once the cooldown is over.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", types.ErrTransient)
+
+// RetryOption options that can be provided to WithRetry.
+type RetryOption func(*retryOptions)
+
+type retryOptions struct {
+	maxAttempts      int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	breakerThreshold int
+	breakerCooldown  time.Duration
+}
+
+// WithRetryMaxAttempts sets the maximum amount of attempts at digging up a secret,
+// including the first one. A value < 1 is treated as 1 (i.e. no retries).
+//
+// Default is 3.
+func WithRetryMaxAttempts(maxAttempts int) RetryOption {
+	return func(o *retryOptions) {
+		o.maxAttempts = max(maxAttempts, 1)
+	}
+}
+
+// WithRetryBackoff sets the wait before the first retry, and its upper bound:
+// the wait doubles at every retry, and is jittered to spread retries of concurrent callers.
+//
+// Default is 100 milliseconds and 5 seconds.
+func WithRetryBackoff(initial, maxBackoff time.Duration) RetryOption {
+	return func(o *retryOptions) {
+		o.initialBackoff = initial
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithRetryCircuitBreaker sets after how many consecutive transient failures of a source
+// its circuit breaker opens, and for how long it stays open.
+// While open, dig-ups from that source fail immediately with ErrCircuitOpen.
+// A threshold <= 0 disables the circuit breakers.
+//
+// Default is 5 failures and 30 seconds.
+func WithRetryCircuitBreaker(threshold int, cooldown time.Duration) RetryOption {
+	return func(o *retryOptions) {
+		o.breakerThreshold = threshold
+		o.breakerCooldown = cooldown
+	}
+}
+
+// WithRetry enables retrying dig-ups that fail with a transient error (i.e. classified as types.ErrorClassTransient,
+// like timeouts of a single attempt), with jittered exponential backoff. Any other failure, like types.ErrSecretNotFound or
+// types.ErrInvalidLocation, is returned immediately.
+// It can be configured providing one or more RetryOption.
+//
+// Each source (by scheme, see WithSourceAs) has its own circuit breaker: after too many consecutive transient failures,
+// the source is not called for a while, and dig-ups fail fast with ErrCircuitOpen.
+//
+// Retrying is a SourceMiddleware: see WithSourceMiddleware for how it composes with others.
+// When used with WithCache, add the cache first so that cached values skip retrying altogether.
+func WithRetry(opts ...RetryOption) SpelunkerOption {
+	return func(o *options) {
+		o.sourceMiddlewares = append(o.sourceMiddlewares, newRetrier(opts...).middleware)
+	}
+}
+
+// retrier retries transient failures of sources, and keeps a circuit breaker per source scheme.
+type retrier struct {
+	opts retryOptions
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+func newRetrier(opts ...RetryOption) *retrier {
+	r := &retrier{
+		opts: retryOptions{
+			maxAttempts:      defaultRetryMaxAttempts,
+			initialBackoff:   defaultRetryInitialBackoff,
+			maxBackoff:       defaultRetryMaxBackoff,
+			breakerThreshold: defaultCircuitBreakerThreshold,
+			breakerCooldown:  defaultCircuitBreakerCooldown,
+		},
+		breakers: make(map[string]*circuitBreaker),
+	}
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	return r
+}
+
+func (r *retrier) breaker(sourceType string) *circuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, found := r.breakers[sourceType]
+	if !found {
+		b = &circuitBreaker{
+			threshold: r.opts.breakerThreshold,
+			cooldown:  r.opts.breakerCooldown,
+		}
+		r.breakers[sourceType] = b
+	}
+	return b
+}
+
+// backoff returns the jittered wait before the given retry (1-based).
+func (r *retrier) backoff(retry int) time.Duration {
+	wait := r.opts.initialBackoff
+	for i := 1; i < retry && wait < r.opts.maxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, r.opts.maxBackoff)
+	if wait <= 0 {
+		return 0
+	}
+
+	// Equal jitter: wait at least half, plus a random part of the other half
+	half := wait / 2
+	return half + rand.N(wait-half+1)
+}
+
+// middleware is the SourceMiddleware retrying transient failures.
+func (r *retrier) middleware(next SourceHandler) SourceHandler {
+	return func(
+		ctx context.Context,
+		source types.SecretSource,
+		coord types.SecretCoord,
+	) (string, error) {
+		breaker := r.breaker(coord.Type)
+
+		var err error
+		for attempt := 1; attempt <= r.opts.maxAttempts; attempt++ {
+			if attempt > 1 {
+				timer := time.NewTimer(r.backoff(attempt - 1))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return "", fmt.Errorf("%w: %w", err, ctx.Err())
+				case <-timer.C:
+				}
+			}
+
+			if retryIn := breaker.allow(); retryIn > 0 {
+				return "", fmt.Errorf(
+					"%w (%q): retry in %s",
+					ErrCircuitOpen,
+					coord.Type,
+					retryIn.Round(time.Millisecond),
+				)
+			}
+
+			var val string
+			val, err = next(ctx, source, coord)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				// The caller gave up: that says nothing about the source
+				breaker.release()
+				return val, err
+			case err == nil || types.ClassifyError(err) != types.ErrorClassTransient:
+				// Any non-transient outcome proves the source is reachable
+				breaker.succeed()
+				return val, err
+			}
+			breaker.fail()
+		}
+		return "", err
+	}
+}
+
+// circuitBreaker tracks consecutive transient failures of a source.
+//
+// Once threshold is reached, it opens for cooldown. After that, it lets one
+// call through (half-open): if it succeeds the breaker closes, otherwise it opens again.
+// A call abandoned by its caller (i.e. context canceled) leaves it as it is.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow returns 0 if a call can go through, or how long until the breaker lets calls through again.
+func (b *circuitBreaker) allow() time.Duration {
+	if b.threshold <= 0 {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return 0
+	}
+	if retryIn := time.Until(b.openUntil); retryIn > 0 {
+		return retryIn
+	}
+	// Half-open: let a single probing call through
+	if b.probing {
+		return b.cooldown
+	}
+	b.probing = true
+	return 0
+}
+
+func (b *circuitBreaker) succeed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// release lets another call through, if half-open, without recording any outcome.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *circuitBreaker) fail() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,275 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+// flakySource implements types.SecretSource for testing.
+// It fails with err for the first `failures` calls, then returns val.
+type flakySource struct {
+	typ      string
+	val      string
+	err      error
+	failures int64
+	calls    atomic.Int64
+}
+
+func (f *flakySource) Type() string {
+	return f.typ
+}
+
+func (f *flakySource) DigUp(_ context.Context, _ types.SecretCoord) (string, error) {
+	if f.calls.Add(1) <= f.failures {
+		return "", f.err
+	}
+	return f.val, nil
+}
+
+var errTransientTest = fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrTransient)
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		err       error
+		failures  int64
+		opts      []spelunk.RetryOption
+		want      string
+		errMatch  error
+		wantCalls int64
+	}{
+		{
+			name:      "success at first attempt",
+			failures:  0,
+			want:      "secret-value",
+			wantCalls: 1,
+		},
+		{
+			name:      "transient failures are retried",
+			err:       errTransientTest,
+			failures:  2,
+			want:      "secret-value",
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after max attempts",
+			err:       errTransientTest,
+			failures:  10,
+			opts:      []spelunk.RetryOption{spelunk.WithRetryMaxAttempts(4)},
+			errMatch:  types.ErrTransient,
+			wantCalls: 4,
+		},
+		{
+			name:      "not found is not retried",
+			err:       types.ErrSecretNotFound,
+			failures:  10,
+			errMatch:  types.ErrSecretNotFound,
+			wantCalls: 1,
+		},
+		{
+			name:      "invalid location is not retried",
+			err:       types.ErrInvalidLocation,
+			failures:  10,
+			errMatch:  types.ErrInvalidLocation,
+			wantCalls: 1,
+		},
+		{
+			name:      "unclassified failures are not retried",
+			err:       types.ErrCouldNotFetchSecret,
+			failures:  10,
+			errMatch:  types.ErrCouldNotFetchSecret,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &flakySource{typ: "test", val: "secret-value", err: tt.err, failures: tt.failures}
+			opts := append([]spelunk.RetryOption{
+				spelunk.WithRetryBackoff(time.Millisecond, 5*time.Millisecond),
+			}, tt.opts...)
+
+			spelunker := spelunk.NewSpelunker(
+				spelunk.WithSource(src),
+				spelunk.WithRetry(opts...),
+			)
+
+			coord, err := types.NewSecretCoord("test://loc")
+			require.NoError(t, err)
+			got, err := spelunker.DigUp(ctx, coord)
+
+			require.Equal(t, tt.wantCalls, src.calls.Load())
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, spelunk.ErrFailedToDigUpSecret)
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithRetry_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	src := &flakySource{typ: "test", err: errTransientTest, failures: 10}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithRetry(
+			spelunk.WithRetryMaxAttempts(10),
+			spelunk.WithRetryBackoff(time.Hour, time.Hour),
+		),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(ctx, coord)
+	require.ErrorIs(t, err, types.ErrTransient)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, int64(1), src.calls.Load())
+}
+
+func TestWithRetry_AttemptTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	// A timeout of the attempt (e.g. of the SDK), while the caller is still waiting, is retried
+	src := &flakySource{
+		typ:      "test",
+		val:      "secret-value",
+		err:      fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, context.DeadlineExceeded),
+		failures: 1,
+	}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithRetry(spelunk.WithRetryBackoff(time.Millisecond, time.Millisecond)),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "secret-value", got)
+	require.Equal(t, int64(2), src.calls.Load())
+}
+
+func TestWithRetry_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	failing := &flakySource{typ: "failing", err: errTransientTest, failures: 4}
+	healthy := &flakySource{typ: "healthy", val: "healthy-value"}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(failing),
+		spelunk.WithSource(healthy),
+		spelunk.WithRetry(
+			spelunk.WithRetryMaxAttempts(1),
+			spelunk.WithRetryCircuitBreaker(2, 50*time.Millisecond),
+		),
+	)
+
+	failingCoord, err := types.NewSecretCoord("failing://loc")
+	require.NoError(t, err)
+	healthyCoord, err := types.NewSecretCoord("healthy://loc")
+	require.NoError(t, err)
+
+	// Consecutive transient failures open the breaker
+	for range 2 {
+		_, err = spelunker.DigUp(ctx, failingCoord)
+		require.ErrorIs(t, err, types.ErrTransient)
+	}
+	_, err = spelunker.DigUp(ctx, failingCoord)
+	require.ErrorIs(t, err, spelunk.ErrCircuitOpen)
+	require.Equal(t, types.ErrorClassTransient, types.ClassifyError(err))
+	require.Equal(t, int64(2), failing.calls.Load())
+
+	// Breakers are per source type
+	got, err := spelunker.DigUp(ctx, healthyCoord)
+	require.NoError(t, err)
+	require.Equal(t, "healthy-value", got)
+
+	// After the cooldown, a failing probe opens the breaker again...
+	time.Sleep(60 * time.Millisecond)
+	_, err = spelunker.DigUp(ctx, failingCoord)
+	require.ErrorIs(t, err, types.ErrTransient)
+	_, err = spelunker.DigUp(ctx, failingCoord)
+	require.ErrorIs(t, err, spelunk.ErrCircuitOpen)
+	require.Equal(t, int64(3), failing.calls.Load())
+
+	// ... while a succeeding one closes it
+	failing.failures = 3
+	time.Sleep(60 * time.Millisecond)
+	_, err = spelunker.DigUp(ctx, failingCoord)
+	require.NoError(t, err)
+	_, err = spelunker.DigUp(ctx, failingCoord)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), failing.calls.Load())
+}
+
+func TestWithRetry_CircuitBreakerContextCanceled(t *testing.T) {
+	ctx := context.Background()
+
+	src := &flakySource{typ: "test", err: errTransientTest, failures: 100}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithRetry(
+			spelunk.WithRetryMaxAttempts(1),
+			spelunk.WithRetryCircuitBreaker(2, 50*time.Millisecond),
+		),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	for range 2 {
+		_, err = spelunker.DigUp(ctx, coord)
+		require.ErrorIs(t, err, types.ErrTransient)
+	}
+
+	// A probe abandoned by its caller neither closes nor opens the breaker...
+	time.Sleep(60 * time.Millisecond)
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	src.err = fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, context.Canceled)
+	_, err = spelunker.DigUp(canceledCtx, coord)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// ... so the next call probes the source again, and a failure opens it straight away
+	src.err = errTransientTest
+	_, err = spelunker.DigUp(ctx, coord)
+	require.ErrorIs(t, err, types.ErrTransient)
+	_, err = spelunker.DigUp(ctx, coord)
+	require.ErrorIs(t, err, spelunk.ErrCircuitOpen)
+	require.Equal(t, int64(4), src.calls.Load())
+}
+
+func TestWithRetry_CircuitBreakerDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	src := &flakySource{typ: "test", err: errTransientTest, failures: 100}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithRetry(
+			spelunk.WithRetryMaxAttempts(1),
+			spelunk.WithRetryCircuitBreaker(0, time.Hour),
+		),
+	)
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	for range 10 {
+		_, err = spelunker.DigUp(ctx, coord)
+		require.ErrorIs(t, err, types.ErrTransient)
+		require.False(t, errors.Is(err, spelunk.ErrCircuitOpen))
+	}
+	require.Equal(t, int64(10), src.calls.Load())
+}
@@ -2,6 +2,7 @@ package spelunk
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -18,6 +19,12 @@ var (
 // Spelunker digs up secrets from given SecretCoord.
 type Spelunker struct {
 	opts options
+
+	// digUp, digUpSource and modify are the chains of middlewares wrapping
+	// the whole dig-up, sources and modifiers
+	digUp       DigUpHandler
+	digUpSource SourceHandler
+	modify      ModifierHandler
 }
 
 // NewSpelunker creates a new Spelunker.
@@ -29,40 +36,121 @@ func NewSpelunker(opts ...SpelunkerOption) *Spelunker {
 		apply(defaultOptions()...).
 		apply(opts...)
 
+	if s.opts.cache != nil {
+		s.opts.cache.observers = s.opts.cacheObservers
+	}
+	digUpMiddlewares := s.opts.digUpMiddlewares
+	if len(s.opts.auditSinks) > 0 {
+		// Auditing is the outermost middleware, to record every access
+		digUpMiddlewares = append([]DigUpMiddleware{s.auditMiddleware}, digUpMiddlewares...)
+	}
+	s.digUp = chainDigUpMiddlewares(s.digUpChained, digUpMiddlewares)
+	s.digUpSource = chainSourceMiddlewares(s.opts.sourceMiddlewares)
+	s.modify = chainModifierMiddlewares(s.opts.modifierMiddlewares)
+
 	return s
 }
 
 // DigUp digs up a secret using the given *SecretCoord.
+//
+// If the source reports the secret (or its key) as not found, and the coordinates have a fallback
+// (see types.ModifierDefault and types.ModifierOptional), the fallback is returned instead: no modifier is applied to it.
+// Any other failure (e.g. an authentication failure) is returned regardless.
 func (s *Spelunker) DigUp(ctx context.Context, coord *types.SecretCoord) (string, error) {
+	return s.digUp(ctx, coord)
+}
+
+// DigUpSecret digs up a secret using the given *SecretCoord, like DigUp,
+// but returns it as a *types.Secret: it can't leak by accident into logs, output or errors.
+func (s *Spelunker) DigUpSecret(ctx context.Context, coord *types.SecretCoord) (*types.Secret, error) {
+	val, err := s.DigUp(ctx, coord)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewSecret(val), nil
+}
+
+// digUpChained is the DigUpHandler wrapped by the chain of DigUpMiddleware.
+func (s *Spelunker) digUpChained(ctx context.Context, coord *types.SecretCoord) (string, error) {
 	// Identify the source of the secret
 	source, found := s.opts.sources[coord.Type]
 	if !found {
-		return "", fmt.Errorf("%w: %q", ErrUnsupportedSecretSourceType, coord.Type)
+		// Locations of unsupported sources are redacted, as it's unknown what they contain
+		return "", types.NewDigUpError(
+			types.ErrorStageSource,
+			coord.Type,
+			types.RedactedLocation,
+			fmt.Errorf("%w: %q", ErrUnsupportedSecretSourceType, coord.Type),
+		)
+	}
+	newDigUpError := func(stage types.ErrorStage, err error) error {
+		return types.NewDigUpError(stage, coord.Type, types.RedactLocation(source, *coord), err)
+	}
+
+	// Enforce the policy, if any
+	if err := s.opts.policy.check(source, coord); err != nil {
+		return "", newDigUpError(types.ErrorStageSource, err)
+	}
+	if err := checkParams(source, coord); err != nil {
+		return "", newDigUpError(types.ErrorStageSource, err)
 	}
 
 	// Dig-up the secret from the source
-	val, err := source.DigUp(ctx, *coord)
+	val, err := s.digUpSource(ctx, source, *coord)
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrFailedToDigUpSecret, err)
+		// Secrets not found fall back to the default value, if any, returned as is
+		fallback, hasFallback := coord.Fallback()
+		if hasFallback && (errors.Is(err, types.ErrSecretNotFound) || errors.Is(err, types.ErrSecretKeyNotFound)) {
+			return fallback, nil
+		}
+		return "", newDigUpError(
+			types.ErrorStageSource,
+			fmt.Errorf("%w: %w", ErrFailedToDigUpSecret, err),
+		)
 	}
 
 	// Apply modifiers, if any
 	for _, mod := range coord.Modifiers {
+		if types.IsReservedModifier(mod[0]) {
+			continue
+		}
 		modifier, found := s.opts.modifiers[mod[0]]
 		if !found {
-			return "", fmt.Errorf("%w: %q", ErrUnsupportedSecretModifierType, mod[0])
+			return "", newDigUpError(
+				types.ErrorStageModifier,
+				fmt.Errorf("%w: %q", ErrUnsupportedSecretModifierType, mod[0]),
+			)
 		}
 
-		val, err = modifier.Modify(ctx, val, mod[1])
+		val, err = s.modify(ctx, modifier, *coord, val, mod[1])
 		if err != nil {
-			return "", fmt.Errorf("%w: %w", ErrFailedToApplyModifier, err)
+			return "", newDigUpError(
+				types.ErrorStageModifier,
+				fmt.Errorf("%w: %w", ErrFailedToApplyModifier, err),
+			)
 		}
 	}
 
-	// Lastly, trim value if configured
-	if s.opts.trimValue {
+	// Lastly, trim value if configured (never raw bytes)
+	if s.opts.trimValue && !IsBytesMode(ctx) {
 		val = strings.TrimSpace(val)
 	}
 
 	return val, nil
 }
+
+// Invalidate evicts from the cache the value dug-up for the given *SecretCoord.
+// It has no effect if caching was not enabled via WithCache.
+func (s *Spelunker) Invalidate(coord *types.SecretCoord) {
+	if s.opts.cache != nil {
+		s.opts.cache.invalidate(coord)
+	}
+}
+
+// Purge evicts all values from the cache.
+// It has no effect if caching was not enabled via WithCache.
+func (s *Spelunker) Purge() {
+	if s.opts.cache != nil {
+		s.opts.cache.purge()
+	}
+}
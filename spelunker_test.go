@@ -3,6 +3,7 @@ package spelunk_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/detro/spelunk/v2"
@@ -146,3 +147,210 @@ func TestSpelunker_DigUp(t *testing.T) {
 		})
 	}
 }
+
+func TestSpelunker_DigUpSecret(t *testing.T) {
+	ctx := context.Background()
+
+	src := util.NewMockSource("test")
+	src.Val = "  secret-value  "
+	failing := util.NewMockSource("fail")
+	failing.Err = types.ErrSecretNotFound
+	spelunker := spelunk.NewSpelunker(spelunk.WithSource(src), spelunk.WithSource(failing))
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	secret, err := spelunker.DigUpSecret(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, "secret-value", secret.Reveal())
+	require.Equal(t, types.Redacted, secret.String())
+
+	coord, err = types.NewSecretCoord("fail://loc")
+	require.NoError(t, err)
+	secret, err = spelunker.DigUpSecret(ctx, coord)
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+	require.Nil(t, secret)
+}
+
+func TestSpelunker_DigUp_DigUpError(t *testing.T) {
+	ctx := context.Background()
+
+	notFound := util.NewMockSource("fail")
+	notFound.Err = fmt.Errorf("%w (%q)", types.ErrSecretNotFound, "loc")
+	spelunker := spelunk.NewSpelunker(spelunk.WithSource(notFound))
+
+	tests := []struct {
+		name      string
+		coordStr  string
+		errMatch  error
+		wantType  string
+		wantLoc   string
+		wantStage types.ErrorStage
+		wantClass types.ErrorClass
+	}{
+		{
+			name:      "source fails",
+			coordStr:  "fail://loc",
+			errMatch:  types.ErrSecretNotFound,
+			wantType:  "fail",
+			wantLoc:   "loc",
+			wantStage: types.ErrorStageSource,
+			wantClass: types.ErrorClassNotFound,
+		},
+		{
+			name:      "unsupported source type",
+			coordStr:  "unknown://loc",
+			errMatch:  spelunk.ErrUnsupportedSecretSourceType,
+			wantType:  "unknown",
+			wantLoc:   types.RedactedLocation,
+			wantStage: types.ErrorStageSource,
+			wantClass: types.ErrorClassUnknown,
+		},
+		{
+			name:      "modifier fails on inline secret",
+			coordStr:  "plain://not-base64!?b64d",
+			errMatch:  spelunk.ErrFailedToApplyModifier,
+			wantType:  "plain",
+			wantLoc:   types.RedactedLocation,
+			wantStage: types.ErrorStageModifier,
+			wantClass: types.ErrorClassUnknown,
+		},
+		{
+			name:      "unsupported modifier type",
+			coordStr:  "plain://value?unknown",
+			errMatch:  spelunk.ErrUnsupportedSecretModifierType,
+			wantType:  "plain",
+			wantLoc:   types.RedactedLocation,
+			wantStage: types.ErrorStageModifier,
+			wantClass: types.ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			_, err = spelunker.DigUp(ctx, coord)
+			require.ErrorIs(t, err, tt.errMatch)
+
+			digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+			require.True(t, errMatched)
+			require.Equal(t, tt.wantType, digUpErr.Type)
+			require.Equal(t, tt.wantLoc, digUpErr.Location)
+			require.Equal(t, tt.wantStage, digUpErr.Stage)
+			require.Equal(t, tt.wantClass, digUpErr.Class)
+		})
+	}
+}
+
+func TestSpelunker_DigUp_Fallback(t *testing.T) {
+	ctx := context.Background()
+
+	notFound := util.NewMockSource("notfound")
+	notFound.Err = fmt.Errorf("%w (%q)", types.ErrSecretNotFound, "loc")
+	keyNotFound := util.NewMockSource("keynotfound")
+	keyNotFound.Err = fmt.Errorf("%w: %q", types.ErrSecretKeyNotFound, "key")
+	denied := util.NewMockSource("denied")
+	denied.Err = fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrPermissionDenied)
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(notFound),
+		spelunk.WithSource(keyNotFound),
+		spelunk.WithSource(denied),
+	)
+
+	tests := []struct {
+		name     string
+		coordStr string
+		want     string
+		errMatch error
+	}{
+		{
+			name:     "default",
+			coordStr: "notfound://loc?default=fallback",
+			want:     "fallback",
+		},
+		{
+			name:     "default is not modified",
+			coordStr: "keynotfound://loc/key?b64d&default=%20not-base64!",
+			want:     " not-base64!",
+		},
+		{
+			name:     "optional",
+			coordStr: "notfound://loc?optional",
+			want:     "",
+		},
+		{
+			name:     "last fallback wins",
+			coordStr: "notfound://loc?optional&default=fallback",
+			want:     "fallback",
+		},
+		{
+			name:     "found secrets ignore the fallback",
+			coordStr: "plain://val?b64e&default=fallback",
+			want:     "dmFs",
+		},
+		{
+			name:     "other failures are returned",
+			coordStr: "denied://loc?default=fallback",
+			errMatch: types.ErrPermissionDenied,
+		},
+		{
+			name:     "no fallback",
+			coordStr: "notfound://loc",
+			errMatch: types.ErrSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			got, err := spelunker.DigUp(ctx, coord)
+			if tt.errMatch != nil {
+				require.ErrorIs(t, err, tt.errMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpelunker_DigUp_WithSourceAs(t *testing.T) {
+	ctx := context.Background()
+
+	eu := util.NewMockSource("vault")
+	eu.Val = "eu-value"
+	us := util.NewMockSource("vault")
+	us.Val = "us-value"
+	def := util.NewMockSource("vault")
+	def.Val = "default-value"
+
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(def),
+		spelunk.WithSourceAs("vault-eu", eu),
+		spelunk.WithSourceAs("vault-us", us),
+	)
+
+	tests := []struct {
+		coordStr string
+		want     string
+	}{
+		{coordStr: "vault://kv/data/x/key", want: "default-value"},
+		{coordStr: "vault-eu://kv/data/x/key", want: "eu-value"},
+		{coordStr: "vault-us://kv/data/x/key", want: "us-value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.coordStr, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+			got, err := spelunker.DigUp(ctx, coord)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+	require.Equal(t, 1, eu.Calls())
+	require.Equal(t, 1, us.Calls())
+	require.Equal(t, 1, def.Calls())
+}
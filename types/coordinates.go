@@ -2,8 +2,11 @@ package types
 
 import (
 	"encoding"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strings"
 )
 
@@ -14,25 +17,76 @@ var (
 		"coordinates point to no location (missing 'authority+path' in URI)",
 	)
 	ErrSecretCoordFailedParsingModifiers = fmt.Errorf("failed to parse modifiers")
+	ErrSecretCoordFailedParsingParams    = fmt.Errorf("failed to parse parameters")
 )
 
+// ParamPrefix marks the query pairs of coordinates that are parameters of the source
+// (see SecretCoord.Params), rather than modifiers (e.g. "vault://kv/data/app?@version=3").
+const ParamPrefix = "@"
+
+// ParamVersion is the parameter selecting the version of a secret, for the sources that keep
+// more than one (e.g. "gcp://projects/p/secrets/s?@version=3").
+const ParamVersion = "version"
+
+// Reserved modifier types: they are handled by the Spelunker itself, rather than
+// by a SecretModifier, and are never applied to the value of the secret.
+const (
+	// ModifierDefault sets the value returned when the secret is not found (e.g. "env://PORT?default=8080").
+	ModifierDefault = "default"
+	// ModifierOptional makes the secret optional: an empty value is returned when it's not found.
+	ModifierOptional = "optional"
+)
+
+// IsReservedModifier returns true if modType is a reserved modifier type (see ModifierDefault and ModifierOptional).
+func IsReservedModifier(modType string) bool {
+	return modType == ModifierDefault || modType == ModifierOptional
+}
+
 // SecretCoord are the coordinates to a secret.
 // Coordinates have a Type (to determine the source of the secret),
-// a Location (to determine how to get to it) and optional Modifiers.
+// a Location (to determine how to get to it), optional Params for the source (see ParamPrefix)
+// and optional Modifiers.
 //
-// This type implements encoding.TextUnmarshaler, so it
-// can be decoded by any idiomatic Go codebase from plain text
-// and even as part of json.Unmarshal calls.
+// This type implements encoding.TextUnmarshaler and encoding.TextMarshaler, so it
+// can be decoded and encoded by any idiomatic Go codebase from and to plain text,
+// and even as part of json.(Un)Marshal calls. It also implements yaml.Marshaler.
 type SecretCoord struct {
-	Type      string
-	Location  string
+	Type     string
+	Location string
+	// Params are the parameters of the source, by name (without ParamPrefix): nil if there are none.
+	// Only sources implementing ParamSource accept them.
+	Params    map[string]string
 	Modifiers [][2]string
 }
 
+// Param returns the value of the parameter of the given name, or "" if it's not set.
+func (sc *SecretCoord) Param(name string) string {
+	return sc.Params[name]
+}
+
+// Fallback returns the value to use when the secret is not found, and true,
+// if the coordinates have a ModifierDefault or ModifierOptional (the last one wins).
+func (sc *SecretCoord) Fallback() (string, bool) {
+	var fallback string
+	var hasFallback bool
+	for _, mod := range sc.Modifiers {
+		switch mod[0] {
+		case ModifierDefault:
+			fallback, hasFallback = mod[1], true
+		case ModifierOptional:
+			fallback, hasFallback = "", true
+		}
+	}
+	return fallback, hasFallback
+}
+
 func (sc *SecretCoord) String() string {
 	res := strings.Builder{}
 	res.WriteString("t=" + sc.Type)
 	res.WriteString(" l=" + sc.Location)
+	for _, name := range slices.Sorted(maps.Keys(sc.Params)) {
+		fmt.Fprintf(&res, " p[%s]=%s", name, sc.Params[name])
+	}
 	for idx, mod := range sc.Modifiers {
 		fmt.Fprintf(&res, " m[%d]=%s:%s", idx, mod[0], mod[1])
 	}
@@ -43,13 +97,27 @@ func (sc *SecretCoord) String() string {
 //
 // Splunker will then dig-up the secret using the correct SecretSource, identified using the SecretCoord.Type (scheme).
 // The specific SecretSource will then use the SecretCoord.Location (authority + path)
-// and the SecretCoord.Modifiers (query) to finish the dig-up.
+// and the SecretCoord.Params (query pairs prefixed by ParamPrefix) to finish the dig-up,
+// before the SecretCoord.Modifiers (all other query pairs) are applied.
 //
 // Each SecretSource defines the URI format it supports.
+//
+// Failures are returned as *DigUpError, at ErrorStageParse.
 func NewSecretCoord(secretCoordURI string) (*SecretCoord, error) {
+	coord, err := parseSecretCoord(secretCoordURI)
+	if err != nil {
+		// The coordinates might be inline secrets: their location is never exposed,
+		// neither in the DigUpError nor in the error it wraps
+		return nil, NewDigUpError(ErrorStageParse, "", RedactedLocation, err)
+	}
+	return coord, nil
+}
+
+func parseSecretCoord(secretCoordURI string) (*SecretCoord, error) {
 	u, err := url.Parse(secretCoordURI)
 	if err != nil {
-		return nil, fmt.Errorf("%w (%q): %w", ErrSecretCoordFailedParsing, secretCoordURI, err)
+		// The error of url.Parse echoes the input: it's not wrapped
+		return nil, fmt.Errorf("%w: not a valid URI", ErrSecretCoordFailedParsing)
 	}
 
 	loc := fmt.Sprintf("%s%s", u.Host, u.Path)
@@ -63,10 +131,10 @@ func NewSecretCoord(secretCoordURI string) (*SecretCoord, error) {
 		Modifiers: make([][2]string, 0),
 	}
 	if len(coord.Type) == 0 {
-		return nil, fmt.Errorf("%w: %q", ErrSecretCoordHaveNoType, secretCoordURI)
+		return nil, fmt.Errorf("%w: expected <TYPE>://<LOCATION>", ErrSecretCoordHaveNoType)
 	}
 	if len(coord.Location) == 0 {
-		return nil, fmt.Errorf("%w: %q", ErrSecretCoordHaveNoLocation, secretCoordURI)
+		return nil, fmt.Errorf("%w: expected %s://<LOCATION>", ErrSecretCoordHaveNoLocation, coord.Type)
 	}
 
 	// Aggregate and URL-unescape modifiers
@@ -82,22 +150,26 @@ func NewSecretCoord(secretCoordURI string) (*SecretCoord, error) {
 			splitPair := strings.SplitN(pair, "=", 2)
 			key, err = url.QueryUnescape(splitPair[0])
 			if err != nil {
-				return nil, fmt.Errorf("%w (pair: %q / key: %q): %w",
-					ErrSecretCoordFailedParsingModifiers,
-					pair, splitPair[0],
-					err,
-				)
+				return nil, fmt.Errorf("%w: invalid escaping in a name", ErrSecretCoordFailedParsingModifiers)
 			}
 
 			if len(splitPair) > 1 {
 				value, err = url.QueryUnescape(splitPair[1])
 				if err != nil {
-					return nil, fmt.Errorf("%w (pair: %q / val: %q): %w",
-						ErrSecretCoordFailedParsingModifiers,
-						pair, splitPair[1],
-						err,
-					)
+					return nil, fmt.Errorf("%w: invalid escaping in the value of %q", ErrSecretCoordFailedParsingModifiers, key)
+				}
+			}
+
+			// Parameters are not ordered: if repeated, the last one wins
+			if name, isParam := strings.CutPrefix(key, ParamPrefix); isParam {
+				if len(name) == 0 {
+					return nil, fmt.Errorf("%w: missing name", ErrSecretCoordFailedParsingParams)
+				}
+				if coord.Params == nil {
+					coord.Params = make(map[string]string)
 				}
+				coord.Params[name] = value
+				continue
 			}
 
 			coord.Modifiers = append(coord.Modifiers, [2]string{key, value})
@@ -117,3 +189,59 @@ func (sc *SecretCoord) UnmarshalText(text []byte) error {
 	*sc = *coord
 	return nil
 }
+
+// URI returns the coordinates as a URI string, escaping the location, the parameters and the modifiers as needed.
+// Parameters come first, sorted by name, followed by the modifiers, in order.
+//
+// For coordinates created via NewSecretCoord, NewSecretCoord(c.URI()) reproduces c.
+func (sc SecretCoord) URI() string {
+	res := strings.Builder{}
+	res.WriteString(sc.Type + "://")
+
+	// Split the location like url.Parse does: the authority (i.e. userinfo and host) ends at the first '/'.
+	// The userinfo is kept as is, as NewSecretCoord already stores it escaped.
+	authority, path, hasPath := strings.Cut(sc.Location, "/")
+	if idx := strings.LastIndex(authority, "@"); idx >= 0 {
+		res.WriteString(authority[:idx+1])
+		authority = authority[idx+1:]
+	}
+	res.WriteString(strings.ReplaceAll(authority, "%", "%25"))
+	if hasPath {
+		res.WriteString((&url.URL{Path: "/" + path}).EscapedPath())
+	}
+
+	sep := "?"
+	for _, name := range slices.Sorted(maps.Keys(sc.Params)) {
+		res.WriteString(sep + ParamPrefix + url.QueryEscape(name))
+		if len(sc.Params[name]) > 0 {
+			res.WriteString("=" + url.QueryEscape(sc.Params[name]))
+		}
+		sep = "&"
+	}
+	for _, mod := range sc.Modifiers {
+		res.WriteString(sep + url.QueryEscape(mod[0]))
+		if len(mod[0]) == 0 || len(mod[1]) > 0 {
+			res.WriteString("=" + url.QueryEscape(mod[1]))
+		}
+		sep = "&"
+	}
+	return res.String()
+}
+
+var (
+	_ encoding.TextMarshaler = SecretCoord{}
+	_ json.Marshaler         = SecretCoord{}
+)
+
+func (sc SecretCoord) MarshalText() ([]byte, error) {
+	return []byte(sc.URI()), nil
+}
+
+func (sc SecretCoord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sc.URI())
+}
+
+// MarshalYAML implements yaml.Marshaler, encoding the coordinates as their URI.
+func (sc SecretCoord) MarshalYAML() (any, error) {
+	return sc.URI(), nil
+}
@@ -1,10 +1,129 @@
 package types
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrCouldNotFetchSecret = fmt.Errorf("could not fetch secret")
 	ErrInvalidLocation     = fmt.Errorf("invalid secret location format")
 	ErrSecretKeyNotFound   = fmt.Errorf("secret key not found")
 	ErrSecretNotFound      = fmt.Errorf("secret not found")
+
+	// ErrTransient marks failures that might succeed if retried (e.g. timeouts, throttling, unavailability).
+	// Sources wrap it, alongside ErrCouldNotFetchSecret, when they can classify a failure as such.
+	ErrTransient = fmt.Errorf("transient failure")
+
+	// ErrPermissionDenied marks failures due to the caller not being allowed to access the secret.
+	// Sources wrap it, alongside ErrCouldNotFetchSecret, when they can classify a failure as such.
+	ErrPermissionDenied = fmt.Errorf("permission denied")
+
+	// ErrUnauthenticated marks failures due to missing, invalid or expired credentials.
+	// Sources wrap it, alongside ErrCouldNotFetchSecret, when they can classify a failure as such.
+	ErrUnauthenticated = fmt.Errorf("unauthenticated")
+
+	// ErrCouldNotWriteSecret is the failure of a SecretWriter to write or delete a secret.
+	ErrCouldNotWriteSecret = fmt.Errorf("could not write secret")
+
+	// ErrVersionConflict marks writes rejected because the secret changed since it was read,
+	// or isn't at the version expected by PutOptions.IfVersion.
+	ErrVersionConflict = fmt.Errorf("secret version conflict")
+
+	// ErrCompareAndSetNotSupported marks writes with PutOptions.IfVersion, to a backend without compare-and-set.
+	ErrCompareAndSetNotSupported = fmt.Errorf("compare-and-set not supported")
+
+	// ErrInvalidSecretValue marks writes of a value that the SecretWriter can't store at the location
+	// (e.g. a whole secret that isn't a JSON object).
+	ErrInvalidSecretValue = fmt.Errorf("invalid secret value")
+
+	// ErrUnsupportedParam marks coordinates with a parameter (see SecretCoord.Params) that the source doesn't accept.
+	ErrUnsupportedParam = fmt.Errorf("unsupported coordinates parameter")
+)
+
+// ErrorClass classifies a dig-up failure, for callers to branch on (see DigUpError).
+type ErrorClass string
+
+const (
+	ErrorClassNotFound         ErrorClass = "not_found"
+	ErrorClassPermissionDenied ErrorClass = "permission_denied"
+	ErrorClassUnauthenticated  ErrorClass = "unauthenticated"
+	ErrorClassInvalidLocation  ErrorClass = "invalid_location"
+	ErrorClassTransient        ErrorClass = "transient"
+	ErrorClassConflict         ErrorClass = "conflict"
+	ErrorClassUnknown          ErrorClass = "unknown"
+)
+
+// ClassifyError returns the ErrorClass of err, based on the sentinel errors it wraps
+// (e.g. ErrSecretNotFound, ErrTransient). It returns ErrorClassUnknown if none matches.
+//
+// It's the one classification of failures: the same class is reported by DigUpError,
+// audit events, telemetry and the exit codes of the `spelunk` CLI.
+func ClassifyError(err error) ErrorClass {
+	switch {
+	case errors.Is(err, ErrTransient), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTransient
+	case errors.Is(err, ErrUnauthenticated):
+		return ErrorClassUnauthenticated
+	case errors.Is(err, ErrPermissionDenied):
+		return ErrorClassPermissionDenied
+	case errors.Is(err, ErrVersionConflict):
+		return ErrorClassConflict
+	case errors.Is(err, ErrSecretNotFound), errors.Is(err, ErrSecretKeyNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, ErrInvalidLocation), errors.Is(err, ErrSecretCoordFailedParsing),
+		errors.Is(err, ErrSecretCoordHaveNoType), errors.Is(err, ErrSecretCoordHaveNoLocation),
+		errors.Is(err, ErrSecretCoordFailedParsingModifiers), errors.Is(err, ErrSecretCoordFailedParsingParams),
+		errors.Is(err, ErrUnsupportedParam):
+		return ErrorClassInvalidLocation
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// ErrorStage is the stage of a dig-up where a failure occurred (see DigUpError).
+type ErrorStage string
+
+const (
+	// ErrorStageParse is the parsing of coordinates (i.e. NewSecretCoord).
+	ErrorStageParse ErrorStage = "parse"
+	// ErrorStageSource is the dig-up from the SecretSource.
+	ErrorStageSource ErrorStage = "source"
+	// ErrorStageModifier is the application of a SecretModifier.
+	ErrorStageModifier ErrorStage = "modifier"
 )
+
+// DigUpError describes a dig-up failure: where it occurred, and its classification.
+// It wraps the underlying error, so errors.Is keeps matching the sentinel errors.
+type DigUpError struct {
+	// Type is the type of the coordinates (i.e. the source type), if known.
+	Type string
+	// Location is the location of the coordinates, redacted if it is the secret itself (see RedactLocation).
+	Location string
+	// Stage is the stage of the dig-up where the failure occurred.
+	Stage ErrorStage
+	// Class is the classification of the failure.
+	Class ErrorClass
+	// Err is the underlying error.
+	Err error
+}
+
+// NewDigUpError creates a *DigUpError wrapping err, classified via ClassifyError.
+func NewDigUpError(stage ErrorStage, sourceType, location string, err error) *DigUpError {
+	return &DigUpError{
+		Type:     sourceType,
+		Location: location,
+		Stage:    stage,
+		Class:    ClassifyError(err),
+		Err:      err,
+	}
+}
+
+func (e *DigUpError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DigUpError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,147 @@
+package types_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want types.ErrorClass
+	}{
+		{
+			name: "not found",
+			err:  fmt.Errorf("%w (%q)", types.ErrSecretNotFound, "loc"),
+			want: types.ErrorClassNotFound,
+		},
+		{
+			name: "key not found",
+			err:  fmt.Errorf("%w (%q)", types.ErrSecretKeyNotFound, "loc"),
+			want: types.ErrorClassNotFound,
+		},
+		{
+			name: "permission denied",
+			err:  fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrPermissionDenied),
+			want: types.ErrorClassPermissionDenied,
+		},
+		{
+			name: "unauthenticated",
+			err:  fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrUnauthenticated),
+			want: types.ErrorClassUnauthenticated,
+		},
+		{
+			name: "invalid location",
+			err:  fmt.Errorf("%w: expected <KEY>", types.ErrInvalidLocation),
+			want: types.ErrorClassInvalidLocation,
+		},
+		{
+			name: "unsupported param",
+			err:  fmt.Errorf("%w: %q", types.ErrUnsupportedParam, "version"),
+			want: types.ErrorClassInvalidLocation,
+		},
+		{
+			name: "transient",
+			err:  fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrTransient),
+			want: types.ErrorClassTransient,
+		},
+		{
+			name: "deadline exceeded",
+			err:  fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, context.DeadlineExceeded),
+			want: types.ErrorClassTransient,
+		},
+		{
+			name: "transient wins over permission denied",
+			err:  fmt.Errorf("%w: %w", types.ErrTransient, types.ErrPermissionDenied),
+			want: types.ErrorClassTransient,
+		},
+		{
+			name: "version conflict",
+			err:  fmt.Errorf("%w: %w", types.ErrCouldNotWriteSecret, types.ErrVersionConflict),
+			want: types.ErrorClassConflict,
+		},
+		{
+			name: "could not fetch",
+			err:  fmt.Errorf("%w: boom", types.ErrCouldNotFetchSecret),
+			want: types.ErrorClassUnknown,
+		},
+		{
+			name: "unrelated",
+			err:  errors.New("boom"),
+			want: types.ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, types.ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestDigUpError(t *testing.T) {
+	err := types.NewDigUpError(
+		types.ErrorStageSource,
+		"vault",
+		"mount/path/key",
+		fmt.Errorf("%w (%q)", types.ErrSecretNotFound, "mount/path/key"),
+	)
+
+	require.ErrorIs(t, err, types.ErrSecretNotFound)
+	require.Equal(t, `secret not found ("mount/path/key")`, err.Error())
+	require.Equal(t, types.ErrorClassNotFound, err.Class)
+
+	digUpErr, errMatched := errors.AsType[*types.DigUpError](fmt.Errorf("wrapped: %w", err))
+	require.True(t, errMatched)
+	require.Equal(t, "vault", digUpErr.Type)
+	require.Equal(t, "mount/path/key", digUpErr.Location)
+	require.Equal(t, types.ErrorStageSource, digUpErr.Stage)
+}
+
+func TestNewSecretCoord_DigUpError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		errMatch error
+	}{
+		{
+			name:     "no type",
+			input:    "://location",
+			errMatch: types.ErrSecretCoordFailedParsing,
+		},
+		{
+			name:     "no location",
+			input:    "vault://",
+			errMatch: types.ErrSecretCoordHaveNoLocation,
+		},
+		{
+			name:     "invalid modifiers",
+			input:    "vault://location?%zz",
+			errMatch: types.ErrSecretCoordFailedParsingModifiers,
+		},
+		{
+			name:     "invalid params",
+			input:    "vault://location?@",
+			errMatch: types.ErrSecretCoordFailedParsingParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := types.NewSecretCoord(tt.input)
+			require.ErrorIs(t, err, tt.errMatch)
+
+			digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+			require.True(t, errMatched)
+			require.Equal(t, types.ErrorStageParse, digUpErr.Stage)
+			require.Equal(t, types.ErrorClassInvalidLocation, digUpErr.Class)
+			require.Equal(t, types.RedactedLocation, digUpErr.Location)
+		})
+	}
+}
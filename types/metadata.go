@@ -0,0 +1,38 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+// SecretMetadata describes a secret, as returned by a MetadataSource alongside its value.
+// All fields are optional: sources set the ones their backend provides.
+type SecretMetadata struct {
+	// Version identifies the version of the secret returned (e.g. Vault KV v2 version, AWS VersionId).
+	Version string `json:"version,omitempty"`
+	// CreatedAt is when the version of the secret was created.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	// ExpiresAt is when the secret expires (e.g. lease expiry, deletion time).
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	// ContentType is the content type of the secret, if known.
+	ContentType string `json:"content_type,omitempty"`
+	// Extras are further backend-specific metadata (e.g. AWS VersionStages, Kubernetes resourceVersion).
+	Extras map[string]string `json:"extras,omitempty"`
+}
+
+// SetExtra sets the backend-specific metadata key to value.
+func (m *SecretMetadata) SetExtra(key, value string) {
+	if m.Extras == nil {
+		m.Extras = make(map[string]string)
+	}
+	m.Extras[key] = value
+}
+
+// MetadataSource is a SecretSource that can dig up secrets alongside their SecretMetadata.
+// It's used by spelunk.Spelunker.DigUpWithMetadata, when available.
+type MetadataSource interface {
+	SecretSource
+
+	// DigUpWithMetadata returns the secret pointed at by the given SecretCoord, and its SecretMetadata.
+	DigUpWithMetadata(context.Context, SecretCoord) (string, *SecretMetadata, error)
+}
@@ -10,3 +10,22 @@ type SecretModifier interface {
 	// Modify applies a modification to the given secret value.
 	Modify(ctx context.Context, secretValue string, mod string) (string, error)
 }
+
+// BytesModifier is a SecretModifier that can modify secrets as raw bytes.
+// It's used by spelunk.Spelunker.DigUpBytes, when available.
+type BytesModifier interface {
+	SecretModifier
+
+	// ModifyBytes applies a modification to the given raw secret value.
+	ModifyBytes(ctx context.Context, secretValue []byte, mod string) ([]byte, error)
+}
+
+// ModifierValidator is a SecretModifier that can validate its argument offline,
+// without any secret to modify (e.g. compiling a JSONPath expression).
+// It's used by spelunk.Spelunker.Validate, when available.
+type ModifierValidator interface {
+	SecretModifier
+
+	// ValidateModifier returns an error if the given modification is not valid for the SecretModifier.
+	ValidateModifier(mod string) error
+}
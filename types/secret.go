@@ -0,0 +1,95 @@
+package types
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Redacted replaces secret values (and locations that are secrets themselves) wherever they would be exposed.
+const Redacted = "[REDACTED]"
+
+// Secret holds the value of a secret, and keeps it from leaking by accident:
+// it always prints, logs and marshals as Redacted, be it via fmt (any verb),
+// log/slog, encoding/json, or any encoding.TextMarshaler aware encoder.
+//
+// The value is accessible only explicitly, via Reveal or Bytes.
+// Once no longer needed, Destroy zeroes it.
+type Secret struct {
+	value []byte
+}
+
+var (
+	_ fmt.Formatter          = Secret{}
+	_ fmt.Stringer           = Secret{}
+	_ fmt.GoStringer         = Secret{}
+	_ slog.LogValuer         = Secret{}
+	_ json.Marshaler         = Secret{}
+	_ encoding.TextMarshaler = Secret{}
+)
+
+// NewSecret creates a Secret holding the given value.
+func NewSecret(value string) *Secret {
+	return &Secret{value: []byte(value)}
+}
+
+// NewSecretFromBytes creates a Secret holding a copy of the given value.
+func NewSecretFromBytes(value []byte) *Secret {
+	return &Secret{value: append([]byte(nil), value...)}
+}
+
+// Reveal returns the value of the secret.
+func (s Secret) Reveal() string {
+	return string(s.value)
+}
+
+// Bytes returns a copy of the value of the secret.
+func (s Secret) Bytes() []byte {
+	return append([]byte(nil), s.value...)
+}
+
+// Len returns the length, in bytes, of the value of the secret.
+func (s Secret) Len() int {
+	return len(s.value)
+}
+
+// Destroy zeroes the memory holding the value of the secret, and empties it.
+//
+// This is best-effort: copies made by Reveal and Bytes, or by the Go runtime
+// (e.g. the garbage collector moving memory around) are out of its reach.
+func (s *Secret) Destroy() {
+	clear(s.value)
+	s.value = nil
+}
+
+// Format implements fmt.Formatter: it writes Redacted, whatever the verb.
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, Redacted)
+}
+
+// String implements fmt.Stringer: it returns Redacted.
+func (s Secret) String() string {
+	return Redacted
+}
+
+// GoString implements fmt.GoStringer: it returns Redacted.
+func (s Secret) GoString() string {
+	return Redacted
+}
+
+// LogValue implements slog.LogValuer: it logs Redacted.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// MarshalJSON implements json.Marshaler: it marshals to Redacted.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// MarshalText implements encoding.TextMarshaler: it marshals to Redacted.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
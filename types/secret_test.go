@@ -0,0 +1,92 @@
+package types_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+const secretValue = "hunter2"
+
+func TestSecret_Redacted(t *testing.T) {
+	secret := types.NewSecret(secretValue)
+
+	t.Run("fmt", func(t *testing.T) {
+		for _, format := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x", "%d", "%10s"} {
+			require.Equal(t, types.Redacted, fmt.Sprintf(format, secret), format)
+			require.Equal(t, types.Redacted, fmt.Sprintf(format, *secret), format)
+		}
+		require.Equal(t, types.Redacted, secret.String())
+
+		wrapper := struct{ Password types.Secret }{Password: *secret}
+		require.NotContains(t, fmt.Sprintf("%+v", wrapper), secretValue)
+		require.NotContains(t, fmt.Errorf("failed with %v", secret).Error(), secretValue)
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		logger.Info("dug-up", "secret", secret, "value", *secret)
+		require.NotContains(t, buf.String(), secretValue)
+		require.Contains(t, buf.String(), types.Redacted)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		res, err := json.Marshal(map[string]any{"ptr": secret, "val": *secret})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"ptr":"[REDACTED]","val":"[REDACTED]"}`, string(res))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		res, err := secret.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, types.Redacted, string(res))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		defer func() {
+			recovered := recover()
+			require.NotContains(t, fmt.Sprint(recovered), secretValue)
+		}()
+		panic(secret)
+	})
+}
+
+func TestSecret_Reveal(t *testing.T) {
+	secret := types.NewSecret(secretValue)
+	require.Equal(t, secretValue, secret.Reveal())
+	require.Equal(t, []byte(secretValue), secret.Bytes())
+	require.Equal(t, len(secretValue), secret.Len())
+
+	// Bytes returns a copy
+	b := secret.Bytes()
+	b[0] = 'X'
+	require.Equal(t, secretValue, secret.Reveal())
+
+	// NewSecretFromBytes holds a copy
+	src := []byte(secretValue)
+	fromBytes := types.NewSecretFromBytes(src)
+	src[0] = 'X'
+	require.Equal(t, secretValue, fromBytes.Reveal())
+}
+
+func TestSecret_Destroy(t *testing.T) {
+	src := []byte(secretValue)
+	secret := types.NewSecretFromBytes(src)
+	shared := *secret
+
+	secret.Destroy()
+	require.Empty(t, secret.Reveal())
+	require.Zero(t, secret.Len())
+
+	// Copies of the Secret struct share the (now zeroed) memory
+	require.Equal(t, bytes.Repeat([]byte{0}, len(secretValue)), shared.Bytes())
+
+	// Destroying twice is harmless
+	require.NotPanics(t, secret.Destroy)
+}
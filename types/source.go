@@ -4,6 +4,9 @@ import (
 	"context"
 )
 
+// RedactedLocation replaces the location of coordinates pointing at an InlineSecretSource.
+const RedactedLocation = Redacted
+
 // SecretSource is a source of secrets.
 type SecretSource interface {
 	// Type returns the unique identifier for the type of SecretSource.
@@ -12,3 +15,114 @@ type SecretSource interface {
 	// DigUp returns the secret pointed at by the given SecretCoord.
 	DigUp(context.Context, SecretCoord) (string, error)
 }
+
+// BytesSource is a SecretSource that can dig up secrets as raw bytes, without
+// encoding them to fit a string (e.g. binary secrets base64-encoded by DigUp).
+// It's used by spelunk.Spelunker.DigUpBytes, when available.
+type BytesSource interface {
+	SecretSource
+
+	// DigUpBytes returns the raw bytes of the secret pointed at by the given SecretCoord.
+	DigUpBytes(context.Context, SecretCoord) ([]byte, error)
+}
+
+// InlineSecretSource is a SecretSource whose SecretCoord.Location is the secret itself
+// (e.g. `plain://`), rather than a reference to where the secret is stored.
+// Such locations must never be logged, traced or otherwise exposed: see RedactLocation.
+type InlineSecretSource interface {
+	SecretSource
+
+	// Inline marks the SecretSource as inline. It does nothing.
+	Inline()
+}
+
+// RedactLocation returns the location of coord, safe to be logged or traced:
+// if source is an InlineSecretSource, it returns RedactedLocation.
+func RedactLocation(source SecretSource, coord SecretCoord) string {
+	if _, inline := source.(InlineSecretSource); inline {
+		return RedactedLocation
+	}
+	return coord.Location
+}
+
+// WatchableSource is a SecretSource that can notify when secrets change, natively
+// (e.g. the Kubernetes watch API), rather than being polled.
+// It's used by spelunk.Spelunker.Watch, when available.
+type WatchableSource interface {
+	SecretSource
+
+	// Watch notifies, via the returned channel, every time the secret pointed at by
+	// the given SecretCoord might have changed: the secret is then dug-up again.
+	// The channel must be closed once the context is done, or when watching stops for any other reason.
+	Watch(context.Context, SecretCoord) (<-chan struct{}, error)
+}
+
+// LocationValidator is a SecretSource that can validate the syntax of SecretCoord.Location
+// offline, without digging up the secret (i.e. no network call, no credentials needed).
+// It's used by spelunk.Spelunker.Validate, when available.
+type LocationValidator interface {
+	SecretSource
+
+	// ValidateLocation returns an error wrapping ErrInvalidLocation, if the location of
+	// the given SecretCoord is not valid for the SecretSource.
+	ValidateLocation(SecretCoord) error
+}
+
+// ParamSource is a SecretSource accepting parameters, via SecretCoord.Params (e.g. "?@version=3").
+// Coordinates with parameters are dug-up only from a ParamSource supporting all of them:
+// spelunk.Spelunker fails with ErrUnsupportedParam otherwise.
+type ParamSource interface {
+	SecretSource
+
+	// Params returns the names of the parameters the SecretSource supports (e.g. ParamVersion).
+	Params() []string
+}
+
+// ExistenceChecker is a SecretSource that can check if a secret exists via metadata-only calls
+// (e.g. AWS DescribeSecret), without digging up its value.
+// It's used by spelunk.Spelunker.Exists, when available.
+type ExistenceChecker interface {
+	SecretSource
+
+	// Exists returns true if the secret pointed at by the given SecretCoord exists,
+	// and false (with no error) if it's not found.
+	Exists(context.Context, SecretCoord) (bool, error)
+}
+
+// SecretLister is a SecretSource that can list what is available under a location prefix
+// (e.g. the secrets in a Kubernetes namespace, or in a Vault folder), without digging up any secret.
+// It's used by spelunk.Spelunker.List, when available.
+type SecretLister interface {
+	SecretSource
+
+	// List returns the children of the given SecretCoord, used as a prefix: coordinates of secrets
+	// that can be dug-up, or of further prefixes that can be listed in turn.
+	// The returned SecretCoord have the same Type as the prefix, and no modifiers.
+	List(ctx context.Context, prefix SecretCoord) ([]SecretCoord, error)
+}
+
+// PutOptions configures how SecretWriter.Put writes a secret.
+type PutOptions struct {
+	// IfVersion, if set, makes the write a compare-and-set: the secret is written only if its current
+	// version matches (e.g. Vault KV v2 version, Kubernetes resourceVersion; see SecretMetadata.Version),
+	// failing with ErrVersionConflict otherwise. Sources whose backend doesn't offer compare-and-set
+	// fail with ErrCompareAndSetNotSupported.
+	IfVersion string
+}
+
+// VersionNone, as PutOptions.IfVersion, makes the write create-only: it fails with ErrVersionConflict
+// if the secret (or the key inside it) already exists. It's the version of a missing Vault KV v2 secret.
+const VersionNone = "0"
+
+// SecretWriter is a SecretSource that can also write and delete secrets (e.g. to seed them while bootstrapping).
+// It's used by spelunk.Spelunker.Put and spelunk.Spelunker.Delete, when available.
+type SecretWriter interface {
+	SecretSource
+
+	// Put writes value as the secret pointed at by the given SecretCoord, creating it if it doesn't exist.
+	Put(ctx context.Context, coord SecretCoord, value string, opts PutOptions) error
+
+	// Delete deletes the secret pointed at by the given SecretCoord.
+	// Deleting a secret that doesn't exist is not an error.
+	Delete(ctx context.Context, coord SecretCoord) error
+}
@@ -0,0 +1,42 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/detro/spelunk/v2/builtin/source/base64"
+	"github.com/detro/spelunk/v2/builtin/source/plain"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		source types.SecretSource
+		want   string
+	}{
+		{
+			name:   "reference source",
+			source: util.NewMockSource("test"),
+			want:   "path/to/secret",
+		},
+		{
+			name:   "plain source",
+			source: &plain.SecretSourcePlain{},
+			want:   types.RedactedLocation,
+		},
+		{
+			name:   "base64 source",
+			source: &base64.SecretSourceBase64{},
+			want:   types.RedactedLocation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord := types.SecretCoord{Type: tt.source.Type(), Location: "path/to/secret"}
+			require.Equal(t, tt.want, types.RedactLocation(tt.source, coord))
+		})
+	}
+}
@@ -2,15 +2,17 @@ package util
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/detro/spelunk/v2/types"
 )
 
 // MockSource implements spelunk.SecretSource for testing
 type MockSource struct {
-	typ string
-	Val string
-	Err error
+	typ   string
+	Val   string
+	Err   error
+	calls atomic.Int64
 }
 
 // NewMockSource creates a new MockSource with the required typ field
@@ -25,7 +27,13 @@ func (m *MockSource) Type() string {
 }
 
 func (m *MockSource) DigUp(_ context.Context, _ types.SecretCoord) (string, error) {
+	m.calls.Add(1)
 	return m.Val, m.Err
 }
 
+// Calls returns how many times DigUp was called
+func (m *MockSource) Calls() int {
+	return int(m.calls.Load())
+}
+
 var _ types.SecretSource = (*MockSource)(nil)
@@ -0,0 +1,75 @@
+package spelunk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+var ErrInvalidModifier = fmt.Errorf("invalid modifier")
+
+// Validate checks the given *SecretCoord offline, without digging up the secret:
+// no network call is made, and no credentials are needed (e.g. to lint configuration in CI).
+//
+// It checks that the source type is supported and allowed by the policy (see WithPolicy),
+// that the location is valid (if the source is a types.LocationValidator),
+// and that every modifier is supported and has a valid argument (if the modifier is a types.ModifierValidator).
+// All failures are reported together, each as a *types.DigUpError.
+func (s *Spelunker) Validate(coord *types.SecretCoord) error {
+	if coord == nil {
+		return ErrNilSecretCoord
+	}
+
+	// Identify the source of the secret
+	source, found := s.opts.sources[coord.Type]
+	if !found {
+		return types.NewDigUpError(
+			types.ErrorStageSource,
+			coord.Type,
+			types.RedactedLocation,
+			fmt.Errorf("%w: %q", ErrUnsupportedSecretSourceType, coord.Type),
+		)
+	}
+	newDigUpError := func(stage types.ErrorStage, err error) error {
+		return types.NewDigUpError(stage, coord.Type, types.RedactLocation(source, *coord), err)
+	}
+
+	var errs []error
+	if err := s.opts.policy.check(source, coord); err != nil {
+		errs = append(errs, newDigUpError(types.ErrorStageSource, err))
+	}
+	if err := checkParams(source, coord); err != nil {
+		errs = append(errs, newDigUpError(types.ErrorStageSource, err))
+	}
+	if validator, ok := source.(types.LocationValidator); ok {
+		if err := validator.ValidateLocation(*coord); err != nil {
+			errs = append(errs, newDigUpError(types.ErrorStageSource, err))
+		}
+	}
+
+	for _, mod := range coord.Modifiers {
+		if types.IsReservedModifier(mod[0]) {
+			continue
+		}
+		modifier, found := s.opts.modifiers[mod[0]]
+		if !found {
+			errs = append(errs, newDigUpError(
+				types.ErrorStageModifier,
+				fmt.Errorf("%w: %q", ErrUnsupportedSecretModifierType, mod[0]),
+			))
+			continue
+		}
+
+		if validator, ok := modifier.(types.ModifierValidator); ok {
+			if err := validator.ValidateModifier(mod[1]); err != nil {
+				errs = append(errs, newDigUpError(
+					types.ErrorStageModifier,
+					fmt.Errorf("%w %q: %w", ErrInvalidModifier, mod[0], err),
+				))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package spelunk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/stretchr/testify/require"
+)
+
+var errEmptyArgument = errors.New("empty argument")
+
+// validatingModifier implements types.ModifierValidator for testing,
+// rejecting empty arguments.
+type validatingModifier struct {
+	mockModifier
+}
+
+func (m *validatingModifier) ValidateModifier(mod string) error {
+	if mod == "" {
+		return errEmptyArgument
+	}
+	return nil
+}
+
+func TestSpelunker_Validate(t *testing.T) {
+	src := newValueSource("mock", "val")
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithModifier(&validatingModifier{mockModifier{typ: "vmod"}}),
+		spelunk.WithPolicy(spelunk.WithPolicyDenyLocations("mock://denied/**")),
+	)
+
+	tests := []struct {
+		name      string
+		coordStr  string
+		errMatch  []error
+		wantStage types.ErrorStage
+	}{
+		{
+			name:     "valid",
+			coordStr: "mock://loc?vmod=arg&b64",
+		},
+		{
+			name:     "valid with fallback",
+			coordStr: "mock://loc?default=val&optional",
+		},
+		{
+			name:      "unsupported source type",
+			coordStr:  "unknown://loc",
+			errMatch:  []error{spelunk.ErrUnsupportedSecretSourceType},
+			wantStage: types.ErrorStageSource,
+		},
+		{
+			name:      "invalid location",
+			coordStr:  "base64://not-base64!",
+			errMatch:  []error{types.ErrInvalidLocation},
+			wantStage: types.ErrorStageSource,
+		},
+		{
+			name:      "policy violation",
+			coordStr:  "mock://denied/secret",
+			errMatch:  []error{spelunk.ErrPolicyViolation},
+			wantStage: types.ErrorStageSource,
+		},
+		{
+			name:      "unsupported modifier type",
+			coordStr:  "mock://loc?unknown",
+			errMatch:  []error{spelunk.ErrUnsupportedSecretModifierType},
+			wantStage: types.ErrorStageModifier,
+		},
+		{
+			name:      "invalid modifier argument",
+			coordStr:  "mock://loc?vmod",
+			errMatch:  []error{spelunk.ErrInvalidModifier, errEmptyArgument},
+			wantStage: types.ErrorStageModifier,
+		},
+		{
+			name:      "all failures reported together",
+			coordStr:  "mock://denied/secret?vmod&unknown",
+			errMatch:  []error{spelunk.ErrPolicyViolation, errEmptyArgument, spelunk.ErrUnsupportedSecretModifierType},
+			wantStage: types.ErrorStageSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			err = spelunker.Validate(coord)
+			if len(tt.errMatch) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, errMatch := range tt.errMatch {
+				require.ErrorIs(t, err, errMatch)
+			}
+
+			digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+			require.True(t, errMatched)
+			require.Equal(t, tt.wantStage, digUpErr.Stage)
+		})
+	}
+
+	// Validation never digs up
+	require.Zero(t, src.Calls())
+	require.ErrorIs(t, spelunker.Validate(nil), spelunk.ErrNilSecretCoord)
+}
@@ -0,0 +1,174 @@
+package spelunk
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+// defaultWatchInterval is how often secrets are polled for changes, unless configured otherwise.
+const defaultWatchInterval = time.Minute
+
+var ErrFailedToWatchSecret = fmt.Errorf("failed to watch secret")
+
+// Update is a change of a watched secret, delivered by Spelunker.Watch:
+// either its new value, or the error that occurred digging it up.
+type Update struct {
+	Value string
+	Err   error
+}
+
+// WatchOption options that can be provided to Spelunker.Watch.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	interval time.Duration
+}
+
+// WithWatchInterval sets how often the secret is polled for changes.
+// For sources implementing types.WatchableSource, it's how long to wait before watching again,
+// when the source stops watching.
+//
+// A value <= 0 is treated as the default, not to poll the source in a busy loop.
+// Default is 1 minute.
+func WithWatchInterval(interval time.Duration) WatchOption {
+	return func(o *watchOptions) {
+		if interval <= 0 {
+			interval = defaultWatchInterval
+		}
+		o.interval = interval
+	}
+}
+
+// Watch watches the secret at the given *SecretCoord, delivering an Update on the returned channel
+// with its current value, and then every time it changes (or fails to be dug-up).
+// The channel is closed once ctx is done.
+//
+// Sources implementing types.WatchableSource notify changes natively (e.g. Kubernetes);
+// all others are polled (see WithWatchInterval). Either way, the secret is dug-up again via DigUp,
+// reading around the cache (without evicting what other callers have cached), and an Update
+// is delivered only if the hash of the value has changed.
+func (s *Spelunker) Watch(
+	ctx context.Context,
+	coord *types.SecretCoord,
+	opts ...WatchOption,
+) (<-chan Update, error) {
+	if coord == nil {
+		return nil, ErrNilSecretCoord
+	}
+	source, found := s.opts.sources[coord.Type]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedSecretSourceType, coord.Type)
+	}
+	if err := s.opts.policy.check(source, coord); err != nil {
+		return nil, err
+	}
+
+	w := &watcher{
+		spelunker: s,
+		source:    source,
+		coord:     coord,
+		opts: watchOptions{
+			interval: defaultWatchInterval,
+		},
+		updates: make(chan Update),
+	}
+	for _, opt := range opts {
+		opt(&w.opts)
+	}
+
+	go w.run(ctx)
+	return w.updates, nil
+}
+
+// watcher delivers the updates of a secret watched via Spelunker.Watch.
+type watcher struct {
+	spelunker *Spelunker
+	source    types.SecretSource
+	coord     *types.SecretCoord
+	opts      watchOptions
+	updates   chan Update
+
+	// lastHash is the hash of the last value delivered, if any
+	lastHash  [sha256.Size]byte
+	delivered bool
+}
+
+func (w *watcher) run(ctx context.Context) {
+	defer close(w.updates)
+
+	for {
+		// Start watching before checking, not to miss changes happening in between
+		changes := w.watchSource(ctx)
+		if !w.check(ctx) {
+			return
+		}
+		if changes != nil {
+			for range changes {
+				if !w.check(ctx) {
+					return
+				}
+			}
+		}
+
+		// Polling, or the source stopped watching: wait before trying again
+		timer := time.NewTimer(w.opts.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// watchSource returns the notifications of changes of the source, if it's a types.WatchableSource,
+// or nil if it must be polled. Failures to watch are delivered as Update, and the source is polled.
+func (w *watcher) watchSource(ctx context.Context) <-chan struct{} {
+	watchable, ok := w.source.(types.WatchableSource)
+	if !ok {
+		return nil
+	}
+	changes, err := watchable.Watch(ctx, *w.coord)
+	if err != nil {
+		w.send(ctx, Update{Err: fmt.Errorf("%w: %w", ErrFailedToWatchSecret, err)})
+		return nil
+	}
+	return changes
+}
+
+// check digs up the secret again, delivering an Update if it has changed or failed.
+// It returns false if ctx is done, and watching must stop.
+func (w *watcher) check(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	val, err := w.spelunker.DigUp(withCacheBypass(ctx), w.coord)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		return w.send(ctx, Update{Err: err})
+	}
+
+	hash := sha256.Sum256([]byte(val))
+	if w.delivered && hash == w.lastHash {
+		return true
+	}
+	w.lastHash, w.delivered = hash, true
+	return w.send(ctx, Update{Value: val})
+}
+
+// send delivers u, returning false if ctx is done before it's received.
+func (w *watcher) send(ctx context.Context, u Update) bool {
+	select {
+	case w.updates <- u:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,191 @@
+package spelunk_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// rotatingSource implements types.SecretSource for testing: its value can be rotated concurrently.
+type rotatingSource struct {
+	typ string
+
+	mu  sync.Mutex
+	val string
+	err error
+}
+
+func (r *rotatingSource) Type() string {
+	return r.typ
+}
+
+func (r *rotatingSource) DigUp(_ context.Context, _ types.SecretCoord) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.val, r.err
+}
+
+func (r *rotatingSource) rotate(val string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.val, r.err = val, err
+}
+
+// watchableSource implements types.WatchableSource for testing: changes are notified explicitly.
+type watchableSource struct {
+	rotatingSource
+	changes chan struct{}
+}
+
+func (w *watchableSource) Watch(ctx context.Context, _ types.SecretCoord) (<-chan struct{}, error) {
+	notify := make(chan struct{})
+	go func() {
+		defer close(notify)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.changes:
+				notify <- struct{}{}
+			}
+		}
+	}()
+	return notify, nil
+}
+
+func (w *watchableSource) rotate(val string) {
+	w.rotatingSource.rotate(val, nil)
+	w.changes <- struct{}{}
+}
+
+func nextUpdate(t *testing.T, updates <-chan spelunk.Update) spelunk.Update {
+	t.Helper()
+	select {
+	case u, ok := <-updates:
+		require.True(t, ok, "updates channel closed")
+		return u
+	case <-time.After(time.Second):
+		require.FailNow(t, "no update received")
+		return spelunk.Update{}
+	}
+}
+
+func TestSpelunker_Watch_Polling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := &rotatingSource{typ: "rot", val: "v1"}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(src),
+		spelunk.WithCache(),
+	)
+
+	coord, err := types.NewSecretCoord("rot://loc?b64e")
+	require.NoError(t, err)
+	cached, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	updates, err := spelunker.Watch(ctx, coord, spelunk.WithWatchInterval(5*time.Millisecond))
+	require.NoError(t, err)
+
+	// Current value first, with modifiers applied
+	require.Equal(t, spelunk.Update{Value: "djE="}, nextUpdate(t, updates))
+
+	// Unchanged values are not delivered; the cache is bypassed
+	src.rotate("v2", nil)
+	require.Equal(t, spelunk.Update{Value: "djI="}, nextUpdate(t, updates))
+
+	// ... without evicting the value cached for other callers
+	got, err := spelunker.DigUp(ctx, coord)
+	require.NoError(t, err)
+	require.Equal(t, cached, got)
+
+	// Failures are delivered too
+	srcErr := fmt.Errorf("%w: %w", types.ErrCouldNotFetchSecret, types.ErrTransient)
+	src.rotate("", srcErr)
+	u := nextUpdate(t, updates)
+	require.ErrorIs(t, u.Err, spelunk.ErrFailedToDigUpSecret)
+	require.ErrorIs(t, u.Err, types.ErrTransient)
+
+	src.rotate("v3", nil)
+	// Skip failures delivered before the rotation was noticed
+	u = nextUpdate(t, updates)
+	for u.Err != nil {
+		u = nextUpdate(t, updates)
+	}
+	require.Equal(t, spelunk.Update{Value: "djM="}, u)
+
+	// Cancelling ctx closes the channel
+	cancel()
+	for range updates {
+	}
+}
+
+func TestSpelunker_Watch_NonPositiveInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := util.NewMockSource("test")
+	src.Val = "v1"
+	spelunker := spelunk.NewSpelunker(spelunk.WithSource(src))
+
+	coord, err := types.NewSecretCoord("test://loc")
+	require.NoError(t, err)
+	updates, err := spelunker.Watch(ctx, coord, spelunk.WithWatchInterval(0))
+	require.NoError(t, err)
+	require.Equal(t, spelunk.Update{Value: "v1"}, nextUpdate(t, updates))
+
+	// The default interval is used instead: the source is not polled in a busy loop
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, 1, src.Calls())
+}
+
+func TestSpelunker_Watch_WatchableSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := &watchableSource{
+		rotatingSource: rotatingSource{typ: "watch", val: "v1"},
+		changes:        make(chan struct{}),
+	}
+	spelunker := spelunk.NewSpelunker(spelunk.WithSource(src))
+
+	coord, err := types.NewSecretCoord("watch://loc")
+	require.NoError(t, err)
+	// Long interval: changes are only noticed via notifications
+	updates, err := spelunker.Watch(ctx, coord, spelunk.WithWatchInterval(time.Hour))
+	require.NoError(t, err)
+
+	require.Equal(t, spelunk.Update{Value: "v1"}, nextUpdate(t, updates))
+
+	src.rotate("v2")
+	require.Equal(t, spelunk.Update{Value: "v2"}, nextUpdate(t, updates))
+
+	// Notifications without changes are not delivered
+	src.rotate("v2")
+	src.rotate("v3")
+	require.Equal(t, spelunk.Update{Value: "v3"}, nextUpdate(t, updates))
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestSpelunker_Watch_Errors(t *testing.T) {
+	ctx := context.Background()
+	spelunker := spelunk.NewSpelunker()
+
+	_, err := spelunker.Watch(ctx, nil)
+	require.ErrorIs(t, err, spelunk.ErrNilSecretCoord)
+
+	coord, err := types.NewSecretCoord("unknown://loc")
+	require.NoError(t, err)
+	_, err = spelunker.Watch(ctx, coord)
+	require.ErrorIs(t, err, spelunk.ErrUnsupportedSecretSourceType)
+}
@@ -0,0 +1,105 @@
+package spelunk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/detro/spelunk/v2/types"
+)
+
+var (
+	ErrWritingNotSupported  = fmt.Errorf("secret source does not support writing")
+	ErrWritingWithModifiers = fmt.Errorf("secrets can't be written through modifiers")
+	ErrFailedToPutSecret    = fmt.Errorf("failed to put secret")
+	ErrFailedToDeleteSecret = fmt.Errorf("failed to delete secret")
+)
+
+// Put writes value as the secret pointed at by the given *SecretCoord, creating it if it doesn't exist
+// (e.g. to seed secrets while bootstrapping). The source must implement types.SecretWriter.
+//
+// With types.PutOptions.IfVersion set, the write is a compare-and-set: it fails with types.ErrVersionConflict
+// if the secret is not at that version (or, with types.VersionNone, if it exists). The coordinates must have no modifiers, and are subject to the policy
+// (see WithPolicy). The cached value for the coordinates, if any, is evicted (see WithCache).
+// Failures are returned as a *types.DigUpError.
+func (s *Spelunker) Put(ctx context.Context, coord *types.SecretCoord, value string, opts types.PutOptions) error {
+	start := time.Now()
+	return s.audited(ctx, AuditOperationPut, coord, start, s.put(ctx, coord, value, opts))
+}
+
+func (s *Spelunker) put(ctx context.Context, coord *types.SecretCoord, value string, opts types.PutOptions) error {
+	writer, newDigUpError, err := s.writerFor(coord)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Put(ctx, *coord, value, opts); err != nil {
+		return newDigUpError(fmt.Errorf("%w: %w", ErrFailedToPutSecret, err))
+	}
+	s.Invalidate(coord)
+	return nil
+}
+
+// Delete deletes the secret pointed at by the given *SecretCoord: deleting a secret that doesn't exist
+// is not an error. The source must implement types.SecretWriter.
+//
+// Like Put, the coordinates must have no modifiers, are subject to the policy, and their cached value is evicted.
+// Failures are returned as a *types.DigUpError.
+func (s *Spelunker) Delete(ctx context.Context, coord *types.SecretCoord) error {
+	start := time.Now()
+	return s.audited(ctx, AuditOperationDelete, coord, start, s.delete(ctx, coord))
+}
+
+func (s *Spelunker) delete(ctx context.Context, coord *types.SecretCoord) error {
+	writer, newDigUpError, err := s.writerFor(coord)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Delete(ctx, *coord); err != nil {
+		return newDigUpError(fmt.Errorf("%w: %w", ErrFailedToDeleteSecret, err))
+	}
+	s.Invalidate(coord)
+	return nil
+}
+
+// writerFor returns the types.SecretWriter for coord, once checked that it can be written,
+// and the function creating a *types.DigUpError for its failures.
+func (s *Spelunker) writerFor(
+	coord *types.SecretCoord,
+) (types.SecretWriter, func(error) error, error) {
+	if coord == nil {
+		return nil, nil, ErrNilSecretCoord
+	}
+
+	// Identify the source of the secret
+	source, found := s.opts.sources[coord.Type]
+	if !found {
+		return nil, nil, types.NewDigUpError(
+			types.ErrorStageSource,
+			coord.Type,
+			types.RedactedLocation,
+			fmt.Errorf("%w: %q", ErrUnsupportedSecretSourceType, coord.Type),
+		)
+	}
+	newDigUpError := func(err error) error {
+		return types.NewDigUpError(types.ErrorStageSource, coord.Type, types.RedactLocation(source, *coord), err)
+	}
+
+	writer, ok := source.(types.SecretWriter)
+	if !ok {
+		return nil, nil, newDigUpError(fmt.Errorf("%w: %q", ErrWritingNotSupported, coord.Type))
+	}
+	if len(coord.Modifiers) > 0 {
+		return nil, nil, newDigUpError(ErrWritingWithModifiers)
+	}
+
+	// Enforce the policy, if any
+	if err := s.opts.policy.check(source, coord); err != nil {
+		return nil, nil, newDigUpError(err)
+	}
+	if err := checkParams(source, coord); err != nil {
+		return nil, nil, newDigUpError(err)
+	}
+	return writer, newDigUpError, nil
+}
@@ -0,0 +1,120 @@
+package spelunk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/detro/spelunk/v2"
+	"github.com/detro/spelunk/v2/types"
+	"github.com/detro/spelunk/v2/util"
+	"github.com/stretchr/testify/require"
+)
+
+// secretWriter implements types.SecretWriter for testing, storing a single secret
+// (dug-up via the embedded util.MockSource), and its version.
+type secretWriter struct {
+	*util.MockSource
+	version int
+}
+
+func (w *secretWriter) Put(_ context.Context, _ types.SecretCoord, value string, opts types.PutOptions) error {
+	if opts.IfVersion != "" && opts.IfVersion != strconv.Itoa(w.version) {
+		return fmt.Errorf("%w: %w", types.ErrCouldNotWriteSecret, types.ErrVersionConflict)
+	}
+	w.Val, w.Err = value, nil
+	w.version++
+	return nil
+}
+
+func (w *secretWriter) Delete(_ context.Context, _ types.SecretCoord) error {
+	w.Val, w.Err = "", types.ErrSecretNotFound
+	return nil
+}
+
+func TestSpelunker_Put(t *testing.T) {
+	writer := &secretWriter{MockSource: util.NewMockSource("writer")}
+	spelunker := spelunk.NewSpelunker(
+		spelunk.WithSource(writer),
+		spelunk.WithSource(newValueSource("mock", "val")),
+		spelunk.WithPolicy(spelunk.WithPolicyDenyLocations("writer://denied/**")),
+		spelunk.WithCache(),
+	)
+	coord, err := types.NewSecretCoord("writer://app/secret")
+	require.NoError(t, err)
+
+	// Written values are dug-up, bypassing the cache
+	require.NoError(t, spelunker.Put(t.Context(), coord, "v1", types.PutOptions{}))
+	got, err := spelunker.DigUp(t.Context(), coord)
+	require.NoError(t, err)
+	require.Equal(t, "v1", got)
+
+	require.NoError(t, spelunker.Put(t.Context(), coord, "v2", types.PutOptions{IfVersion: "1"}))
+	got, err = spelunker.DigUp(t.Context(), coord)
+	require.NoError(t, err)
+	require.Equal(t, "v2", got)
+
+	require.NoError(t, spelunker.Delete(t.Context(), coord))
+	exists, err := spelunker.Exists(t.Context(), coord)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	tests := []struct {
+		name      string
+		coordStr  string
+		opts      types.PutOptions
+		errMatch  error
+		wantClass types.ErrorClass
+	}{
+		{
+			name:      "version conflict",
+			coordStr:  "writer://app/secret",
+			opts:      types.PutOptions{IfVersion: "1"},
+			errMatch:  spelunk.ErrFailedToPutSecret,
+			wantClass: types.ErrorClassConflict,
+		},
+		{
+			name:      "with modifiers",
+			coordStr:  "writer://app/secret?b64",
+			errMatch:  spelunk.ErrWritingWithModifiers,
+			wantClass: types.ErrorClassUnknown,
+		},
+		{
+			name:      "policy violation",
+			coordStr:  "writer://denied/secret",
+			errMatch:  spelunk.ErrPolicyViolation,
+			wantClass: types.ErrorClassPermissionDenied,
+		},
+		{
+			name:      "writing not supported",
+			coordStr:  "mock://app/secret",
+			errMatch:  spelunk.ErrWritingNotSupported,
+			wantClass: types.ErrorClassUnknown,
+		},
+		{
+			name:      "unsupported source type",
+			coordStr:  "unknown://app/secret",
+			errMatch:  spelunk.ErrUnsupportedSecretSourceType,
+			wantClass: types.ErrorClassUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coord, err := types.NewSecretCoord(tt.coordStr)
+			require.NoError(t, err)
+
+			err = spelunker.Put(t.Context(), coord, "val", tt.opts)
+			require.ErrorIs(t, err, tt.errMatch)
+
+			digUpErr, errMatched := errors.AsType[*types.DigUpError](err)
+			require.True(t, errMatched)
+			require.Equal(t, tt.wantClass, digUpErr.Class)
+		})
+	}
+
+	require.ErrorIs(t, spelunker.Put(t.Context(), nil, "val", types.PutOptions{}), spelunk.ErrNilSecretCoord)
+	require.ErrorIs(t, spelunker.Delete(t.Context(), nil), spelunk.ErrNilSecretCoord)
+}